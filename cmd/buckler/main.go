@@ -1,11 +1,17 @@
 package main
 
 import (
+	"backend/database"
 	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+	"backend/internal/service"
 	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -15,11 +21,17 @@ func main() {
 		fetch = flag.Bool("fetch", false, "fetch battlelog")
 		sid   = flag.String("sid", "", "Buckler short_id (sid)")
 		page  = flag.Int("page", 1, "page number")
+		kind  = flag.String("kind", "custom", "battlelog kind (rank/casual/hub/custom)")
+
+		backfill = flag.Bool("backfill", false, "walk every battlelog page for --sid and store it (resumable)")
+		guild    = flag.String("guild", "", "Discord guild ID to store battles in (--backfill)")
+		user     = flag.String("user", "", "Discord user ID that owns the battles when --sid is not a linked account (--backfill)")
+		restart  = flag.Bool("restart", false, "start the backfill over from page 1 (--backfill)")
 	)
 	flag.Parse()
 
-	if !*login && !*fetch {
-		fmt.Println("usage: buckler --login | --fetch --sid <sid> [--page n]")
+	if !*login && !*fetch && !*backfill {
+		fmt.Println("usage: buckler --login | --fetch --sid <sid> [--kind custom] [--page n] | --backfill --sid <sid> --guild <guild_id> [--user <user_id>] [--kind custom,rank] [--restart]")
 		os.Exit(2)
 	}
 
@@ -29,6 +41,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *backfill {
+		os.Exit(runBackfill(cfg, *sid, *guild, *user, *kind, *restart))
+	}
+
 	client, err := buckler.NewClient(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "client error: %v\n", err)
@@ -51,12 +67,18 @@ func main() {
 			fmt.Fprintln(os.Stderr, "--sid required")
 			os.Exit(2)
 		}
-		res, err := client.FetchCustomBattlelog(ctx, *sid, *page)
+		battlelogKind, err := buckler.ParseBattlelogKind(*kind)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		res, err := client.FetchBattlelog(ctx, *sid, battlelogKind, *page)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "fetch failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("fetch ok: page=%d total_page=%d sid=%d items=%d\n",
+		fmt.Printf("fetch ok: kind=%s page=%d total_page=%d sid=%d items=%d\n",
+			battlelogKind,
 			res.PageProps.CurrentPage,
 			res.PageProps.TotalPage,
 			res.PageProps.SID,
@@ -64,3 +86,60 @@ func main() {
 		)
 	}
 }
+
+// runBackfill は DB に進捗を保存しながら全ページを取得する。Ctrl+C で止めても次回は続きから再開する。
+func runBackfill(cfg buckler.Config, sid, guildID, userID, kindsRaw string, restart bool) int {
+	if sid == "" || guildID == "" {
+		fmt.Fprintln(os.Stderr, "--sid and --guild required")
+		return 2
+	}
+	kinds, err := buckler.ParseBattlelogKinds(kindsRaw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	db, err := database.NewConnection()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db error: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	client, err := buckler.NewClient(cfg, buckler.WithCookieStore(repository.NewBucklerCookieRepository(db)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "client error: %v\n", err)
+		return 1
+	}
+	accountRepo := repository.NewSF6AccountRepository(db)
+	sf6Service := service.NewSF6Service(client, repository.NewSF6BattleRepository(db), accountRepo, 0)
+	backfillService := service.NewSF6BackfillService(sf6Service, repository.NewSF6BackfillRepository(db), accountRepo)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if userID == "" {
+		owner, err := accountRepo.GetByFighter(ctx, guildID, sid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "account lookup failed: %v\n", err)
+			return 1
+		}
+		if owner == nil {
+			fmt.Fprintln(os.Stderr, "--user required: sid is not a linked account in this guild")
+			return 2
+		}
+		userID = owner.UserID
+	}
+
+	jobs, err := backfillService.Run(ctx, guildID, userID, sid, kinds, restart, func(job domain.SF6BackfillJob) {
+		fmt.Printf("backfill: kind=%s page=%d/%d saved=%d status=%s\n", job.BattleType, job.NextPage-1, job.TotalPage, job.SavedCount, job.Status)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backfill stopped: %v (run again to resume)\n", err)
+		return 1
+	}
+	for _, job := range jobs {
+		fmt.Printf("backfill ok: kind=%s pages=%d saved=%d\n", job.BattleType, job.TotalPage, job.SavedCount)
+	}
+	return 0
+}
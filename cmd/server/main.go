@@ -47,16 +47,27 @@ func main() {
 	sf6BattleRepo := repository.NewSF6BattleRepository(db)
 	sf6FriendRepo := repository.NewSF6FriendRepository(db)
 	sf6SessionRepo := repository.NewSF6SessionRepository(db)
+	sf6PollScheduleRepo := repository.NewSF6PollScheduleRepository(db)
+	sf6FetchRunRepo := repository.NewSF6FetchRunRepository(db)
+	sf6FetchRunService := service.NewSF6FetchRunService(sf6FetchRunRepo, sf6PollScheduleRepo)
+	sf6FeedService := service.NewSF6FeedService(repository.NewSF6FeedRepository(db), sf6AccountRepo, envDuration("SF6_FEED_MAX_AGE", 24*time.Hour))
+	sf6RatingService := service.NewSF6RatingService(repository.NewSF6RatingRepository(db))
 	sf6AccountService := service.NewSF6AccountService(sf6AccountRepo, sf6FriendRepo, sf6BattleRepo)
 	sf6FriendService := service.NewSF6FriendService(sf6FriendRepo, sf6AccountRepo, sf6BattleRepo)
 	sf6SessionService := service.NewSF6SessionService(sf6SessionRepo)
 	var sf6Service service.SF6Service
+	var sf6BackfillService service.SF6BackfillService
 	if cfg, err := buckler.LoadConfigFromEnv(); err != nil {
 		e.Logger.Warn("buckler config missing: sf6 commands disabled: ", err)
-	} else if bclient, err := buckler.NewClient(cfg); err != nil {
+	} else if bclient, err := buckler.NewClient(cfg, buckler.WithCookieStore(repository.NewBucklerCookieRepository(db))); err != nil {
 		e.Logger.Error("buckler client init failed: ", err)
 	} else {
-		sf6Service = service.NewSF6Service(bclient, sf6BattleRepo, sf6AccountRepo)
+		if cfg.CookieEncKeyRaw == "" {
+			e.Logger.Warn("BUCKLER_COOKIE_ENC_KEY missing: buckler cookies are not persisted")
+		}
+		sf6Service = service.NewSF6Service(bclient, sf6BattleRepo, sf6AccountRepo, envDuration("SF6_FETCH_FRESHNESS", 30*time.Second))
+		healthSevice.SetBucklerStats(bclient)
+		sf6BackfillService = service.NewSF6BackfillService(sf6Service, repository.NewSF6BackfillRepository(db), sf6AccountRepo)
 	}
 
 	// ミドルウェア
@@ -131,8 +142,12 @@ func main() {
 	}()
 
 	// Discord起動
+	var sf6SessionNotifier service.SF6SessionNotifier
+	var sf6FeedNotifier service.SF6FeedNotifier
 	if dSession != nil {
-	router := discord.NewRouter(anonService, sf6AccountService, sf6FriendService, sf6Service, sf6SessionService)
+	router := discord.NewRouter(anonService, sf6AccountService, sf6FriendService, sf6Service, sf6SessionService, sf6BackfillService, sf6FetchRunService, sf6FeedService, sf6RatingService)
+		sf6SessionNotifier = router.SF6SessionNotifier(dSession.Discordgo())
+		sf6FeedNotifier = router.SF6FeedNotifier(dSession.Discordgo())
 		dSession.AddHandler(router.HandleInteraction)
 		dSession.AddHandler(router.HandleMessageCreate)
 
@@ -174,15 +189,77 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// 定期ジョブは advisory lock を取れた 1 インスタンス（リーダー）だけで動かす
+	leaderElector := service.NewLeaderElector(
+		repository.NewLeaderLockRepository(db),
+		getEnvWithDefault("LEADER_LOCK_NAME", "chatclub-scheduler"),
+		instanceID(),
+		service.LeaderElectionOptions{
+			RetryInterval: envDuration("LEADER_RETRY_INTERVAL", 15*time.Second),
+			CheckInterval: envDuration("LEADER_CHECK_INTERVAL", 10*time.Second),
+		},
+		e.Logger,
+	)
+	healthSevice.SetLeaderStatus(leaderElector)
+
 	if sf6Service != nil {
-		pollInterval := envDuration("SF6_POLL_INTERVAL", 4*time.Hour)
-		maxPages := envInt("SF6_POLL_MAX_PAGES", 10)
-		accountDelayMax := envDuration("SF6_POLL_ACCOUNT_DELAY_MAX", 3*time.Second)
-		go service.RunSF6Poller(ctx, pollInterval, maxPages, accountDelayMax, sf6AccountRepo, sf6FriendRepo, sf6Service, e.Logger)
+		kinds, err := buckler.ParseBattlelogKinds(os.Getenv("SF6_BATTLELOG_KINDS"))
+		if err != nil {
+			e.Logger.Warnf("invalid SF6_BATTLELOG_KINDS, fallback to custom: %v", err)
+			kinds = []buckler.BattlelogKind{buckler.BattlelogCustom}
+		}
+		pollOptions := service.SF6PollOptions{
+			Tick: envDuration("SF6_POLL_TICK", time.Minute),
+			Intervals: service.SF6PollIntervals{
+				Base:   envDuration("SF6_POLL_INTERVAL", 4*time.Hour),
+				Min:    envDuration("SF6_POLL_MIN_INTERVAL", 30*time.Minute),
+				Max:    envDuration("SF6_POLL_MAX_INTERVAL", 24*time.Hour),
+				Active: envDuration("SF6_POLL_ACTIVE_INTERVAL", 10*time.Minute),
+			},
+			MaxPages:        envInt("SF6_POLL_MAX_PAGES", 10),
+			AccountDelayMax: envDuration("SF6_POLL_ACCOUNT_DELAY_MAX", 3*time.Second),
+			Kinds:           kinds,
+			Workers:         envInt("SF6_POLL_WORKERS", 2),
+			JobTimeout:      envDuration("SF6_POLL_JOB_TIMEOUT", 2*time.Minute),
+			JobRetries:      envInt("SF6_POLL_JOB_RETRIES", 2),
+			// 取得のたびに新着対戦をフィードチャンネルへ流す
+			AfterRound: func(ctx context.Context, _ service.SF6PollSummary) {
+				if _, err := sf6FeedService.NotifyNewBattles(ctx, time.Now(), sf6FeedNotifier, e.Logger); err != nil && ctx.Err() == nil {
+					e.Logger.Error("sf6 feed: ", err)
+				}
+				sf6RatingService.RefreshAll(ctx, e.Logger)
+			},
+		}
+		leaderElector.Go("sf6 poller", func(ctx context.Context) {
+			service.RunSF6Poller(ctx, pollOptions, sf6AccountRepo, sf6FriendRepo, sf6SessionRepo, sf6PollScheduleRepo, sf6FetchRunRepo, sf6Service, e.Logger)
+		})
+
+		sessionPollInterval := envDuration("SF6_SESSION_POLL_INTERVAL", 60*time.Second)
+		sessionIdleTimeout := envDuration("SF6_SESSION_IDLE_TIMEOUT", 30*time.Minute)
+		leaderElector.Go("sf6 session watcher", func(ctx context.Context) {
+			service.RunSF6SessionWatcher(ctx, sessionPollInterval, sessionIdleTimeout, kinds, sf6SessionService, sf6Service, sf6AccountRepo, sf6FetchRunRepo, sf6SessionNotifier, e.Logger)
+		})
+
+		// 再起動前に途中だったバックフィルを続きから再開する
+		leaderElector.Go("sf6 backfill resume", func(ctx context.Context) {
+			sf6BackfillService.ResumeUnfinished(ctx, e.Logger)
+		})
 	} else {
 		fmt.Printf("sf6 poller disabled: sf6Service is nil (check CAPCOM_EMAIL/CAPCOM_PASSWORD and Buckler config)")
 	}
 
+	// 取得履歴は保持期間を過ぎたら削除する
+	fetchRunRetention := envDuration("SF6_FETCH_RUN_RETENTION", 30*24*time.Hour)
+	leaderElector.Go("sf6 fetch run pruner", func(ctx context.Context) {
+		service.RunSF6FetchRunPruner(ctx, time.Hour, fetchRunRetention, sf6FetchRunRepo, e.Logger)
+	})
+
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		leaderElector.Run(ctx)
+	}()
+
 	// 「シグナルでの終了要求」か「サーバ起動側のエラー」のどちらが先かを競合待ちする
 	select {
 	case <-ctx.Done():
@@ -218,6 +295,14 @@ func main() {
 		e.Logger.Error("discord close:", err)
 	}
 
+	// 定期ジョブを止めてリーダーのロックを外す（次のインスタンスがすぐ引き継げるように）
+	stop()
+	select {
+	case <-leaderDone:
+	case <-shutdownCtx.Done():
+		e.Logger.Error("leader release timed out")
+	}
+
 	// DBはここで閉じる（全リクエスト完了後）
 	if derr := db.Close(); derr != nil {
 		e.Logger.Error("db close:", derr)
@@ -259,6 +344,24 @@ func envBool(key string, def bool) bool {
 	return val == "1" || val == "true" || val == "yes"
 }
 
+func getEnvWithDefault(key, def string) string {
+	if val := strings.TrimSpace(os.Getenv(key)); val != "" {
+		return val
+	}
+	return def
+}
+
+// instanceID はリーダー表示用のインスタンス名。Heroku では DYNO（web.1 など）を使う。
+func instanceID() string {
+	name := os.Getenv("DYNO")
+	if name == "" {
+		if host, err := os.Hostname(); err == nil {
+			name = host
+		}
+	}
+	return fmt.Sprintf("%s:%d", name, os.Getpid())
+}
+
 func envStringList(key string) []string {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
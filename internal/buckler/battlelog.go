@@ -60,12 +60,103 @@ func RoundWins(results []int) int {
 	return wins
 }
 
+// BattlelogKind は Buckler の battlelog タブ（rank/casual/hub/custom）を表す。
+type BattlelogKind string
+
+const (
+	BattlelogRank   BattlelogKind = "rank"
+	BattlelogCasual BattlelogKind = "casual"
+	BattlelogHub    BattlelogKind = "hub"
+	BattlelogCustom BattlelogKind = "custom"
+)
+
+// BattlelogKinds は取得可能な battlelog の種類を表示順で返す。
+func BattlelogKinds() []BattlelogKind {
+	return []BattlelogKind{BattlelogRank, BattlelogCasual, BattlelogHub, BattlelogCustom}
+}
+
+// ParseBattlelogKind は文字列を BattlelogKind に変換する。
+// "ranked" や "custom_room" のような表記揺れも受け付ける。
+func ParseBattlelogKind(raw string) (BattlelogKind, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "rank", "ranked":
+		return BattlelogRank, nil
+	case "casual":
+		return BattlelogCasual, nil
+	case "hub", "battle_hub", "battlehub":
+		return BattlelogHub, nil
+	case "custom", "custom_room", "customroom":
+		return BattlelogCustom, nil
+	}
+	return "", fmt.Errorf("unknown battlelog kind: %s", raw)
+}
+
+// ParseBattlelogKinds はカンマ区切りの種類一覧を解釈する。空なら custom のみ。
+func ParseBattlelogKinds(raw string) ([]BattlelogKind, error) {
+	parts := strings.Split(raw, ",")
+	out := make([]BattlelogKind, 0, len(parts))
+	seen := make(map[BattlelogKind]struct{}, len(parts))
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kind, err := ParseBattlelogKind(part)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[kind]; ok {
+			continue
+		}
+		seen[kind] = struct{}{}
+		out = append(out, kind)
+	}
+	if len(out) == 0 {
+		out = append(out, BattlelogCustom)
+	}
+	return out, nil
+}
+
+// Kind は replay_battle_type（無ければ replay_battle_type_name）から試合種別を判定する。
+// 判定できない場合は空文字を返す。
+func (e ReplayEntry) Kind() BattlelogKind {
+	switch e.ReplayBattleType {
+	case 1:
+		return BattlelogRank
+	case 2:
+		return BattlelogCasual
+	case 3:
+		return BattlelogHub
+	case 4:
+		return BattlelogCustom
+	}
+	name := strings.ToLower(e.ReplayBattleTypeName)
+	switch {
+	case strings.Contains(name, "rank"):
+		return BattlelogRank
+	case strings.Contains(name, "casual"):
+		return BattlelogCasual
+	case strings.Contains(name, "hub"):
+		return BattlelogHub
+	case strings.Contains(name, "custom"):
+		return BattlelogCustom
+	}
+	return ""
+}
+
 // FetchCustomBattlelog は Custom Room の battlelog JSON を取得する。
 func (c *Client) FetchCustomBattlelog(ctx context.Context, sid string, page int) (BattlelogResponse, error) {
+	return c.FetchBattlelog(ctx, sid, BattlelogCustom, page)
+}
+
+// FetchBattlelog は指定タブの battlelog JSON を取得する。
+func (c *Client) FetchBattlelog(ctx context.Context, sid string, kind BattlelogKind, page int) (BattlelogResponse, error) {
 	var res BattlelogResponse
 	if sid == "" {
 		return res, errors.New("sid required")
 	}
+	if kind == "" {
+		kind = BattlelogCustom
+	}
 	if page <= 0 {
 		page = 1
 	}
@@ -74,38 +165,66 @@ func (c *Client) FetchCustomBattlelog(ctx context.Context, sid string, page int)
 		return res, err
 	}
 
+	// Cookie が残っていてもサーバー側で失効していることがあるため、検知したら再ログインして一度だけ再取得する
+	err := c.withSessionRetry(ctx, func() error {
+		var err error
+		res, err = c.fetchBattlelogOnce(ctx, sid, kind, page)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+	// 取得中に Buckler 側でCookieが更新されていれば保存し直す
+	c.persistCookies(ctx)
+	return res, nil
+}
+
+func (c *Client) fetchBattlelogOnce(ctx context.Context, sid string, kind BattlelogKind, page int) (BattlelogResponse, error) {
+	var res BattlelogResponse
 	buildID, err := c.FetchBuildID(ctx, sid)
 	if err != nil {
 		return res, err
 	}
-	url := c.buildBattlelogURL(buildID, sid, page)
+	url := c.buildBattlelogURL(buildID, sid, kind, page)
 
 	resp, body, err := c.getReturn(ctx, url)
 	if err != nil {
 		return res, err
 	}
+	if err := c.checkNextDataResponse(resp, body); err != nil {
+		return res, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return res, newStatusError("battlelog", resp)
+	}
 	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
 		// rebuild buildId and retry once
-		c.cache.Set("")
+		c.cache.Invalidate(buildID)
 		buildID, err = c.FetchBuildID(ctx, sid)
 		if err != nil {
 			return res, err
 		}
-		url = c.buildBattlelogURL(buildID, sid, page)
-		_, body, err = c.getReturn(ctx, url)
+		url = c.buildBattlelogURL(buildID, sid, kind, page)
+		resp, body, err = c.getReturn(ctx, url)
 		if err != nil {
 			return res, err
 		}
+		if err := c.checkNextDataResponse(resp, body); err != nil {
+			return res, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return res, newStatusError("battlelog", resp)
 	}
 
 	if err := json.Unmarshal(body, &res); err != nil {
-		return res, fmt.Errorf("decode battlelog: %w", err)
+		return res, &SchemaError{Op: "battlelog", Err: err}
 	}
 	return res, nil
 }
 
-func (c *Client) buildBattlelogURL(buildID, sid string, page int) string {
+func (c *Client) buildBattlelogURL(buildID, sid string, kind BattlelogKind, page int) string {
 	base := strings.TrimRight(c.cfg.BucklerBaseURL, "/")
 	lang := c.cfg.Lang
-	return fmt.Sprintf("%s/_next/data/%s/%s/profile/%s/battlelog/custom.json?sid=%s&page=%s", base, buildID, lang, sid, sid, strconv.Itoa(page))
+	return fmt.Sprintf("%s/_next/data/%s/%s/profile/%s/battlelog/%s.json?sid=%s&page=%s", base, buildID, lang, sid, kind, sid, strconv.Itoa(page))
 }
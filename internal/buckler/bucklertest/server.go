@@ -0,0 +1,608 @@
+// Package bucklertest は buckler.Client をオフラインで動かすための偽 Auth0 / CID / Buckler サーバーを提供する。
+//
+// Auth（Auth0 と CID を兼ねる）と Buckler の 2 つの httptest.Server を立て、
+// authorize → /login → /usernamepassword/login → /login/callback → loginCallback → Buckler /auth/login
+// のリダイレクトを再現して buckler_id / buckler_r_id を発行する。
+// battlelog / card は fixtures 以下の JSON（または SetBattlelog / SetCard で登録したもの）を返す。
+package bucklertest
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	// DefaultEmail / DefaultPassword は偽サーバーが受け付けるログイン情報。
+	DefaultEmail    = "fake@example.com"
+	DefaultPassword = "fake-password"
+	// FixtureSID は同梱 fixtures のユーザーコード。
+	FixtureSID = "1234567890"
+	// DefaultBuildID は初期の buildId。
+	DefaultBuildID = "fake-build-1"
+	// DefaultPageSize は battlelog 1 ページあたりの件数（Buckler と同じ 10 件）。
+	DefaultPageSize = 10
+
+	bucklerPrefix = "/6/buckler"
+	// BucklerClientID は Buckler 側 authorize で使う client_id。
+	BucklerClientID = "fake-buckler-client"
+)
+
+//go:embed fixtures/*.json
+var fixtureFS embed.FS
+
+// Server は偽 Auth0 / CID / Buckler サーバー。
+type Server struct {
+	Auth    *httptest.Server
+	Buckler *httptest.Server
+
+	Email    string
+	Password string
+	PageSize int
+
+	mu         sync.Mutex
+	buildID    string
+	states     map[string]string // state -> redirect_uri
+	tokens     map[string]string // callback wresult -> state
+	codes      map[string]bool
+	authSess   map[string]bool
+	sessions   map[string]bool
+	battlelogs map[string][]json.RawMessage // sid/kind -> 新しい順の replay
+	cards      map[string]json.RawMessage
+	logins     int
+	requests   map[string]int
+	failures   []failure
+}
+
+// failure は次の battlelog リクエストで返すエラー応答。
+type failure struct {
+	status     int
+	retryAfter string
+}
+
+// NewServer は fixtures を読み込んだ偽サーバーを起動する。終了時は Close を呼ぶ。
+func NewServer() *Server {
+	s := &Server{
+		Email:      DefaultEmail,
+		Password:   DefaultPassword,
+		PageSize:   DefaultPageSize,
+		buildID:    DefaultBuildID,
+		states:     make(map[string]string),
+		tokens:     make(map[string]string),
+		codes:      make(map[string]bool),
+		authSess:   make(map[string]bool),
+		sessions:   make(map[string]bool),
+		battlelogs: make(map[string][]json.RawMessage),
+		cards:      make(map[string]json.RawMessage),
+		requests:   make(map[string]int),
+	}
+	if err := s.loadFixtures(); err != nil {
+		panic("bucklertest: load fixtures: " + err.Error())
+	}
+	s.Auth = httptest.NewServer(http.HandlerFunc(s.serveAuth))
+	s.Buckler = httptest.NewServer(http.HandlerFunc(s.serveBuckler))
+	return s
+}
+
+// Close は両サーバーを停止する。
+func (s *Server) Close() {
+	s.Auth.Close()
+	s.Buckler.Close()
+}
+
+// AuthBaseURL は Config.AuthBaseURL / CidBaseURL に設定する URL。
+func (s *Server) AuthBaseURL() string {
+	return s.Auth.URL
+}
+
+// BucklerBaseURL は Config.BucklerBaseURL に設定する URL。
+func (s *Server) BucklerBaseURL() string {
+	return s.Buckler.URL + bucklerPrefix
+}
+
+// RedirectURI は Config.RedirectURI に設定する CID のコールバック URL。
+func (s *Server) RedirectURI() string {
+	return s.Auth.URL + "/ja/loginCallback"
+}
+
+// BuildID は現在の buildId を返す。
+func (s *Server) BuildID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buildID
+}
+
+// SetClientEnv は buckler.LoadConfigFromEnv がこのサーバーに接続するよう環境変数を設定する。
+// buckler 自身のテストからも使うので buckler.Config は組み立てず、環境変数で渡す。レート制限はかけない。
+func (s *Server) SetClientEnv(t testing.TB) {
+	t.Helper()
+	env := map[string]string{
+		"CAPCOM_EMAIL":                    s.Email,
+		"CAPCOM_PASSWORD":                 s.Password,
+		"CAPCOM_AUTH_BASE_URL":            s.AuthBaseURL(),
+		"CAPCOM_CID_BASE_URL":             s.AuthBaseURL(),
+		"BUCKLER_BASE_URL":                s.BucklerBaseURL(),
+		"BUCKLER_LANG":                    "ja-jp",
+		"CAPCOM_CLIENT_ID":                "fake-client",
+		"CAPCOM_CONNECTION":               "Username-Password-Authentication",
+		"CAPCOM_TENANT":                   "fake",
+		"CAPCOM_PROTOCOL":                 "oauth2",
+		"CAPCOM_REDIRECT_URI":             s.RedirectURI(),
+		"CAPCOM_RESPONSE_TYPE":            "code",
+		"CAPCOM_SCOPE":                    "openid profile email",
+		"CAPCOM_UI_LOCALES":               "ja",
+		"CAPCOM_SHOW_SIGN_UP":             "0",
+		"BUCKLER_CLIENT_ID":               BucklerClientID,
+		"BUCKLER_REDIRECT_URI":            s.BucklerBaseURL() + "/auth/login",
+		"BUCKLER_SCOPE":                   "openid",
+		"BUCKLER_RESPONSE_TYPE":           "code",
+		"BUCKLER_USER_AGENT":              "bucklertest",
+		"BUCKLER_DEBUG":                   "0",
+		"BUCKLER_COOKIE_ENC_KEY":          "",
+		"BUCKLER_COOKIE_ENC_KEY_PREVIOUS": "",
+		"BUCKLER_RATE_LIMIT_RPS":          "0",
+		"BUCKLER_RATE_LIMIT_BURST":        "0",
+		"BUCKLER_AUTH_RATE_LIMIT_RPS":     "0",
+		"BUCKLER_DAILY_REQUEST_CAP":       "0",
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// SetBuildID は buildId を差し替える（デプロイによる buildId 変更の再現）。
+func (s *Server) SetBuildID(buildID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buildID = buildID
+}
+
+// ExpireSessions は発行済みの Buckler セッションをすべて無効にする（Cookie は残ったまま）。
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+	s.authSess = make(map[string]bool)
+}
+
+// SetBattlelog は sid / kind の battlelog を新しい順の replay で置き換える。
+func (s *Server) SetBattlelog(sid, kind string, replays []json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.battlelogs[sid+"/"+kind] = replays
+}
+
+// SetCard は sid のカード JSON を登録する。
+func (s *Server) SetCard(sid string, card json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards[sid] = card
+}
+
+// FailBattlelog は次の n 回の battlelog リクエストに status（429 なら Retry-After 付き）を返す。
+func (s *Server) FailBattlelog(n, status int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, failure{status: status, retryAfter: retryAfter})
+	}
+}
+
+// LoginCount は Buckler セッションを発行した回数（= ログイン完了回数）を返す。
+func (s *Server) LoginCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// RequestCount は種類（"battlelog" / "card" / "buildId" / "login"）ごとのリクエスト数を返す。
+func (s *Server) RequestCount(kind string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[kind]
+}
+
+func (s *Server) countRequest(kind string) {
+	s.mu.Lock()
+	s.requests[kind]++
+	s.mu.Unlock()
+}
+
+func (s *Server) loadFixtures() error {
+	entries, err := fixtureFS.ReadDir("fixtures")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		raw, err := fixtureFS.ReadFile("fixtures/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(name, "_")
+		switch {
+		case len(parts) == 3 && parts[0] == "battlelog":
+			var replays []json.RawMessage
+			if err := json.Unmarshal(raw, &replays); err != nil {
+				return fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+			s.battlelogs[parts[1]+"/"+parts[2]] = replays
+		case len(parts) == 2 && parts[0] == "card":
+			s.cards[parts[1]] = json.RawMessage(raw)
+		default:
+			return fmt.Errorf("unknown fixture: %s", entry.Name())
+		}
+	}
+	return nil
+}
+
+// ---- Auth0 / CID ----
+
+func (s *Server) serveAuth(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/authorize":
+		s.handleAuthorize(w, r)
+	case "/login":
+		s.handleLoginPage(w, r)
+	case "/usernamepassword/challenge":
+		writeJSON(w, http.StatusOK, map[string]any{})
+	case "/usernamepassword/login":
+		s.handleUsernamePassword(w, r)
+	case "/login/callback":
+		s.handleLoginCallback(w, r)
+	case "/ja/loginCallback":
+		s.handleCIDCallback(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleAuthorize はログイン済み（auth0 Cookie あり）なら code 付きで redirect_uri に戻し、
+// 未ログインなら /login に飛ばす。
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if redirectURI == "" {
+		http.Error(w, "redirect_uri required", http.StatusBadRequest)
+		return
+	}
+	state := q.Get("state")
+	if state == "" {
+		state = randomToken()
+	}
+	if s.hasAuthSession(r) {
+		code := randomToken()
+		s.mu.Lock()
+		s.codes[code] = true
+		s.mu.Unlock()
+		http.Redirect(w, r, withQuery(redirectURI, url.Values{"code": {code}, "state": {state}}), http.StatusFound)
+		return
+	}
+	s.mu.Lock()
+	s.states[state] = redirectURI
+	s.mu.Unlock()
+	http.Redirect(w, r, "/login?"+url.Values{"state": {state}, "client": {q.Get("client_id")}}.Encode(), http.StatusFound)
+}
+
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: "_csrf", Value: randomToken(), Path: "/"})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<html><body><form id=\"login\"><input name=\"username\"><input name=\"password\" type=\"password\"></form></body></html>")
+}
+
+func (s *Server) handleUsernamePassword(w http.ResponseWriter, r *http.Request) {
+	s.countRequest("login")
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	username, _ := payload["username"].(string)
+	password, _ := payload["password"].(string)
+	state, _ := payload["state"].(string)
+	csrf, _ := payload["_csrf"].(string)
+	if ck, err := r.Cookie("_csrf"); err != nil || ck.Value == "" || ck.Value != csrf {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid_csrf"})
+		return
+	}
+	if username != s.Email || password != s.Password {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "access_denied", "error_description": "Wrong email or password."})
+		return
+	}
+	s.mu.Lock()
+	_, ok := s.states[state]
+	token := randomToken()
+	if ok {
+		s.tokens[token] = state
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_state"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<html><body><form method="post" name="hiddenform" action="%s/login/callback">`+
+		`<input type="hidden" name="wa" value="wsignin1.0">`+
+		`<input type="hidden" name="wresult" value="%s">`+
+		`<input type="hidden" name="wctx" value="%s">`+
+		`</form></body></html>`,
+		s.Auth.URL, html.EscapeString(token), html.EscapeString(`{"state":"`+state+`"}`))
+}
+
+func (s *Server) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+	token := r.PostForm.Get("wresult")
+	s.mu.Lock()
+	state, ok := s.tokens[token]
+	redirectURI := s.states[state]
+	delete(s.tokens, token)
+	delete(s.states, state)
+	session := randomToken()
+	code := randomToken()
+	if ok {
+		s.authSess[session] = true
+		s.codes[code] = true
+	}
+	s.mu.Unlock()
+	if !ok || redirectURI == "" {
+		http.Error(w, "invalid callback", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "auth0", Value: session, Path: "/", HttpOnly: true})
+	http.Redirect(w, r, withQuery(redirectURI, url.Values{"code": {code}, "state": {state}}), http.StatusFound)
+}
+
+// handleCIDCallback は CID のログイン完了後、Buckler 用の authorize へ進める。
+func (s *Server) handleCIDCallback(w http.ResponseWriter, r *http.Request) {
+	if !s.consumeCode(r.URL.Query().Get("code")) {
+		http.Error(w, "invalid code", http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, s.bucklerAuthorizeURL(), http.StatusFound)
+}
+
+func (s *Server) bucklerAuthorizeURL() string {
+	q := url.Values{}
+	q.Set("client_id", BucklerClientID)
+	q.Set("redirect_uri", s.BucklerBaseURL()+"/auth/login")
+	q.Set("response_type", "code")
+	q.Set("scope", "openid")
+	q.Set("state", randomToken())
+	return s.Auth.URL + "/authorize?" + q.Encode()
+}
+
+func (s *Server) hasAuthSession(r *http.Request) bool {
+	ck, err := r.Cookie("auth0")
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.authSess[ck.Value]
+}
+
+func (s *Server) consumeCode(code string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.codes[code] {
+		return false
+	}
+	delete(s.codes, code)
+	return true
+}
+
+// ---- Buckler ----
+
+func (s *Server) serveBuckler(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimPrefix(r.URL.Path, bucklerPrefix)
+	if p == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+
+	switch {
+	case len(parts) >= 2 && parts[0] == "_next" && parts[1] == "data":
+		s.serveNextData(w, r, parts[2:])
+	case len(parts) == 4 && parts[0] == "api" && parts[2] == "card":
+		s.serveCard(w, r, parts[3])
+	case len(parts) == 2 && parts[0] == "auth" && parts[1] == "login":
+		// 言語無しの /auth/login はクライアント側で /{lang}/auth/login に書き換えられる想定
+		http.Redirect(w, r, bucklerPrefix+"/ja-jp/auth/login?"+r.URL.RawQuery, http.StatusFound)
+	case len(parts) == 3 && parts[1] == "auth" && parts[2] == "login":
+		s.handleBucklerLogin(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "auth" && parts[2] == "postlogin":
+		http.Redirect(w, r, bucklerPrefix+"/"+parts[0]+"/?status=login", http.StatusFound)
+	case len(parts) == 1 && parts[0] != "":
+		s.servePage(w)
+	case len(parts) == 4 && parts[1] == "profile" && parts[3] == "battlelog":
+		s.countRequest("buildId")
+		s.servePage(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleBucklerLogin(w http.ResponseWriter, r *http.Request, lang string) {
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		// ログイン入口: Buckler 用 authorize へ
+		http.Redirect(w, r, s.bucklerAuthorizeURL(), http.StatusFound)
+		return
+	}
+	if !s.consumeCode(code) {
+		http.Error(w, "invalid code", http.StatusBadRequest)
+		return
+	}
+	id, rid := randomToken(), randomToken()
+	s.mu.Lock()
+	s.sessions[id] = true
+	s.logins++
+	s.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: "buckler_id", Value: id, Path: "/", HttpOnly: true})
+	http.SetCookie(w, &http.Cookie{Name: "buckler_r_id", Value: rid, Path: "/", HttpOnly: true})
+	http.Redirect(w, r, bucklerPrefix+"/"+lang+"/auth/postlogin", http.StatusFound)
+}
+
+func (s *Server) servePage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<html><body><div id="__next"></div>`+
+		`<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{}},"page":"/[lang]","buildId":"%s"}</script>`+
+		`</body></html>`, s.BuildID())
+}
+
+func (s *Server) hasBucklerSession(r *http.Request) bool {
+	ck, err := r.Cookie("buckler_id")
+	if err != nil {
+		return false
+	}
+	if _, err := r.Cookie("buckler_r_id"); err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[ck.Value]
+}
+
+// serveNextData は /_next/data/{buildId}/{lang}/... を処理する。
+func (s *Server) serveNextData(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) < 3 || parts[0] != s.BuildID() {
+		http.NotFound(w, r)
+		return
+	}
+	rest := parts[2:]
+	switch {
+	case len(rest) == 2 && rest[0] == "auth" && rest[1] == "loginep.json":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"pageProps": map[string]any{"__N_REDIRECT": s.bucklerAuthorizeURL(), "__N_REDIRECT_STATUS": 307},
+			"__N_SSP":   true,
+		})
+	case len(rest) == 4 && rest[0] == "profile" && rest[2] == "battlelog" && strings.HasSuffix(rest[3], ".json"):
+		s.serveBattlelog(w, r, rest[1], strings.TrimSuffix(rest[3], ".json"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveBattlelog(w http.ResponseWriter, r *http.Request, sid, kind string) {
+	s.countRequest("battlelog")
+	s.mu.Lock()
+	var fail *failure
+	if len(s.failures) > 0 {
+		fail = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+	if fail != nil {
+		if fail.retryAfter != "" {
+			w.Header().Set("Retry-After", fail.retryAfter)
+		}
+		http.Error(w, http.StatusText(fail.status), fail.status)
+		return
+	}
+	if !s.hasBucklerSession(r) {
+		// 未ログイン時の Next.js と同じくログイン入口へのリダイレクトを返す
+		writeJSON(w, http.StatusOK, map[string]any{
+			"pageProps": map[string]any{
+				"__N_REDIRECT":        bucklerPrefix + "/auth/loginep?redirect_url=" + url.QueryEscape(path.Join("/profile", sid, "battlelog", kind)),
+				"__N_REDIRECT_STATUS": 307,
+			},
+			"__N_SSP": true,
+		})
+		return
+	}
+	s.mu.Lock()
+	replays, ok := s.battlelogs[sid+"/"+kind]
+	_, known := s.cards[sid]
+	pageSize := s.PageSize
+	s.mu.Unlock()
+	if !ok && !known {
+		http.NotFound(w, r)
+		return
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	totalPage := (len(replays) + pageSize - 1) / pageSize
+	start := (page - 1) * pageSize
+	list := []json.RawMessage{}
+	if start < len(replays) {
+		end := start + pageSize
+		if end > len(replays) {
+			end = len(replays)
+		}
+		list = replays[start:end]
+	}
+	sidNum, _ := strconv.ParseInt(sid, 10, 64)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pageProps": map[string]any{
+			"current_page": page,
+			"total_page":   totalPage,
+			"sid":          sidNum,
+			"replay_list":  list,
+		},
+		"__N_SSP": true,
+	})
+}
+
+func (s *Server) serveCard(w http.ResponseWriter, r *http.Request, sid string) {
+	s.countRequest("card")
+	s.mu.Lock()
+	card, ok := s.cards[sid]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(card)
+}
+
+// ---- helpers ----
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func withQuery(rawURL string, values url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	for key, vals := range values {
+		for _, v := range vals {
+			q.Set(key, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
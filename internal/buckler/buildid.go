@@ -2,12 +2,16 @@ package buckler
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"net/http"
 	"regexp"
+	"sync"
 	"time"
 )
 
+// buildIDCache は複数の goroutine から同時に読み書きされるため mutex で保護する。
 type buildIDCache struct {
+	mu        sync.Mutex
 	value     string
 	expiresAt time.Time
 	ttl       time.Duration
@@ -23,6 +27,8 @@ func newBuildIDCache(ttl time.Duration) *buildIDCache {
 
 // Get は有効なキャッシュがあれば返す。
 func (c *buildIDCache) Get() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.value == "" {
 		return "", false
 	}
@@ -34,10 +40,22 @@ func (c *buildIDCache) Get() (string, bool) {
 
 // Set は buildId をキャッシュし、期限を更新する。
 func (c *buildIDCache) Set(value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.value = value
 	c.expiresAt = time.Now().Add(c.ttl)
 }
 
+// Invalidate はキャッシュが stale のままなら破棄する。
+// 他の goroutine が既に新しい buildId に更新していれば、それは残す。
+func (c *buildIDCache) Invalidate(stale string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.value == stale {
+		c.value = ""
+	}
+}
+
 var buildIDPattern = regexp.MustCompile(`"buildId"\s*:\s*"([^"]+)"`)
 
 // FetchBuildID は HTML から buildId を抽出する。
@@ -58,11 +76,14 @@ func (c *Client) FetchBuildID(ctx context.Context, sid string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return "", newStatusError("buildId", resp)
+	}
 	matches := buildIDPattern.FindSubmatch(body)
-	if len(matches) < 2 {
+	if len(matches) < 2 && !isMaintenancePage(resp, body) {
 		if loc := getLocation(resp); loc != "" {
 			nextURL := resolveURL(htmlURL, loc)
-			_, body, err = c.getReturn(ctx, nextURL)
+			resp, body, err = c.getReturn(ctx, nextURL)
 			if err != nil {
 				return "", err
 			}
@@ -70,7 +91,10 @@ func (c *Client) FetchBuildID(ctx context.Context, sid string) (string, error) {
 		}
 	}
 	if len(matches) < 2 {
-		return "", errors.New("buildId not found")
+		if isMaintenancePage(resp, body) {
+			return "", fmt.Errorf("%w: maintenance page", ErrMaintenance)
+		}
+		return "", fmt.Errorf("%w: buildId not found in %s", ErrBuildIDDrift, htmlURL)
 	}
 	buildID := string(matches[1])
 	c.cache.Set(buildID)
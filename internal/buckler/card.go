@@ -32,11 +32,11 @@ func (c *Client) FetchCard(ctx context.Context, sid string) (CardResponse, error
 		return CardResponse{}, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return CardResponse{}, fmt.Errorf("card api status=%d", resp.StatusCode)
+		return CardResponse{}, newStatusError("card", resp)
 	}
 	var card CardResponse
 	if err := json.Unmarshal(body, &card); err != nil {
-		return CardResponse{}, err
+		return CardResponse{}, &SchemaError{Op: "card", Err: err}
 	}
 	return card, nil
 }
@@ -8,9 +8,9 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,15 +18,28 @@ import (
 type Client struct {
 	cfg    Config
 	client *http.Client
+	jar    *resettableJar
 	cache  *buildIDCache
+
+	loginMu       sync.Mutex
+	loginInFlight *loginCall
+	loginGen      uint64
+
+	limiter *requestLimiter
+
+	cookieStore    CookieStore
+	cookieMu       sync.Mutex
+	savedCookieSum string
 }
 
 var errBucklerSessionNotEstablished = errors.New("buckler session not established")
 
-func NewClient(cfg Config) (*Client, error) {
-	jar, err := cookiejar.New(nil)
+// NewClient はクライアントを作成する。CookieStore が設定されていれば保存済みのログインCookieを復元する。
+// 返すクライアントは複数の goroutine から同時に使える。
+func NewClient(cfg Config, opts ...Option) (*Client, error) {
+	jar, err := newResettableJar()
 	if err != nil {
-		return nil, fmt.Errorf("cookie jar: %w", err)
+		return nil, err
 	}
 
 	hc := &http.Client{
@@ -38,11 +51,26 @@ func NewClient(cfg Config) (*Client, error) {
 		},
 	}
 
-	return &Client{
+	c := &Client{
 		cfg:    cfg,
 		client: hc,
+		jar:    jar,
 		cache:  newBuildIDCache(cfg.BuildIDTTL),
-	}, nil
+	}
+	c.limiter = newRequestLimiter(cfg, c.isBucklerHost)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.cookieStoreEnabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), cookieStoreTimeout)
+		defer cancel()
+		// 復元に失敗しても通常ログインで続行できるので、ログだけ残す
+		if err := c.restoreCookies(ctx); err != nil {
+			fmt.Printf("[buckler] cookie restore failed, fallback to login: %v\n", err)
+		}
+	}
+	return c, nil
 }
 
 func (c *Client) Config() Config {
@@ -57,8 +85,14 @@ func (c *Client) EnsureLogin(ctx context.Context) error {
 	return c.Login(ctx)
 }
 
-// Login は Auth0 経由のログインフローを実行する。
+// Login は Auth0 経由のログインフローを実行し、成功したらCookieを保存する。
+// 同時に呼ばれた場合はログインを 1 回だけ行い、全員が同じ結果を受け取る。
+// 失敗時は ErrNotAuthenticated を包んだエラーを返す。
 func (c *Client) Login(ctx context.Context) error {
+	return c.runLogin(ctx, false)
+}
+
+func (c *Client) login(ctx context.Context) error {
 	// 1) authorize でログインURLと state を取得
 	loginURL, state, err := c.startAuthorize(ctx)
 	if err != nil {
@@ -386,7 +420,7 @@ func (c *Client) getReturn(ctx context.Context, rawURL string) (*http.Response,
 	}
 	c.applyHeaders(req)
 	c.debugRequest(req)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -409,7 +443,7 @@ func (c *Client) getReturnWithHeaders(ctx context.Context, rawURL string, header
 		req.Header.Set(key, value)
 	}
 	c.debugRequest(req)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -434,7 +468,7 @@ func (c *Client) postJSONReturn(ctx context.Context, rawURL string, payload any)
 	}
 	c.applyHeaders(req)
 	c.debugRequest(req)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -454,7 +488,7 @@ func (c *Client) postFormReturn(ctx context.Context, rawURL string, fields map[s
 	}
 	c.applyHeaders(req)
 	c.debugRequest(req)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -471,12 +505,12 @@ func (c *Client) applyHeaders(req *http.Request) {
 	req.Header.Set("User-Agent", c.cfg.UserAgent)
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", c.acceptLanguage())
-	if req.Header.Get("Referer") == "" && isBucklerHost(req.URL.Host) {
+	if req.Header.Get("Referer") == "" && c.isBucklerHost(req.URL.Host) {
 		if !strings.Contains(req.URL.Path, "/auth/login") {
 			req.Header.Set("Referer", c.bucklerReferer())
 		}
 	}
-	if isBucklerHost(req.URL.Host) && useNavHeaders(req.URL.Path) {
+	if c.isBucklerHost(req.URL.Host) && useNavHeaders(req.URL.Path) {
 		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
 		req.Header.Set("Upgrade-Insecure-Requests", "1")
 		req.Header.Set("Sec-Fetch-Dest", "document")
@@ -495,8 +529,14 @@ func (c *Client) applyHeaders(req *http.Request) {
 	}
 }
 
-func isBucklerHost(host string) bool {
-	return strings.Contains(host, "streetfighter.com")
+// isBucklerHost は Buckler 側のホストかを判定する。本番ドメインに加え、
+// BucklerBaseURL のホスト（テスト用の偽サーバーなど）も Buckler として扱う。
+func (c *Client) isBucklerHost(host string) bool {
+	if strings.Contains(host, "streetfighter.com") {
+		return true
+	}
+	u, err := url.Parse(c.cfg.BucklerBaseURL)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
 }
 
 func useNavHeaders(path string) bool {
@@ -517,7 +557,7 @@ func (c *Client) debugRequest(req *http.Request) {
 		return
 	}
 	host := req.URL.Host
-	if !isBucklerHost(host) {
+	if !c.isBucklerHost(host) {
 		return
 	}
 	fmt.Printf("[buckler][debug] req %s %s\n", req.Method, req.URL.String())
@@ -534,7 +574,7 @@ func (c *Client) debugResponse(req *http.Request, resp *http.Response) {
 	if !c.cfg.Debug || req == nil || req.URL == nil || resp == nil {
 		return
 	}
-	if !isBucklerHost(req.URL.Host) {
+	if !c.isBucklerHost(req.URL.Host) {
 		return
 	}
 	fmt.Printf("[buckler][debug] resp %d location=%s\n", resp.StatusCode, resp.Header.Get("Location"))
@@ -618,7 +658,7 @@ func (c *Client) followRedirects(ctx context.Context, startURL string) error {
 
 func (c *Client) rewriteBucklerLoginURL(rawURL string) string {
 	u, err := url.Parse(rawURL)
-	if err != nil || !isBucklerHost(u.Host) {
+	if err != nil || !c.isBucklerHost(u.Host) {
 		return rawURL
 	}
 	if u.Path == "/6/buckler/auth/login" {
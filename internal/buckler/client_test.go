@@ -0,0 +1,277 @@
+package buckler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"backend/internal/buckler/bucklertest"
+)
+
+func newFakeClient(t *testing.T, srv *bucklertest.Server) *Client {
+	t.Helper()
+	srv.SetClientEnv(t)
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func newFakeServer(t *testing.T) *bucklertest.Server {
+	t.Helper()
+	srv := bucklertest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLoginAgainstFakeServer(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	if err := c.Login(context.Background()); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !c.hasBucklerSession() {
+		t.Fatal("hasBucklerSession() = false after login")
+	}
+	if got := srv.LoginCount(); got != 1 {
+		t.Fatalf("LoginCount = %d, want 1", got)
+	}
+}
+
+func TestLoginWrongPasswordIsNotAuthenticated(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	c.cfg.Password = "wrong-password"
+
+	err := c.Login(context.Background())
+	if !errors.Is(err, ErrNotAuthenticated) {
+		t.Fatalf("Login() error = %v, want ErrNotAuthenticated", err)
+	}
+}
+
+func TestFetchBattlelogPaginatesFixtures(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	ctx := context.Background()
+
+	first, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1)
+	if err != nil {
+		t.Fatalf("FetchBattlelog(page=1) error = %v", err)
+	}
+	if first.PageProps.TotalPage != 2 || len(first.PageProps.ReplayList) != 10 {
+		t.Fatalf("page 1: total_page=%d items=%d, want 2/10", first.PageProps.TotalPage, len(first.PageProps.ReplayList))
+	}
+	second, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 2)
+	if err != nil {
+		t.Fatalf("FetchBattlelog(page=2) error = %v", err)
+	}
+	if second.PageProps.CurrentPage != 2 || len(second.PageProps.ReplayList) != 2 {
+		t.Fatalf("page 2: current_page=%d items=%d, want 2/2", second.PageProps.CurrentPage, len(second.PageProps.ReplayList))
+	}
+	for _, entry := range first.PageProps.ReplayList {
+		if entry.Kind() != BattlelogCustom {
+			t.Fatalf("entry %s kind = %q, want custom", entry.ReplayID, entry.Kind())
+		}
+	}
+
+	rank, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogRank, 1)
+	if err != nil {
+		t.Fatalf("FetchBattlelog(rank) error = %v", err)
+	}
+	if len(rank.PageProps.ReplayList) != 3 {
+		t.Fatalf("rank items = %d, want 3", len(rank.PageProps.ReplayList))
+	}
+	if got := srv.LoginCount(); got != 1 {
+		t.Fatalf("LoginCount = %d, want 1", got)
+	}
+}
+
+func TestFetchBattlelogRelogsInWhenSessionExpires(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	ctx := context.Background()
+
+	if _, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() error = %v", err)
+	}
+	srv.ExpireSessions()
+
+	res, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1)
+	if err != nil {
+		t.Fatalf("FetchBattlelog() after expiry error = %v", err)
+	}
+	if len(res.PageProps.ReplayList) == 0 {
+		t.Fatal("FetchBattlelog() after expiry returned no replays")
+	}
+	if got := srv.LoginCount(); got != 2 {
+		t.Fatalf("LoginCount = %d, want 2", got)
+	}
+}
+
+func TestFetchBattlelogFollowsBuildIDChange(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	ctx := context.Background()
+
+	if _, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() error = %v", err)
+	}
+	srv.SetBuildID("fake-build-2")
+	if _, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() after buildId change error = %v", err)
+	}
+	if got, _ := c.cache.Get(); got != "fake-build-2" {
+		t.Fatalf("cached buildId = %q, want fake-build-2", got)
+	}
+}
+
+func TestFetchBattlelogUnknownSIDIsNotFound(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	_, err := c.FetchBattlelog(context.Background(), "999", BattlelogCustom, 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FetchBattlelog() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFetchCard(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	card, err := c.FetchCard(context.Background(), bucklertest.FixtureSID)
+	if err != nil {
+		t.Fatalf("FetchCard() error = %v", err)
+	}
+	if card.FighterName != "FakeSelf" || card.FavoriteCharacterTool != "ryu" {
+		t.Fatalf("card = %+v", card)
+	}
+	if _, err := c.FetchCard(context.Background(), "999"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FetchCard(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestIsBucklerHostAcceptsConfiguredHost(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	u, err := url.Parse(srv.BucklerBaseURL())
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if !c.isBucklerHost(u.Host) {
+		t.Fatalf("isBucklerHost(%q) = false", u.Host)
+	}
+	if !c.isBucklerHost("www.streetfighter.com") {
+		t.Fatal("isBucklerHost(www.streetfighter.com) = false")
+	}
+	if c.isBucklerHost("auth.cid.capcom.com") {
+		t.Fatal("isBucklerHost(auth.cid.capcom.com) = true")
+	}
+}
+
+type memCookieStore struct {
+	keyID   string
+	payload string
+	saves   int
+}
+
+func (m *memCookieStore) LoadCookieSnapshot(ctx context.Context, accountKey string) (string, string, error) {
+	return m.keyID, m.payload, nil
+}
+
+func (m *memCookieStore) SaveCookieSnapshot(ctx context.Context, accountKey, keyID, payload string) error {
+	m.keyID, m.payload = keyID, payload
+	m.saves++
+	return nil
+}
+
+func TestCookieSnapshotSkipsLoginAndRotatesKey(t *testing.T) {
+	srv := newFakeServer(t)
+	ctx := context.Background()
+	store := &memCookieStore{}
+	oldKey := "0123456789abcdef0123456789abcdef"
+	newKey := "fedcba9876543210fedcba9876543210"
+
+	first := newFakeClient(t, srv)
+	first.cfg.CookieEncKeyRaw = oldKey
+	first.cookieStore = store
+	if err := first.Login(ctx); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if store.saves != 1 || store.payload == "" {
+		t.Fatalf("saves = %d, want 1 snapshot after login", store.saves)
+	}
+
+	// 鍵を入れ替えて再起動した想定: 旧鍵で復号でき、現行鍵で保存し直される
+	second := newFakeClient(t, srv)
+	second.cfg.CookieEncKeyRaw = newKey
+	second.cfg.CookieEncKeyPrevRaw = oldKey
+	second.cookieStore = store
+	if err := second.restoreCookies(ctx); err != nil {
+		t.Fatalf("restoreCookies() error = %v", err)
+	}
+	if _, err := second.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() error = %v", err)
+	}
+	if got := srv.LoginCount(); got != 1 {
+		t.Fatalf("LoginCount = %d, want 1 (restored cookies should be reused)", got)
+	}
+	wantID, _ := cookieKeyID(newKey)
+	if store.keyID != wantID {
+		t.Fatalf("key_id = %q, want %q after rotation", store.keyID, wantID)
+	}
+
+	// どの鍵でも復号できなければ読み捨てて通常ログインに戻る
+	third := newFakeClient(t, srv)
+	third.cfg.CookieEncKeyRaw = oldKey
+	third.cookieStore = store
+	if err := third.restoreCookies(ctx); err == nil {
+		t.Fatal("restoreCookies() with unknown key error = nil")
+	}
+	if _, err := third.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() after failed restore error = %v", err)
+	}
+	if got := srv.LoginCount(); got != 2 {
+		t.Fatalf("LoginCount = %d, want 2", got)
+	}
+}
+
+func TestIsMaintenancePage(t *testing.T) {
+	redirect := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": []string{"/6/buckler/maintenance?lang=ja-jp"}}}
+	served := &http.Response{StatusCode: http.StatusOK, Request: &http.Request{URL: &url.URL{Path: "/6/buckler/ja-jp/maintenance"}}}
+	normal := &http.Response{StatusCode: http.StatusOK, Request: &http.Request{URL: &url.URL{Path: "/6/buckler/ja-jp/profile/123/battlelog"}}}
+	tests := []struct {
+		name string
+		resp *http.Response
+		body string
+		want bool
+	}{
+		{"maintenance redirect", redirect, "", true},
+		{"maintenance path", served, "<html></html>", true},
+		{"maintenance title ja", normal, "<html><head><title>メンテナンス中 | STREET FIGHTER 6</title></head></html>", true},
+		{"maintenance title en", nil, "<html><head><TITLE>Scheduled Maintenance</TITLE></head></html>", true},
+		{
+			"normal page mentioning maintenance",
+			normal,
+			`<html><head><title>バトルログ | STREET FIGHTER 6</title></head><body>
+<a href="/6/buckler/news/maintenance_0101">定期メンテナンスのお知らせ</a>
+<footer>Scheduled maintenance: every Wednesday</footer></body></html>`,
+			false,
+		},
+		{"no title", nil, "<html><body>メンテナンス</body></html>", false},
+	}
+	for _, tt := range tests {
+		if got := isMaintenancePage(tt.resp, []byte(tt.body)); got != tt.want {
+			t.Errorf("%s: isMaintenancePage() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package buckler
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"backend/internal/buckler/bucklertest"
+)
+
+// fetchConcurrently は n 個の goroutine から同じ sid の battlelog を取得する。
+func fetchConcurrently(t *testing.T, c *Client, n int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(page int) {
+			defer wg.Done()
+			if _, err := c.FetchBattlelog(context.Background(), bucklertest.FixtureSID, BattlelogCustom, page); err != nil {
+				t.Errorf("FetchBattlelog(page=%d) error = %v", page, err)
+			}
+		}(i%2 + 1)
+	}
+	wg.Wait()
+}
+
+func TestClientConcurrentFetchSharesLogin(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	fetchConcurrently(t, c, 8)
+	if got := srv.LoginCount(); got != 1 {
+		t.Fatalf("LoginCount = %d, want 1 (concurrent callers should share one login)", got)
+	}
+
+	// セッション失効と buildId 更新が同時に起きても、再ログインは 1 回にまとまる
+	srv.ExpireSessions()
+	srv.SetBuildID("fake-build-2")
+	fetchConcurrently(t, c, 8)
+	if got := srv.LoginCount(); got != 2 {
+		t.Fatalf("LoginCount = %d, want 2 after expiry", got)
+	}
+	if got, _ := c.cache.Get(); got != "fake-build-2" {
+		t.Fatalf("cached buildId = %q, want fake-build-2", got)
+	}
+}
+
+func TestClientLoginWaiterSurvivesCanceledLeader(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, ctx := range []context.Context{canceled, context.Background()} {
+		wg.Add(1)
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			errs[i] = c.Login(ctx)
+		}(i, ctx)
+	}
+	wg.Wait()
+
+	if errs[1] != nil {
+		t.Fatalf("Login(background) error = %v", errs[1])
+	}
+	if !c.hasBucklerSession() {
+		t.Fatal("hasBucklerSession() = false after login")
+	}
+}
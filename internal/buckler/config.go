@@ -3,6 +3,7 @@ package buckler
 import (
 	"errors"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -34,6 +35,15 @@ type Config struct {
 	BuildIDTTL      time.Duration
 	UserAgent       string
 	CookieEncKeyRaw string
+	// CookieEncKeyPrevRaw はローテーション前の鍵。保存済みCookieの復号にだけ使う。
+	CookieEncKeyPrevRaw string
+	// RateLimitRPS / RateLimitBurst は Buckler ホストへの毎秒リクエスト数とバースト（0 以下は無制限）。
+	RateLimitRPS   float64
+	RateLimitBurst int
+	// AuthRateLimitRPS は Auth0 / CID ホストへの毎秒リクエスト数（0 以下は無制限）。
+	AuthRateLimitRPS float64
+	// DailyRequestCap は全ホスト合計の 1 日（JST）あたりのリクエスト上限（0 は無制限）。
+	DailyRequestCap int
 }
 
 // LoadConfigFromEnv は環境変数から設定を読み込む。
@@ -64,6 +74,11 @@ func LoadConfigFromEnv() (Config, error) {
 		BuildIDTTL:     24 * time.Hour,
 		UserAgent:      envOrDefault("BUCKLER_USER_AGENT", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
 		CookieEncKeyRaw: os.Getenv("BUCKLER_COOKIE_ENC_KEY"),
+		CookieEncKeyPrevRaw: os.Getenv("BUCKLER_COOKIE_ENC_KEY_PREVIOUS"),
+		RateLimitRPS:        envFloat("BUCKLER_RATE_LIMIT_RPS", 1),
+		RateLimitBurst:      envInt("BUCKLER_RATE_LIMIT_BURST", 3),
+		AuthRateLimitRPS:    envFloat("BUCKLER_AUTH_RATE_LIMIT_RPS", 2),
+		DailyRequestCap:     envInt("BUCKLER_DAILY_REQUEST_CAP", 5000),
 	}
 
 	if cfg.Email == "" || cfg.Password == "" {
@@ -81,6 +96,30 @@ func envOrDefault(key, def string) string {
 	return def
 }
 
+func envInt(key string, def int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func envFloat(key string, def float64) float64 {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
 func envBool(key string, def bool) bool {
 	v := strings.TrimSpace(os.Getenv(key))
 	if v == "" {
@@ -0,0 +1,202 @@
+package buckler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CookieStore は暗号化済みのCookieスナップショットを永続化する。
+// Load は未保存なら空文字を返す。
+type CookieStore interface {
+	LoadCookieSnapshot(ctx context.Context, accountKey string) (keyID, payload string, err error)
+	SaveCookieSnapshot(ctx context.Context, accountKey, keyID, payload string) error
+}
+
+// Option は NewClient の追加設定。
+type Option func(*Client)
+
+// WithCookieStore はログインCookieの保存先を設定する。
+// 暗号鍵（BUCKLER_COOKIE_ENC_KEY）が未設定の場合は無視される。
+func WithCookieStore(store CookieStore) Option {
+	return func(c *Client) {
+		c.cookieStore = store
+	}
+}
+
+const cookieStoreTimeout = 10 * time.Second
+
+// cookieSnapshot は複数ホスト分の CookieEnvelope をまとめて保存する形式。
+type cookieSnapshot struct {
+	Envelopes []CookieEnvelope `json:"envelopes"`
+	SavedAt   time.Time        `json:"saved_at"`
+}
+
+// cookieKeyID は鍵のフィンガープリント（鍵そのものは保存しない）。
+func cookieKeyID(keyRaw string) (string, error) {
+	key, err := parseCookieKey(keyRaw)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// cookieAccountKey は保存行のキー。メールアドレスはハッシュ化して保存する。
+func cookieAccountKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *Client) cookieStoreEnabled() bool {
+	return c.cookieStore != nil && c.cfg.CookieEncKeyRaw != ""
+}
+
+// persistedCookieURLs は保存対象のURL（Buckler / Auth0 / CID）。
+func (c *Client) persistedCookieURLs() []string {
+	return []string{c.cfg.BucklerBaseURL, c.cfg.AuthBaseURL, c.cfg.CidBaseURL}
+}
+
+// restoreCookies は保存済みCookieを復号してJarへ戻す。
+// 復号できない場合は読み捨て、次回の EnsureLogin で通常ログインにフォールバックする。
+func (c *Client) restoreCookies(ctx context.Context) error {
+	if !c.cookieStoreEnabled() {
+		return nil
+	}
+	keyID, payload, err := c.cookieStore.LoadCookieSnapshot(ctx, cookieAccountKey(c.cfg.Email))
+	if err != nil {
+		return fmt.Errorf("load cookie snapshot: %w", err)
+	}
+	if payload == "" {
+		return nil
+	}
+
+	snapshot, usedKey, err := c.decryptSnapshot(keyID, payload)
+	if err != nil {
+		return err
+	}
+	for _, env := range snapshot.Envelopes {
+		if err := c.ImportCookies(env); err != nil {
+			return fmt.Errorf("import cookies: %w", err)
+		}
+	}
+	fmt.Printf("[buckler] cookies restored: saved_at=%s session=%t\n", snapshot.SavedAt.Format(time.RFC3339), c.hasBucklerSession())
+
+	// 旧鍵で読めた場合は現行鍵で保存し直す（鍵ローテーション）
+	if usedKey != c.cfg.CookieEncKeyRaw {
+		if err := c.saveCookies(ctx); err != nil {
+			fmt.Printf("[buckler] cookie re-encrypt failed: %v\n", err)
+		} else {
+			fmt.Printf("[buckler] cookies re-encrypted with current key\n")
+		}
+	}
+	return nil
+}
+
+// decryptSnapshot は key_id が一致する鍵を優先し、現行鍵 → 旧鍵の順に復号を試す。
+func (c *Client) decryptSnapshot(keyID, payload string) (cookieSnapshot, string, error) {
+	keys := make([]string, 0, 2)
+	for _, key := range []string{c.cfg.CookieEncKeyRaw, c.cfg.CookieEncKeyPrevRaw} {
+		if key == "" {
+			continue
+		}
+		if id, err := cookieKeyID(key); err == nil && id == keyID {
+			keys = append([]string{key}, keys...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	var lastErr error
+	for _, key := range keys {
+		plain, err := decryptCookieBlob(key, payload)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var snapshot cookieSnapshot
+		if err := json.Unmarshal(plain, &snapshot); err != nil {
+			lastErr = err
+			continue
+		}
+		return snapshot, key, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no cookie encryption key")
+	}
+	return cookieSnapshot{}, "", fmt.Errorf("decrypt cookie snapshot (key_id=%s): %w", keyID, lastErr)
+}
+
+// saveCookies は現在のJarを暗号化して保存する。前回保存時から変化が無ければ何もしない。
+func (c *Client) saveCookies(ctx context.Context) error {
+	if !c.cookieStoreEnabled() || !c.hasBucklerSession() {
+		return nil
+	}
+	envelopes := make([]CookieEnvelope, 0, 3)
+	for _, rawURL := range c.persistedCookieURLs() {
+		env, err := c.ExportCookies(rawURL)
+		if err != nil {
+			return err
+		}
+		if len(env.Cookies) == 0 {
+			continue
+		}
+		// Jar から取り出した Cookie は Path を持たないため、復元時にホスト全体へ付くようにしておく
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		env.URL = u.Scheme + "://" + u.Host + "/"
+		for _, ck := range env.Cookies {
+			ck.Path = "/"
+		}
+		envelopes = append(envelopes, env)
+	}
+
+	plain, err := json.Marshal(envelopes)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(plain)
+	fingerprint := hex.EncodeToString(sum[:])
+	c.cookieMu.Lock()
+	unchanged := fingerprint == c.savedCookieSum
+	c.cookieMu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	keyID, err := cookieKeyID(c.cfg.CookieEncKeyRaw)
+	if err != nil {
+		return err
+	}
+	payload, err := encryptCookieBlob(c.cfg.CookieEncKeyRaw, cookieSnapshot{Envelopes: envelopes, SavedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if err := c.cookieStore.SaveCookieSnapshot(ctx, cookieAccountKey(c.cfg.Email), keyID, payload); err != nil {
+		return fmt.Errorf("save cookie snapshot: %w", err)
+	}
+	c.cookieMu.Lock()
+	c.savedCookieSum = fingerprint
+	c.cookieMu.Unlock()
+	return nil
+}
+
+// persistCookies は保存失敗を取得処理のエラーにしないためのラッパー。
+func (c *Client) persistCookies(ctx context.Context) {
+	if !c.cookieStoreEnabled() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cookieStoreTimeout)
+	defer cancel()
+	if err := c.saveCookies(ctx); err != nil {
+		fmt.Printf("[buckler] cookie save failed: %v\n", err)
+	}
+}
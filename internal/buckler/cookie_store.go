@@ -41,11 +41,29 @@ func (c *Client) ImportCookies(env CookieEnvelope) error {
 
 // EncryptEnvelope はCookieをAES-GCMで暗号化して文字列にする。
 func EncryptEnvelope(keyRaw string, env CookieEnvelope) (string, error) {
+	return encryptCookieBlob(keyRaw, env)
+}
+
+// DecryptEnvelope は暗号化されたCookie文字列を復号する。
+func DecryptEnvelope(keyRaw, blob string) (CookieEnvelope, error) {
+	plain, err := decryptCookieBlob(keyRaw, blob)
+	if err != nil {
+		return CookieEnvelope{}, err
+	}
+	var env CookieEnvelope
+	if err := json.Unmarshal(plain, &env); err != nil {
+		return CookieEnvelope{}, err
+	}
+	return env, nil
+}
+
+// encryptCookieBlob は値をJSONにしてAES-GCMで暗号化する（nonce を先頭に付けて base64）。
+func encryptCookieBlob(keyRaw string, v any) (string, error) {
 	key, err := parseCookieKey(keyRaw)
 	if err != nil {
 		return "", err
 	}
-	plain, err := json.Marshal(env)
+	plain, err := json.Marshal(v)
 	if err != nil {
 		return "", err
 	}
@@ -65,38 +83,30 @@ func EncryptEnvelope(keyRaw string, env CookieEnvelope) (string, error) {
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// DecryptEnvelope は暗号化されたCookie文字列を復号する。
-func DecryptEnvelope(keyRaw, blob string) (CookieEnvelope, error) {
+// decryptCookieBlob は encryptCookieBlob の逆で、復号したJSONを返す。
+func decryptCookieBlob(keyRaw, blob string) ([]byte, error) {
 	key, err := parseCookieKey(keyRaw)
 	if err != nil {
-		return CookieEnvelope{}, err
+		return nil, err
 	}
 	ciphertext, err := base64.StdEncoding.DecodeString(blob)
 	if err != nil {
-		return CookieEnvelope{}, err
+		return nil, err
 	}
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return CookieEnvelope{}, err
+		return nil, err
 	}
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return CookieEnvelope{}, err
+		return nil, err
 	}
 	nonceSize := gcm.NonceSize()
 	if len(ciphertext) < nonceSize {
-		return CookieEnvelope{}, errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 	nonce, data := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plain, err := gcm.Open(nil, nonce, data, nil)
-	if err != nil {
-		return CookieEnvelope{}, err
-	}
-	var env CookieEnvelope
-	if err := json.Unmarshal(plain, &env); err != nil {
-		return CookieEnvelope{}, err
-	}
-	return env, nil
+	return gcm.Open(nil, nonce, data, nil)
 }
 
 // parseCookieKey は32バイト鍵（raw/base64/hex）を解釈する。
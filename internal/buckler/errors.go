@@ -0,0 +1,155 @@
+package buckler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buckler パッケージが返す代表的なエラー。errors.Is で判定できる。
+var (
+	// ErrNotAuthenticated は Buckler にログインできない（再ログインにも失敗した）ことを表す。
+	ErrNotAuthenticated = errors.New("buckler not authenticated")
+	// ErrBuildIDDrift は buildId が取得できない（ページ構成が変わった）ことを表す。
+	ErrBuildIDDrift = errors.New("buckler buildId drift")
+	// ErrRateLimited は 429 で拒否されたことを表す。待ち時間は RetryAfter で取得する。
+	ErrRateLimited = errors.New("buckler rate limited")
+	// ErrNotFound はプロフィールが存在しない（sid の誤り、または非公開）ことを表す。
+	ErrNotFound = errors.New("buckler profile not found")
+	// ErrMaintenance は Buckler がメンテナンス中であることを表す。
+	ErrMaintenance = errors.New("buckler under maintenance")
+	// ErrSchemaChanged はレスポンスの形が想定と違うことを表す。
+	ErrSchemaChanged = errors.New("buckler response schema changed")
+)
+
+// StatusError は想定外の HTTP ステータスを表す。ステータスに応じて上記の sentinel と一致する。
+type StatusError struct {
+	Op         string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("buckler %s: status=%d retry_after=%s", e.Op, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("buckler %s: status=%d", e.Op, e.StatusCode)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrMaintenance:
+		return e.StatusCode == http.StatusServiceUnavailable
+	case ErrNotAuthenticated:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// SchemaError はレスポンスのデコードや必須項目の欠落を表す。ErrSchemaChanged と一致する。
+type SchemaError struct {
+	Op  string
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("buckler %s: unexpected response: %v", e.Op, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaChanged
+}
+
+// RetryAfter はエラーに含まれる待ち時間（Retry-After、日次上限ならリセットまで）を返す。
+func RetryAfter(err error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter, true
+	}
+	var budgetErr *BudgetError
+	if errors.As(err, &budgetErr) {
+		if wait := time.Until(budgetErr.ResetAt); wait > 0 {
+			return wait, true
+		}
+	}
+	return 0, false
+}
+
+func newStatusError(op string, resp *http.Response) *StatusError {
+	return &StatusError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter は秒数または HTTP-date 形式の Retry-After を解釈する。
+func parseRetryAfter(raw string, now time.Time) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(raw); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(raw); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// maintenanceTitlePattern は HTML の <title> を取り出す。
+var maintenanceTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// isMaintenancePage はメンテナンス告知ページかを判定する。
+// 本文のどこかに「メンテナンス」とあるだけの通常ページ（お知らせやフッター）は含めず、
+// メンテナンス用のパスへの遷移か、ページの <title> がメンテナンス告知のときだけ true。
+func isMaintenancePage(resp *http.Response, body []byte) bool {
+	if resp != nil {
+		if isMaintenancePath(getLocation(resp)) {
+			return true
+		}
+		if resp.Request != nil && resp.Request.URL != nil && isMaintenancePath(resp.Request.URL.Path) {
+			return true
+		}
+	}
+	matches := maintenanceTitlePattern.FindSubmatch(body)
+	if len(matches) < 2 {
+		return false
+	}
+	title := strings.ToLower(string(matches[1]))
+	return strings.Contains(title, "maintenance") || strings.Contains(title, "メンテナンス")
+}
+
+// isMaintenancePath は URL（パスのみでもよい）がメンテナンス用のページを指すか判定する。
+func isMaintenancePath(raw string) bool {
+	if raw == "" {
+		return false
+	}
+	path := raw
+	if u, err := url.Parse(raw); err == nil {
+		path = u.Path
+	}
+	for _, segment := range strings.Split(strings.ToLower(path), "/") {
+		if segment == "maintenance" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package buckler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+)
+
+// resettableJar は中身を差し替えられる CookieJar。
+// http.Client.Jar 自体を書き換えるとリクエスト中の goroutine と競合するため、差し替えはこの中で行う。
+type resettableJar struct {
+	mu  sync.RWMutex
+	jar *cookiejar.Jar
+}
+
+func newResettableJar() (*resettableJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cookie jar: %w", err)
+	}
+	return &resettableJar{jar: jar}, nil
+}
+
+func (j *resettableJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *resettableJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+// reset は保持している Cookie をすべて捨てる。
+func (j *resettableJar) reset() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("cookie jar: %w", err)
+	}
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+	return nil
+}
+
+// loginCall は実行中のログイン。同時にログインしようとした呼び出しはこの結果を待つ。
+type loginCall struct {
+	done chan struct{}
+	err  error
+}
+
+// runLogin はログインを 1 本だけ実行し、同時に呼ばれた側はその結果を共有する。
+// resetJar が true なら、ログイン前に Cookie を捨てる（失効した Cookie での再ログイン用）。
+func (c *Client) runLogin(ctx context.Context, resetJar bool) error {
+	for {
+		c.loginMu.Lock()
+		if call := c.loginInFlight; call != nil {
+			c.loginMu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-call.done:
+			}
+			// 先行したログインが呼び出し元の都合で中断された場合は、自分でやり直す
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.err
+		}
+		call := &loginCall{done: make(chan struct{})}
+		c.loginInFlight = call
+		c.loginMu.Unlock()
+
+		err := c.loginOnce(ctx, resetJar)
+
+		c.loginMu.Lock()
+		c.loginInFlight = nil
+		if err == nil {
+			c.loginGen++
+		}
+		c.loginMu.Unlock()
+		call.err = err
+		close(call.done)
+		return err
+	}
+}
+
+func (c *Client) loginOnce(ctx context.Context, resetJar bool) error {
+	if resetJar {
+		if err := c.jar.reset(); err != nil {
+			return err
+		}
+	}
+	if err := c.login(ctx); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotAuthenticated, err)
+	}
+	c.persistCookies(ctx)
+	return nil
+}
+
+// loginGeneration はログインに成功した回数を返す。再ログインが必要かの判定に使う。
+func (c *Client) loginGeneration() uint64 {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	return c.loginGen
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
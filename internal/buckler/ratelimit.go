@@ -0,0 +1,244 @@
+package buckler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// 429 に Retry-After が無かった場合にそのホストを止める時間。
+const defaultRateLimitBlock = 60 * time.Second
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// RequestStats は Buckler 系ホストへのリクエスト数の集計（ヘルスチェック用）。
+type RequestStats struct {
+	Total        int64
+	ByHost       map[string]int64
+	Throttled    int64
+	Rejected     int64
+	RateLimited  int64
+	DailyCount   int
+	DailyCap     int
+	DailyResetAt time.Time
+	BlockedUntil *time.Time
+}
+
+// BudgetError は 1 日あたりのリクエスト上限に達したことを表す。ErrRateLimited と一致する。
+type BudgetError struct {
+	Cap     int
+	ResetAt time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("buckler daily request cap reached: cap=%d reset_at=%s", e.Cap, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// requestLimiter はホスト単位のトークンバケットと日次上限、429 によるホスト停止を管理する。
+type requestLimiter struct {
+	mu           sync.Mutex
+	bucklerHost  func(host string) bool
+	bucklerRate  rate.Limit
+	otherRate    rate.Limit
+	burst        int
+	dailyCap     int
+	limiters     map[string]*rate.Limiter
+	blockedUntil map[string]time.Time
+	day          string
+	dayCount     int
+	stats        RequestStats
+	now          func() time.Time
+}
+
+func newRequestLimiter(cfg Config, bucklerHost func(host string) bool) *requestLimiter {
+	burst := cfg.RateLimitBurst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &requestLimiter{
+		bucklerHost:  bucklerHost,
+		bucklerRate:  limitFromRPS(cfg.RateLimitRPS),
+		otherRate:    limitFromRPS(cfg.AuthRateLimitRPS),
+		burst:        burst,
+		dailyCap:     cfg.DailyRequestCap,
+		limiters:     make(map[string]*rate.Limiter),
+		blockedUntil: make(map[string]time.Time),
+		stats:        RequestStats{ByHost: make(map[string]int64), DailyCap: cfg.DailyRequestCap},
+		now:          time.Now,
+	}
+}
+
+// limitFromRPS は 0 以下を無制限として扱う。
+func limitFromRPS(rps float64) rate.Limit {
+	if rps <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(rps)
+}
+
+// wait はリクエスト前に呼ぶ。日次上限・429 による停止・トークンバケットを順に確認する。
+func (l *requestLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	for {
+		now := l.now()
+		l.rollDayLocked(now)
+		if l.dailyCap > 0 && l.dayCount >= l.dailyCap {
+			l.stats.Rejected++
+			resetAt := l.stats.DailyResetAt
+			l.mu.Unlock()
+			return &BudgetError{Cap: l.dailyCap, ResetAt: resetAt}
+		}
+		until, ok := l.blockedUntil[host]
+		if !ok || !now.Before(until) {
+			break
+		}
+		wait := until.Sub(now)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(until) {
+			l.stats.Rejected++
+			l.mu.Unlock()
+			return &StatusError{Op: "throttle " + host, StatusCode: http.StatusTooManyRequests, RetryAfter: wait}
+		}
+		l.mu.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		// 待っている間に他のリクエストで日次上限に達したり、停止が延びたりしていないか確認し直す
+		l.mu.Lock()
+	}
+	limiter := l.limiterLocked(host)
+	// 日次上限は待機前に確保しておき、並行リクエストで上限を超えないようにする
+	l.dayCount++
+	l.stats.DailyCount = l.dayCount
+	l.mu.Unlock()
+
+	if r := limiter.Reserve(); r.OK() {
+		delay := r.Delay()
+		if delay > 0 {
+			l.mu.Lock()
+			l.stats.Throttled++
+			l.mu.Unlock()
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				r.Cancel()
+				l.release()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+
+	l.mu.Lock()
+	l.stats.Total++
+	l.stats.ByHost[host]++
+	l.mu.Unlock()
+	return nil
+}
+
+// release は実際には送らなかったリクエスト分の日次枠を戻す。
+func (l *requestLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dayCount > 0 {
+		l.dayCount--
+		l.stats.DailyCount = l.dayCount
+	}
+}
+
+// observe はレスポンスを見て、429 ならそのホストを Retry-After（無ければ既定値）の間止める。
+func (l *requestLimiter) observe(host string, resp *http.Response) {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	now := l.now()
+	wait := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if wait <= 0 {
+		wait = defaultRateLimitBlock
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.RateLimited++
+	until := now.Add(wait)
+	if cur, ok := l.blockedUntil[host]; !ok || until.After(cur) {
+		l.blockedUntil[host] = until
+	}
+	fmt.Printf("[buckler] rate limited by %s: blocked until %s\n", host, until.Format(time.RFC3339))
+}
+
+func (l *requestLimiter) snapshot() RequestStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.rollDayLocked(now)
+	out := l.stats
+	out.ByHost = make(map[string]int64, len(l.stats.ByHost))
+	for host, n := range l.stats.ByHost {
+		out.ByHost[host] = n
+	}
+	for _, until := range l.blockedUntil {
+		if until.After(now) && (out.BlockedUntil == nil || until.After(*out.BlockedUntil)) {
+			t := until
+			out.BlockedUntil = &t
+		}
+	}
+	return out
+}
+
+func (l *requestLimiter) limiterLocked(host string) *rate.Limiter {
+	if limiter, ok := l.limiters[host]; ok {
+		return limiter
+	}
+	limit := l.otherRate
+	if l.bucklerHost != nil && l.bucklerHost(host) {
+		limit = l.bucklerRate
+	}
+	limiter := rate.NewLimiter(limit, l.burst)
+	l.limiters[host] = limiter
+	return limiter
+}
+
+// rollDayLocked は JST の日付が変わっていれば日次カウントをリセットする。
+func (l *requestLimiter) rollDayLocked(now time.Time) {
+	local := now.In(jst)
+	day := local.Format("2006-01-02")
+	if day == l.day {
+		return
+	}
+	l.day = day
+	l.dayCount = 0
+	l.stats.DailyCount = 0
+	y, m, d := local.Date()
+	l.stats.DailyResetAt = time.Date(y, m, d+1, 0, 0, 0, 0, jst)
+}
+
+// do は全リクエストの送信口。レート制限を通してから送り、429 を記録する。
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := c.limiter.wait(req.Context(), host); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.limiter.observe(host, resp)
+	return resp, nil
+}
+
+// RequestStats はリクエスト数・制限状況の集計を返す。
+func (c *Client) RequestStats() RequestStats {
+	return c.limiter.snapshot()
+}
@@ -0,0 +1,111 @@
+package buckler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/buckler/bucklertest"
+)
+
+func TestRequestLimiterTokenBucket(t *testing.T) {
+	l := newRequestLimiter(Config{RateLimitRPS: 20, RateLimitBurst: 1}, func(string) bool { return true })
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(ctx, "buckler.test"); err != nil {
+			t.Fatalf("wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("3 requests at 20rps/burst 1 took %s, want >= ~100ms", elapsed)
+	}
+	stats := l.snapshot()
+	if stats.Total != 3 || stats.Throttled != 2 || stats.ByHost["buckler.test"] != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestRequestLimiterRechecksDailyCapAfterBlock(t *testing.T) {
+	l := newRequestLimiter(Config{DailyRequestCap: 2}, func(string) bool { return true })
+	ctx := context.Background()
+	if err := l.wait(ctx, "buckler.test"); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	l.mu.Lock()
+	l.blockedUntil["buckler.test"] = l.now().Add(100 * time.Millisecond)
+	l.mu.Unlock()
+
+	// 429 の停止を待っている間に、別ホストへのリクエストが最後の枠を使う
+	done := make(chan error, 1)
+	go func() { done <- l.wait(ctx, "buckler.test") }()
+	time.Sleep(20 * time.Millisecond)
+	if err := l.wait(ctx, "auth.test"); err != nil {
+		t.Fatalf("wait(other host) error = %v", err)
+	}
+
+	err := <-done
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("wait() after block error = %v, want BudgetError", err)
+	}
+	if stats := l.snapshot(); stats.DailyCount != 2 {
+		t.Fatalf("daily count = %d, want 2 (cap)", stats.DailyCount)
+	}
+}
+
+func TestFetchBattlelogRateLimitedBlocksHost(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	ctx := context.Background()
+
+	if _, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1); err != nil {
+		t.Fatalf("FetchBattlelog() error = %v", err)
+	}
+	srv.FailBattlelog(1, 429, "120")
+	_, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("FetchBattlelog() error = %v, want ErrRateLimited", err)
+	}
+	if wait, ok := RetryAfter(err); !ok || wait != 120*time.Second {
+		t.Fatalf("RetryAfter() = %s, %t, want 120s", wait, ok)
+	}
+
+	// ブロック中は偽サーバーに届く前に断る
+	before := srv.RequestCount("battlelog")
+	shortCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, err := c.FetchBattlelog(shortCtx, bucklertest.FixtureSID, BattlelogCustom, 1); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("FetchBattlelog() while blocked error = %v, want ErrRateLimited", err)
+	}
+	if got := srv.RequestCount("battlelog"); got != before {
+		t.Fatalf("battlelog requests = %d, want %d (blocked)", got, before)
+	}
+	stats := c.RequestStats()
+	if stats.RateLimited != 1 || stats.BlockedUntil == nil || stats.Rejected == 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestFetchBattlelogDailyCap(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newFakeClient(t, srv)
+	ctx := context.Background()
+
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	// buildId の取得 1 回分だけ残す
+	c.limiter.dailyCap = c.RequestStats().DailyCount + 1
+
+	_, err := c.FetchBattlelog(ctx, bucklertest.FixtureSID, BattlelogCustom, 1)
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("FetchBattlelog() error = %v, want BudgetError", err)
+	}
+	if _, ok := RetryAfter(err); !ok {
+		t.Fatal("RetryAfter() for BudgetError = false")
+	}
+}
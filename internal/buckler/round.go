@@ -0,0 +1,42 @@
+package buckler
+
+// round_results の各値はそのラウンドの決着の仕方を表す（0 はそのラウンドを取れなかった）。
+// Buckler の対戦ログ画面のアイコン（V / C / T / D / P / SA / CA / OD）に対応する。
+const (
+	RoundFinishNone        = "none"
+	RoundFinishKO          = "ko"
+	RoundFinishChip        = "chip"
+	RoundFinishTime        = "time"
+	RoundFinishDoubleKO    = "double_ko"
+	RoundFinishPerfect     = "perfect"
+	RoundFinishSuperArt    = "super_art"
+	RoundFinishCriticalArt = "critical_art"
+	RoundFinishOverdrive   = "overdrive"
+	RoundFinishOther       = "other"
+)
+
+// RoundFinish は round_results の値を決着の種類に変換する。未知の値は RoundFinishOther。
+func RoundFinish(code int) string {
+	switch code {
+	case 0:
+		return RoundFinishNone
+	case 1:
+		return RoundFinishKO
+	case 2:
+		return RoundFinishChip
+	case 3:
+		return RoundFinishTime
+	case 4:
+		return RoundFinishDoubleKO
+	case 5:
+		return RoundFinishPerfect
+	case 6:
+		return RoundFinishSuperArt
+	case 7:
+		return RoundFinishCriticalArt
+	case 8:
+		return RoundFinishOverdrive
+	default:
+		return RoundFinishOther
+	}
+}
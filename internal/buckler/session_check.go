@@ -0,0 +1,116 @@
+package buckler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// errSessionRejected は Cookie はあるがサーバー側でセッションが無効になっている状態。
+var errSessionRejected = errors.New("buckler session rejected")
+
+// checkNextDataResponse は _next/data の応答が未ログイン扱いになっていないか判定する。
+// 認証ページへのリダイレクト、HTML、空の pageProps（または __N_REDIRECT）を失効とみなす。
+func (c *Client) checkNextDataResponse(resp *http.Response, body []byte) error {
+	if resp == nil {
+		return nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: status=%d", errSessionRejected, resp.StatusCode)
+	}
+	if isRedirect(resp.StatusCode) {
+		if isMaintenancePage(resp, nil) {
+			return fmt.Errorf("%w: redirect to %s", ErrMaintenance, getLocation(resp))
+		}
+		if loc := getLocation(resp); c.isAuthRedirect(loc) {
+			return fmt.Errorf("%w: redirect to %s", errSessionRejected, loc)
+		}
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '<' {
+		if isMaintenancePage(resp, trimmed) {
+			return fmt.Errorf("%w: maintenance page", ErrMaintenance)
+		}
+		return fmt.Errorf("%w: html response", errSessionRejected)
+	}
+	var payload struct {
+		PageProps map[string]json.RawMessage `json:"pageProps"`
+	}
+	if err := json.Unmarshal(trimmed, &payload); err != nil {
+		// JSON として読めないものは呼び出し側のデコードエラーに任せる
+		return nil
+	}
+	if len(payload.PageProps) == 0 {
+		return fmt.Errorf("%w: empty pageProps", errSessionRejected)
+	}
+	if raw, ok := payload.PageProps["__N_REDIRECT"]; ok {
+		var loc string
+		_ = json.Unmarshal(raw, &loc)
+		if c.isAuthRedirect(loc) {
+			return fmt.Errorf("%w: next redirect to %s", errSessionRejected, loc)
+		}
+	}
+	return nil
+}
+
+// isAuthRedirect はリダイレクト先がログイン系（Buckler の auth / CID / Auth0）かを判定する。
+func (c *Client) isAuthRedirect(loc string) bool {
+	if loc == "" {
+		return false
+	}
+	lower := strings.ToLower(loc)
+	if strings.Contains(lower, "/auth/login") || strings.Contains(lower, "loginep") {
+		return true
+	}
+	u, err := url.Parse(loc)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, raw := range []string{c.cfg.AuthBaseURL, c.cfg.CidBaseURL} {
+		if base, err := url.Parse(raw); err == nil && base.Host != "" && strings.EqualFold(base.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// relogin は失効した Cookie を捨ててログインし直す。
+// seenGen の時点から他の goroutine が既にログインし直していれば、それを使って何もしない。
+func (c *Client) relogin(ctx context.Context, seenGen uint64) error {
+	c.loginMu.Lock()
+	alreadyDone := c.loginGen != seenGen && c.loginInFlight == nil
+	c.loginMu.Unlock()
+	if alreadyDone {
+		return nil
+	}
+	fmt.Printf("[buckler] session expired, re-login\n")
+	return c.runLogin(ctx, true)
+}
+
+// withSessionRetry は fn が失効を検知した場合に一度だけ再ログインして再実行する。
+func (c *Client) withSessionRetry(ctx context.Context, fn func() error) error {
+	gen := c.loginGeneration()
+	err := fn()
+	if !errors.Is(err, errSessionRejected) {
+		return err
+	}
+	fmt.Printf("[buckler] %v\n", err)
+	if err := c.relogin(ctx, gen); err != nil {
+		return err
+	}
+	err = fn()
+	if errors.Is(err, errSessionRejected) {
+		return fmt.Errorf("%w: %w", ErrNotAuthenticated, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+// Package chart は統計を PNG のグラフに描く。外部のフォントや描画ライブラリは使わず、
+// 標準ライブラリと組み込みのビットマップフォントだけで描画する。
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// DefaultWidth は Discord の embed 画像として縮小されにくい幅。
+const DefaultWidth = 640
+
+const (
+	padding    = 16
+	titleScale = 2
+	labelScale = 2
+	titleGap   = 10
+)
+
+// Tone は勝ち負けなどの色分け。
+type Tone int
+
+const (
+	ToneNeutral Tone = iota
+	ToneWin
+	ToneLoss
+)
+
+var (
+	colorBackground = color.RGBA{0x2B, 0x2D, 0x31, 0xFF}
+	colorTitle      = color.RGBA{0xF2, 0xF3, 0xF5, 0xFF}
+	colorText       = color.RGBA{0xB5, 0xBA, 0xC1, 0xFF}
+	colorGrid       = color.RGBA{0x4E, 0x50, 0x58, 0xFF}
+	colorTrack      = color.RGBA{0x38, 0x3A, 0x40, 0xFF}
+	colorLine       = color.RGBA{0x58, 0x65, 0xF2, 0xFF}
+	colorWin        = color.RGBA{0x23, 0xA5, 0x59, 0xFF}
+	colorLoss       = color.RGBA{0xDA, 0x37, 0x3C, 0xFF}
+	colorNeutral    = color.RGBA{0x80, 0x84, 0x8E, 0xFF}
+)
+
+func toneColor(tone Tone) color.RGBA {
+	switch tone {
+	case ToneWin:
+		return colorWin
+	case ToneLoss:
+		return colorLoss
+	default:
+		return colorNeutral
+	}
+}
+
+// Panel は 1 枚の画像に縦に並べるグラフ 1 つ分。
+type Panel interface {
+	// Height は幅 width で描くときの高さ（px）。
+	Height(width int) int
+	// Draw は area の中に描く。
+	Draw(img *image.RGBA, area image.Rectangle)
+}
+
+// Render は panels を上から順に並べた PNG を返す。
+func Render(width int, panels ...Panel) ([]byte, error) {
+	if width <= 2*padding {
+		return nil, fmt.Errorf("width too small: %d", width)
+	}
+	if len(panels) == 0 {
+		return nil, errors.New("no panels")
+	}
+	inner := width - 2*padding
+	height := padding
+	for _, p := range panels {
+		height += p.Height(inner) + padding
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colorBackground}, image.Point{}, draw.Src)
+
+	y := padding
+	for _, p := range panels {
+		h := p.Height(inner)
+		p.Draw(img, image.Rect(padding, y, padding+inner, y+h))
+		y += h + padding
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func titleHeight(title string) int {
+	if title == "" {
+		return 0
+	}
+	return textHeight(titleScale) + titleGap
+}
+
+// drawTitle はパネルの見出しを描き、残りの領域を返す。
+func drawTitle(img *image.RGBA, area image.Rectangle, title string) image.Rectangle {
+	if title == "" {
+		return area
+	}
+	drawText(img, area.Min.X, area.Min.Y, fitText(title, titleScale, area.Dx()), titleScale, colorTitle)
+	area.Min.Y += titleHeight(title)
+	return area
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r.Intersect(img.Bounds()), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// drawLine は太さ thickness の線分を描く（Bresenham）。
+func drawLine(img *image.RGBA, x0, y0, x1, y1, thickness int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	half := thickness / 2
+	e := dx + dy
+	for {
+		fillRect(img, image.Rect(x0-half, y0-half, x0-half+thickness, y0-half+thickness), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
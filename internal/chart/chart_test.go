@@ -0,0 +1,55 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	data, err := Render(DefaultWidth,
+		Line{Title: "Win Rate", Values: []float64{0.2, 0.5, 0.8, 0.6}, Min: 0, Max: 1, Guides: []float64{0, 0.5, 1}},
+		Bars{Title: "By Character", Bars: []Bar{{Label: "chunli", Value: 0.75, Text: "75.0% 8G", Tone: ToneWin}, {Label: "jp", Value: 0.25, Text: "25.0% 4G", Tone: ToneLoss}}, Guide: 0.5},
+		Strip{Title: "Sets", Cells: []Cell{{Label: "3-1", Tone: ToneWin}, {Label: "2-2"}, {Label: "0-5", Tone: ToneLoss}}},
+	)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := img.Bounds().Dx(); got != DefaultWidth {
+		t.Fatalf("width = %d, want %d", got, DefaultWidth)
+	}
+	// 見出し 3 つ + 折れ線 + 棒 2 本 + マス 1 段 + 余白
+	want := padding + (titleHeight("x") + linePlotHeight + padding) + (titleHeight("x") + 2*barRowHeight + padding) + (titleHeight("x") + stripCellHeight + padding)
+	if got := img.Bounds().Dy(); got != want {
+		t.Fatalf("height = %d, want %d", got, want)
+	}
+}
+
+func TestRenderNoPanels(t *testing.T) {
+	if _, err := Render(DefaultWidth); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got := fitText("chunli", labelScale, textWidth("chunli", labelScale)); got != "chunli" {
+		t.Fatalf("fitText = %q", got)
+	}
+	got := fitText("deejay", labelScale, textWidth("dee.", labelScale))
+	if got != "dee." {
+		t.Fatalf("fitText = %q, want dee.", got)
+	}
+}
+
+func TestGlyphFallback(t *testing.T) {
+	if glyphFor('a') != glyphs['A'] {
+		t.Fatal("lowercase should use uppercase glyph")
+	}
+	if glyphFor('春') != glyphs['?'] {
+		t.Fatal("unknown rune should use '?'")
+	}
+}
@@ -0,0 +1,198 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Line は値の推移を古い順に左から折れ線で描く。
+type Line struct {
+	Title  string
+	Values []float64
+	// Min / Max は縦軸の範囲。Min >= Max なら Values の最小・最大を使う。
+	Min, Max float64
+	// Guides は目盛り線を引く値。
+	Guides []float64
+	// Format は目盛りの表示。nil なら整数で表示する。
+	Format func(float64) string
+}
+
+const linePlotHeight = 160
+
+func (l Line) Height(width int) int {
+	return titleHeight(l.Title) + linePlotHeight
+}
+
+func (l Line) Draw(img *image.RGBA, area image.Rectangle) {
+	area = drawTitle(img, area, l.Title)
+	lo, hi := l.Min, l.Max
+	if lo >= hi {
+		lo, hi = valueRange(l.Values)
+	}
+	format := l.Format
+	if format == nil {
+		format = func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	}
+
+	labelWidth := 0
+	for _, g := range l.Guides {
+		labelWidth = max(labelWidth, textWidth(format(g), labelScale))
+	}
+	plot := area
+	plot.Min.X += labelWidth + 8
+	half := textHeight(labelScale) / 2
+	plot.Min.Y += half
+	plot.Max.Y -= half
+	if plot.Dx() < 2 || plot.Dy() < 2 {
+		return
+	}
+	yOf := func(v float64) int {
+		ratio := 0.5
+		if hi > lo {
+			ratio = (v - lo) / (hi - lo)
+		}
+		ratio = math.Min(math.Max(ratio, 0), 1)
+		return plot.Max.Y - 1 - int(math.Round(ratio*float64(plot.Dy()-1)))
+	}
+	for _, g := range l.Guides {
+		y := yOf(g)
+		fillRect(img, image.Rect(plot.Min.X, y, plot.Max.X, y+1), colorGrid)
+		drawText(img, area.Min.X, y-half, format(g), labelScale, colorText)
+	}
+
+	n := len(l.Values)
+	if n == 0 {
+		return
+	}
+	xOf := func(idx int) int {
+		if n == 1 {
+			return plot.Min.X + plot.Dx()/2
+		}
+		return plot.Min.X + int(math.Round(float64(idx)*float64(plot.Dx()-1)/float64(n-1)))
+	}
+	for idx := 1; idx < n; idx++ {
+		drawLine(img, xOf(idx-1), yOf(l.Values[idx-1]), xOf(idx), yOf(l.Values[idx]), 3, colorLine)
+	}
+	lastX, lastY := xOf(n-1), yOf(l.Values[n-1])
+	fillRect(img, image.Rect(lastX-3, lastY-3, lastX+4, lastY+4), colorTitle)
+}
+
+func valueRange(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 1
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi
+}
+
+// Bar は横棒 1 本分。Value は 0〜1 の割合。
+type Bar struct {
+	Label string
+	Value float64
+	// Text は棒の右に添える値（"62.5% 8G" など）。
+	Text string
+	Tone Tone
+}
+
+// Bars は項目ごとの割合を横棒で描く。
+type Bars struct {
+	Title string
+	Bars  []Bar
+	// Guide は縦の補助線を引く割合（勝率 50% なら 0.5）。0 以下なら引かない。
+	Guide float64
+}
+
+const (
+	barRowHeight     = 26
+	barHeight        = 16
+	barMaxLabelChars = 12
+)
+
+func (b Bars) Height(width int) int {
+	return titleHeight(b.Title) + len(b.Bars)*barRowHeight
+}
+
+func (b Bars) Draw(img *image.RGBA, area image.Rectangle) {
+	area = drawTitle(img, area, b.Title)
+	labelWidth, valueWidth := 0, 0
+	for _, bar := range b.Bars {
+		labelWidth = max(labelWidth, textWidth(bar.Label, labelScale))
+		valueWidth = max(valueWidth, textWidth(bar.Text, labelScale))
+	}
+	labelWidth = min(labelWidth, (barMaxLabelChars*(glyphWidth+glyphSpacing)-glyphSpacing)*labelScale)
+	trackLeft := area.Min.X + labelWidth + 10
+	trackRight := area.Max.X - valueWidth - 10
+	if trackRight-trackLeft < 10 {
+		return
+	}
+	trackWidth := trackRight - trackLeft
+
+	for idx, bar := range b.Bars {
+		top := area.Min.Y + idx*barRowHeight
+		textY := top + (barHeight-textHeight(labelScale))/2
+		drawText(img, area.Min.X, textY, fitText(bar.Label, labelScale, labelWidth), labelScale, colorText)
+		fillRect(img, image.Rect(trackLeft, top, trackRight, top+barHeight), colorTrack)
+		value := math.Min(math.Max(bar.Value, 0), 1)
+		fillRect(img, image.Rect(trackLeft, top, trackLeft+int(math.Round(value*float64(trackWidth))), top+barHeight), toneColor(bar.Tone))
+		drawText(img, trackRight+10, textY, bar.Text, labelScale, colorTitle)
+	}
+	if b.Guide > 0 && b.Guide < 1 {
+		x := trackLeft + int(math.Round(b.Guide*float64(trackWidth)))
+		fillRect(img, image.Rect(x, area.Min.Y, x+1, area.Min.Y+len(b.Bars)*barRowHeight-(barRowHeight-barHeight)), colorTitle)
+	}
+}
+
+// Cell は Strip の 1 マス。
+type Cell struct {
+	Label string
+	Tone  Tone
+}
+
+// Strip はセットごとの勝敗などを古い順に左からマスで並べる。
+type Strip struct {
+	Title string
+	Cells []Cell
+}
+
+const (
+	stripCellHeight   = 36
+	stripCellMaxWidth = 72
+	stripCellGap      = 4
+)
+
+func (s Strip) Height(width int) int {
+	return titleHeight(s.Title) + stripCellHeight
+}
+
+func (s Strip) Draw(img *image.RGBA, area image.Rectangle) {
+	area = drawTitle(img, area, s.Title)
+	n := len(s.Cells)
+	if n == 0 {
+		return
+	}
+	cellWidth := min((area.Dx()+stripCellGap)/n-stripCellGap, stripCellMaxWidth)
+	if cellWidth < 1 {
+		return
+	}
+	for idx, cell := range s.Cells {
+		left := area.Min.X + idx*(cellWidth+stripCellGap)
+		fillRect(img, image.Rect(left, area.Min.Y, left+cellWidth, area.Min.Y+stripCellHeight), toneColor(cell.Tone))
+		scale := labelScale
+		if textWidth(cell.Label, scale) > cellWidth-4 {
+			scale = 1
+		}
+		if textWidth(cell.Label, scale) > cellWidth-4 {
+			continue
+		}
+		x := left + (cellWidth-textWidth(cell.Label, scale))/2
+		y := area.Min.Y + (stripCellHeight-textHeight(scale))/2
+		drawText(img, x, y, cell.Label, scale, colorTitle)
+	}
+}
@@ -2,6 +2,13 @@ package discord
 
 import "github.com/bwmarrin/discordgo"
 
+// コンテキストメニューの名前。登録と振り分けの両方で使う。
+const (
+	sf6RecordVsUserMenu = "SF6: my record vs this user"
+	sf6ProfileCardMenu  = "SF6: show profile card"
+	sf6RefreshStatsMenu = "Refresh"
+)
+
 // Commands はこのBotで使う全てのスラッシュコマンド定義を返す。
 func Commands() []*discordgo.ApplicationCommand {
 	manageChannelsPerm := int64(discordgo.PermissionManageChannels)
@@ -25,10 +32,41 @@ func Commands() []*discordgo.ApplicationCommand {
 				v := false
 				return &v
 			}(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "all",
+					Description: "Fetch recent pages for all linked accounts and friends.",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "Show the last fetch per fighter, recent failures and the next poll.",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "backfill",
+					Description: "Walk every battlelog page for one sid (resumable).",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "subject_code",
+							Description: "SF6 user code (sid) to backfill",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "restart",
+							Description: "Start over from page 1 instead of resuming",
+							Required:    false,
+						},
+					},
+				},
+			},
 		},
 		{
 			Name:        "sf6_stats",
-			Description: "Show SF6 stats (range/count).",
+			Description: "Show SF6 stats (range/count/set/matchup).",
 			DMPermission: func() *bool {
 				v := false
 				return &v
@@ -40,10 +78,11 @@ func Commands() []*discordgo.ApplicationCommand {
 					Description: "Stats by date range (JST).",
 					Options: []*discordgo.ApplicationCommandOption{
 						{
-							Type:        discordgo.ApplicationCommandOptionString,
-							Name:        "opponent_code",
-							Description: "Opponent SF6 user code (sid)",
-							Required:    true,
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
 						},
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
@@ -63,6 +102,7 @@ func Commands() []*discordgo.ApplicationCommand {
 							Description: "Subject SF6 user code (sid)",
 							Required:    false,
 						},
+						sf6ModeOption(),
 					},
 				},
 				{
@@ -71,10 +111,11 @@ func Commands() []*discordgo.ApplicationCommand {
 					Description: "Stats by recent N matches.",
 					Options: []*discordgo.ApplicationCommandOption{
 						{
-							Type:        discordgo.ApplicationCommandOptionString,
-							Name:        "opponent_code",
-							Description: "Opponent SF6 user code (sid)",
-							Required:    true,
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
 						},
 						{
 							Type:        discordgo.ApplicationCommandOptionInteger,
@@ -88,6 +129,7 @@ func Commands() []*discordgo.ApplicationCommand {
 							Description: "Subject SF6 user code (sid)",
 							Required:    false,
 						},
+						sf6ModeOption(),
 					},
 				},
 				{
@@ -95,11 +137,33 @@ func Commands() []*discordgo.ApplicationCommand {
 					Name:        "set",
 					Description: "Stats grouped by <=30min intervals.",
 					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
+						},
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
-							Name:        "opponent_code",
-							Description: "Opponent SF6 user code (sid)",
-							Required:    true,
+							Name:        "subject_code",
+							Description: "Subject SF6 user code (sid)",
+							Required:    false,
+						},
+						sf6ModeOption(),
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "matchup",
+					Description: "Win rate by my character x opponent character.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
 						},
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
@@ -107,6 +171,13 @@ func Commands() []*discordgo.ApplicationCommand {
 							Description: "Subject SF6 user code (sid)",
 							Required:    false,
 						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "min_games",
+							Description: "Hide pairings with fewer games (default 3)",
+							Required:    false,
+						},
+						sf6ModeOption(),
 					},
 				},
 			},
@@ -120,10 +191,11 @@ func Commands() []*discordgo.ApplicationCommand {
 			}(),
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "opponent_code",
-					Description: "Opponent SF6 user code (sid)",
-					Required:    true,
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "opponent_code",
+					Description:  "Opponent SF6 user code (sid)",
+					Required:     true,
+					Autocomplete: true,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
@@ -131,11 +203,12 @@ func Commands() []*discordgo.ApplicationCommand {
 					Description: "Subject SF6 user code (sid)",
 					Required:    false,
 				},
+				sf6ModeOption(),
 			},
 		},
 		{
 			Name:        "sf6_session",
-			Description: "Start/end a session and show stats.",
+			Description: "Start/end/list sessions and show stats.",
 			DMPermission: func() *bool {
 				v := false
 				return &v
@@ -147,10 +220,11 @@ func Commands() []*discordgo.ApplicationCommand {
 					Description: "Start a session.",
 					Options: []*discordgo.ApplicationCommandOption{
 						{
-							Type:        discordgo.ApplicationCommandOptionString,
-							Name:        "opponent_code",
-							Description: "Opponent SF6 user code (sid)",
-							Required:    true,
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
 						},
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
@@ -166,10 +240,11 @@ func Commands() []*discordgo.ApplicationCommand {
 					Description: "End a session and show stats.",
 					Options: []*discordgo.ApplicationCommandOption{
 						{
-							Type:        discordgo.ApplicationCommandOptionString,
-							Name:        "opponent_code",
-							Description: "Opponent SF6 user code (sid)",
-							Required:    true,
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     true,
+							Autocomplete: true,
 						},
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
@@ -179,6 +254,20 @@ func Commands() []*discordgo.ApplicationCommand {
 						},
 					},
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List past sessions and show their stats.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "opponent_code",
+							Description:  "Opponent SF6 user code (sid)",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
 			},
 		},
 		{
@@ -197,6 +286,160 @@ func Commands() []*discordgo.ApplicationCommand {
 				return &v
 			}(),
 		},
+		{
+			Name:        "sf6_feed",
+			Description: "Post new SF6 matches to a channel.",
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "channel",
+					Description: "Set the feed channel (admin only).",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionChannel,
+							Name:        "channel",
+							Description: "Target text channel",
+							Required:    true,
+							ChannelTypes: []discordgo.ChannelType{
+								discordgo.ChannelTypeGuildText,
+								discordgo.ChannelTypeGuildNews,
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "off",
+					Description: "Stop posting to the feed channel (admin only).",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "optout",
+					Description: "Do not post your matches to the feed.",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "optin",
+					Description: "Post your matches to the feed again.",
+				},
+			},
+		},
+		{
+			Name:        "sf6_leaderboard",
+			Description: "Rank linked members by battles against each other.",
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "sort",
+					Description: "Sort order (default: winrate)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "win rate", Value: "winrate"},
+						{Name: "games played", Value: "games"},
+						{Name: "longest win streak", Value: "streak"},
+						{Name: "distinct opponents", Value: "opponents"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "min_games",
+					Description: "Minimum games to be ranked by win rate (default 10)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "from",
+					Description: "Start date (YYYY-MM-DD, JST). Omit with to for all time",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "to",
+					Description: "End date (YYYY-MM-DD, JST)",
+					Required:    false,
+				},
+				sf6ModeOption(),
+			},
+		},
+		{
+			Name:        "sf6_compare",
+			Description: "Compare two fighters against their common opponents.",
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "a",
+					Description: "First SF6 user code (sid) or @mention",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "b",
+					Description: "Second SF6 user code (sid) or @mention",
+					Required:    true,
+				},
+				sf6ModeOption(),
+			},
+		},
+		{
+			Name:        "sf6_rating",
+			Description: "Show guild-wide SF6 Elo ratings.",
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "leaderboard",
+					Description: "Show the rating leaderboard.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "character",
+							Description: "Character (e.g. Chun-Li). Omit for all characters",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "history",
+					Description: "Show rating history.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "code",
+							Description: "SF6 user code (sid) or @mention (default: you)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "character",
+							Description: "Character (e.g. Chun-Li). Omit for all characters",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "count",
+							Description: "Number of recent matches (1-50, default 20)",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:        "anon",
 			Description: "Post anonymously in this channel.",
@@ -276,6 +519,31 @@ func Commands() []*discordgo.ApplicationCommand {
 				},
 			},
 		},
+		// コンテキストメニュー（名前は HandleInteraction の case と揃える。説明は付けられない）
+		{
+			Name: sf6RecordVsUserMenu,
+			Type: discordgo.UserApplicationCommand,
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+		},
+		{
+			Name: sf6ProfileCardMenu,
+			Type: discordgo.UserApplicationCommand,
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+		},
+		{
+			Name: sf6RefreshStatsMenu,
+			Type: discordgo.MessageApplicationCommand,
+			DMPermission: func() *bool {
+				v := false
+				return &v
+			}(),
+		},
 		// ここに今後 /tournament /beat /cypher を足していく:
 		// {
 		// 	Name:        "tournament",
@@ -291,3 +559,20 @@ func Commands() []*discordgo.ApplicationCommand {
 		// },
 	}
 }
+
+// sf6ModeOption は stats/history で共通の対戦種別（mode）オプション。
+func sf6ModeOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "mode",
+		Description: "Battle mode filter (default: all)",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "all", Value: "all"},
+			{Name: "rank", Value: "rank"},
+			{Name: "casual", Value: "casual"},
+			{Name: "hub", Value: "hub"},
+			{Name: "custom", Value: "custom"},
+		},
+	}
+}
@@ -31,11 +31,12 @@ func RespondEphemeralEmbed(s *discordgo.Session, i *discordgo.InteractionCreate,
 	})
 }
 
-func FollowupPublicEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+func FollowupPublicEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent, files ...*discordgo.File) {
 	_, _ = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
 		Content:    content,
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
+		Files:      files,
 	})
 }
 
@@ -118,7 +119,7 @@ func NotifyCommandTimeout(s *discordgo.Session, i *discordgo.InteractionCreate)
 	}
 }
 
-func EditInteractionResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
+func EditInteractionResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent, files ...*discordgo.File) error {
 	if s == nil || i == nil {
 		return fmt.Errorf("interaction not available")
 	}
@@ -133,6 +134,7 @@ func EditInteractionResponse(s *discordgo.Session, i *discordgo.InteractionCreat
 	if components != nil {
 		edit.Components = &components
 	}
+	edit.Files = files
 	_, err := s.InteractionResponseEdit(i.Interaction, edit)
 	return err
 }
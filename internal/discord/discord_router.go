@@ -24,18 +24,32 @@ func NewRouter(
 	sf6FriendService service.SF6FriendService,
 	sf6Service service.SF6Service,
 	sf6SessionService service.SF6SessionService,
+	sf6BackfillService service.SF6BackfillService,
+	sf6FetchRunService service.SF6FetchRunService,
+	sf6FeedService service.SF6FeedService,
+	sf6RatingService service.SF6RatingService,
 	// tournamentService service.TournamentService,
 	// cypherService service.CypherService,
 	// beatService service.BeatService,
 ) *Router {
 	return &Router{
 		anonymous: anonymous.NewHandler(anonymousChannelService),
-		sf6:       sf6.NewHandler(sf6AccountService, sf6FriendService, sf6Service, sf6SessionService),
+		sf6:       sf6.NewHandler(sf6AccountService, sf6FriendService, sf6Service, sf6SessionService, sf6BackfillService, sf6FetchRunService, sf6FeedService, sf6RatingService),
 		// TournamentService: tournamentService,
 		// CypherService:     cypherService,
 		// BeatService:       beatService,
 	}
 }
+
+// SF6SessionNotifier はセッション監視用のスコアボード更新を返す。
+func (r *Router) SF6SessionNotifier(s *discordgo.Session) service.SF6SessionNotifier {
+	return r.sf6.SessionScoreboardNotifier(s)
+}
+
+// SF6FeedNotifier は新着対戦の投稿用 notifier を返す。
+func (r *Router) SF6FeedNotifier(s *discordgo.Session) service.SF6FeedNotifier {
+	return r.sf6.FeedNotifier(s)
+}
 
 // HandleInteraction は discordgo のイベントハンドラとして登録される入口。
 // main.go 側で session.AddHandler(router.HandleInteraction) する想定。
@@ -65,6 +79,21 @@ func (r *Router) HandleInteraction(s *discordgo.Session, i *discordgo.Interactio
 			r.sf6.HandleSession(s, i)
 		case "sf6_friend":
 			r.sf6.HandleFriend(s, i)
+		case "sf6_feed":
+			r.sf6.HandleFeed(s, i)
+		case "sf6_rating":
+			r.sf6.HandleRating(s, i)
+		case "sf6_leaderboard":
+			r.sf6.HandleLeaderboard(s, i)
+		case "sf6_compare":
+			r.sf6.HandleCompare(s, i)
+		// コンテキストメニュー（ユーザー / メッセージの右クリック）
+		case sf6RecordVsUserMenu:
+			r.sf6.HandleRecordVsUser(s, i)
+		case sf6ProfileCardMenu:
+			r.sf6.HandleProfileCard(s, i)
+		case sf6RefreshStatsMenu:
+			r.sf6.HandleRefreshStats(s, i)
 
 		// 将来的な拡張 (コメントアウトしておいてOK)
 		// case "tournament":
@@ -77,6 +106,8 @@ func (r *Router) HandleInteraction(s *discordgo.Session, i *discordgo.Interactio
 			// 未対応コマンドはとりあえず無視 or ログに出すくらいでOK
 			return
 		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		r.sf6.HandleAutocomplete(s, i)
 	case discordgo.InteractionMessageComponent:
 		r.sf6.HandleComponent(s, i)
 	case discordgo.InteractionModalSubmit:
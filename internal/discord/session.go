@@ -13,6 +13,8 @@ type Session interface {
 	Close() error
 	AddHandler(handler any)
 	RegisterCommands(ctx context.Context, appID, guildID string) error
+	// Discordgo は REST 呼び出し（メッセージ編集など）用に内部の discordgo.Session を返す。
+	Discordgo() *discordgo.Session
 }
 
 type session struct {
@@ -73,6 +75,10 @@ func (s *session) AddHandler(handler any) {
 	s.dg.AddHandler(handler)
 }
 
+func (s *session) Discordgo() *discordgo.Session {
+	return s.dg
+}
+
 func (s *session) RegisterCommands(ctx context.Context, appID, guildID string) error {
 	if appID == "" {
 		return fmt.Errorf("discord app id is empty")
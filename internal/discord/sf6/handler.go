@@ -7,10 +7,14 @@ import (
 )
 
 type Handler struct {
-	SF6AccountService service.SF6AccountService
-	SF6FriendService  service.SF6FriendService
-	SF6Service        service.SF6Service
-	SF6SessionService service.SF6SessionService
+	SF6AccountService  service.SF6AccountService
+	SF6FriendService   service.SF6FriendService
+	SF6Service         service.SF6Service
+	SF6SessionService  service.SF6SessionService
+	SF6BackfillService service.SF6BackfillService
+	SF6FetchRunService service.SF6FetchRunService
+	SF6FeedService     service.SF6FeedService
+	SF6RatingService   service.SF6RatingService
 }
 
 func NewHandler(
@@ -18,12 +22,20 @@ func NewHandler(
 	sf6FriendService service.SF6FriendService,
 	sf6Service service.SF6Service,
 	sf6SessionService service.SF6SessionService,
+	sf6BackfillService service.SF6BackfillService,
+	sf6FetchRunService service.SF6FetchRunService,
+	sf6FeedService service.SF6FeedService,
+	sf6RatingService service.SF6RatingService,
 ) *Handler {
 	return &Handler{
-		SF6AccountService: sf6AccountService,
-		SF6FriendService:  sf6FriendService,
-		SF6Service:        sf6Service,
-		SF6SessionService: sf6SessionService,
+		SF6AccountService:  sf6AccountService,
+		SF6FriendService:   sf6FriendService,
+		SF6Service:         sf6Service,
+		SF6SessionService:  sf6SessionService,
+		SF6BackfillService: sf6BackfillService,
+		SF6FetchRunService: sf6FetchRunService,
+		SF6FeedService:     sf6FeedService,
+		SF6RatingService:   sf6RatingService,
 	}
 }
 
@@ -55,6 +67,38 @@ func (h *Handler) HandleFriend(s *discordgo.Session, i *discordgo.InteractionCre
 	h.handleSF6Friend(s, i)
 }
 
+func (h *Handler) HandleFeed(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6Feed(s, i)
+}
+
+func (h *Handler) HandleRating(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6Rating(s, i)
+}
+
+func (h *Handler) HandleLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6Leaderboard(s, i)
+}
+
+func (h *Handler) HandleCompare(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6Compare(s, i)
+}
+
+func (h *Handler) HandleRecordVsUser(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6RecordVsUser(s, i)
+}
+
+func (h *Handler) HandleProfileCard(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6ProfileCard(s, i)
+}
+
+func (h *Handler) HandleRefreshStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6RefreshStats(s, i)
+}
+
+func (h *Handler) HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	h.handleSF6Autocomplete(s, i)
+}
+
 func (h *Handler) HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	h.handleSF6Component(s, i)
 }
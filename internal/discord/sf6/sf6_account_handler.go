@@ -6,9 +6,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"backend/internal/buckler"
 	"backend/internal/discord/common"
 	"backend/internal/domain"
+	"backend/internal/service"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -176,6 +179,10 @@ func (r *Handler) handleSF6Component(s *discordgo.Session, i *discordgo.Interact
 		r.handleSF6HistoryComponent(s, i, data.CustomID)
 	case strings.HasPrefix(data.CustomID, "sf6_stats_set_page"):
 		r.handleSF6StatsSetComponent(s, i, data.CustomID)
+	case strings.HasPrefix(data.CustomID, "sf6_session_list"):
+		r.handleSF6SessionListComponent(s, i, data.CustomID)
+	case strings.HasPrefix(data.CustomID, "sf6_leaderboard_page"):
+		r.handleSF6LeaderboardComponent(s, i, data.CustomID)
 	}
 }
 
@@ -227,9 +234,9 @@ func (r *Handler) handleSF6ModalSubmit(s *discordgo.Session, i *discordgo.Intera
 			common.FollowupEphemeral(s, i, "登録に失敗: "+err.Error())
 			return
 		}
-		totalSaved, pagesFetched, err := r.initialFetch(ctx, i.GuildID, userID, userCode)
+		totalSaved, pagesFetched, err := r.initialFetch(ctx, domain.SF6FetchTriggerManual, i.GuildID, userID, userCode)
 		if err != nil {
-			common.FollowupEphemeral(s, i, "登録完了。移し替え件数: "+strconv.FormatInt(updated, 10)+" / 初回取得に失敗: "+err.Error())
+			common.FollowupEphemeral(s, i, "登録完了。移し替え件数: "+strconv.FormatInt(updated, 10)+" / 初回取得に失敗: "+formatSF6FetchError(err))
 			return
 		}
 		accountEmbed, linked, err := r.buildAccountEmbed(ctx, "SF6 Account", i.GuildID, userID, user, totalSaved, pagesFetched, updated)
@@ -249,7 +256,17 @@ func (r *Handler) handleSF6ModalSubmit(s *discordgo.Session, i *discordgo.Intera
 	}
 }
 
-func (r *Handler) initialFetch(ctx context.Context, guildID, userID, userCode string) (int, int, error) {
+// sf6BattlelogKinds は SF6_BATTLELOG_KINDS の対戦種別を返す。不正な値なら custom のみとする。
+func sf6BattlelogKinds() []buckler.BattlelogKind {
+	kinds, err := buckler.ParseBattlelogKinds(os.Getenv("SF6_BATTLELOG_KINDS"))
+	if err != nil {
+		return []buckler.BattlelogKind{buckler.BattlelogCustom}
+	}
+	return kinds
+}
+
+// initialFetch は userCode の直近ページを種別ごとに取得し、取得履歴を trigger 付きで記録する。
+func (r *Handler) initialFetch(ctx context.Context, trigger, guildID, userID, userCode string) (int, int, error) {
 	if r.SF6Service == nil {
 		return 0, 0, nil
 	}
@@ -259,17 +276,31 @@ func (r *Handler) initialFetch(ctx context.Context, guildID, userID, userCode st
 			maxPages = n
 		}
 	}
+	started := time.Now()
+	totalSaved, pagesFetched, err := r.fetchRecentPages(ctx, guildID, userID, userCode, sf6BattlelogKinds(), maxPages)
+	if r.SF6FetchRunService != nil {
+		run := service.NewSF6FetchRun(trigger, guildID, userCode, 1, pagesFetched, totalSaved, started, err)
+		if rerr := r.SF6FetchRunService.Record(ctx, run); rerr != nil {
+			common.Logf("[sf6][fetch] record run failed: guild=%s sid=%s err=%v", guildID, userCode, rerr)
+		}
+	}
+	return totalSaved, pagesFetched, err
+}
+
+func (r *Handler) fetchRecentPages(ctx context.Context, guildID, userID, userCode string, kinds []buckler.BattlelogKind, maxPages int) (int, int, error) {
 	totalSaved := 0
 	pagesFetched := 0
-	for p := 1; p <= maxPages; p++ {
-		count, allExisting, err := r.SF6Service.FetchAndStoreCustomBattles(ctx, guildID, userID, userCode, p)
-		if err != nil {
-			return totalSaved, pagesFetched, err
-		}
-		pagesFetched++
-		totalSaved += count
-		if allExisting {
-			break
+	for _, kind := range kinds {
+		for p := 1; p <= maxPages; p++ {
+			count, allExisting, err := r.SF6Service.FetchAndStoreBattles(ctx, guildID, userID, userCode, kind, p)
+			if err != nil {
+				return totalSaved, pagesFetched, err
+			}
+			pagesFetched++
+			totalSaved += count
+			if allExisting {
+				break
+			}
 		}
 	}
 	return totalSaved, pagesFetched, nil
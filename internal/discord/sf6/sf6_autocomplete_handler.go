@@ -0,0 +1,81 @@
+package sf6
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord の autocomplete は 3 秒以内に応答しないと失敗扱いになる。
+const sf6AutocompleteTimeout = 2 * time.Second
+
+// Discord が表示できる候補は 25 件まで。
+const sf6AutocompleteMaxChoices = 25
+
+// Choice の name / value は 100 文字まで。
+const sf6AutocompleteMaxChoiceLen = 100
+
+func (r *Handler) handleSF6Autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	focused := focusedOption(i.ApplicationCommandData().Options)
+	if focused == nil || focused.Name != "opponent_code" {
+		return
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	userID := common.InteractionUserID(i)
+	if i.GuildID != "" && userID != "" && r.SF6FriendService != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sf6AutocompleteTimeout)
+		defer cancel()
+		suggestions, err := r.SF6FriendService.SuggestOpponents(ctx, i.GuildID, userID, focused.StringValue(), sf6AutocompleteMaxChoices)
+		if err != nil {
+			common.Logf("[sf6][autocomplete] suggest failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+		}
+		for _, suggestion := range suggestions {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  formatSF6OpponentChoice(suggestion),
+				Value: suggestion.FighterID,
+			})
+		}
+	}
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}); err != nil {
+		common.Logf("[sf6][autocomplete] respond failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+	}
+}
+
+// focusedOption はサブコマンドの中も含めて入力中のオプションを探す。
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if found := focusedOption(opt.Options); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func formatSF6OpponentChoice(suggestion domain.SF6OpponentSuggestion) string {
+	source := "最近の対戦相手"
+	switch suggestion.Source {
+	case domain.SF6OpponentSourceFriend:
+		source = "フレンド"
+	case domain.SF6OpponentSourceMember:
+		source = "メンバー"
+	}
+	label := fmt.Sprintf("%s • %s", suggestion.FighterID, source)
+	if suggestion.Name != "" {
+		label = fmt.Sprintf("%s (%s) • %s", suggestion.Name, suggestion.FighterID, source)
+	}
+	if runes := []rune(label); len(runes) > sf6AutocompleteMaxChoiceLen {
+		label = string(runes[:sf6AutocompleteMaxChoiceLen-1]) + "…"
+	}
+	return label
+}
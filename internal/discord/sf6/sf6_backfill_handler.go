@@ -0,0 +1,145 @@
+package sf6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Interaction の応答を編集できるのは 15 分までなので、その手前で打ち切る（続きは再実行で再開できる）。
+const sf6BackfillCommandTimeout = 14 * time.Minute
+
+// 進捗メッセージを編集する最短間隔。
+const sf6BackfillProgressInterval = 3 * time.Second
+
+func (r *Handler) handleSF6FetchBackfill(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	if r.SF6BackfillService == nil {
+		common.FollowupEphemeral(s, i, "バックフィルは無効です")
+		return
+	}
+	var subjectCode string
+	restart := false
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "subject_code":
+			subjectCode = strings.TrimSpace(opt.StringValue())
+		case "restart":
+			restart = opt.BoolValue()
+		}
+	}
+	if subjectCode == "" {
+		common.FollowupEphemeral(s, i, "subject_code が必要です")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sf6BackfillCommandTimeout)
+	defer cancel()
+	if sid, _, ok, err := r.resolveSIDFromMention(ctx, i.GuildID, subjectCode); ok {
+		if err != nil {
+			common.FollowupEphemeral(s, i, err.Error())
+			return
+		}
+		subjectCode = sid
+	}
+
+	progress := newSF6BackfillProgress(subjectCode)
+	if err := common.EditInteractionResponse(s, i, progress.String(), nil, nil); err != nil {
+		common.Logf("[sf6][backfill] edit response failed: sid=%s err=%v", subjectCode, err)
+	}
+	_, err := r.SF6BackfillService.Run(ctx, i.GuildID, userID, subjectCode, sf6BattlelogKinds(), restart, func(job domain.SF6BackfillJob) {
+		if progress.update(job) {
+			_ = common.EditInteractionResponse(s, i, progress.String(), nil, nil)
+		}
+	})
+
+	msg := progress.String()
+	switch {
+	case err == nil:
+		msg += "\nバックフィル完了"
+	case errors.Is(err, service.ErrSF6BackfillRunning):
+		msg += "\n同じ sid のバックフィルが実行中です"
+	case ctx.Err() != nil:
+		msg += "\n時間切れで中断しました。もう一度実行すると続きから取得します"
+	default:
+		msg += "\n中断しました: " + formatSF6FetchError(err) + "\nもう一度実行すると続きから取得します"
+	}
+	if err := common.EditInteractionResponse(s, i, msg, nil, nil); err != nil {
+		common.FollowupEphemeral(s, i, msg)
+	}
+}
+
+// sf6BackfillProgress は対戦種別ごとの進捗を保持し、メッセージの編集頻度を抑える。
+type sf6BackfillProgress struct {
+	mu       sync.Mutex
+	sid      string
+	jobs     []domain.SF6BackfillJob
+	editedAt time.Time
+}
+
+func newSF6BackfillProgress(sid string) *sf6BackfillProgress {
+	return &sf6BackfillProgress{sid: sid}
+}
+
+// update は進捗を記録し、メッセージを編集すべきなら true を返す。
+func (p *sf6BackfillProgress) update(job domain.SF6BackfillJob) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	replaced := false
+	for idx := range p.jobs {
+		if p.jobs[idx].BattleType == job.BattleType {
+			p.jobs[idx] = job
+			replaced = true
+		}
+	}
+	if !replaced {
+		p.jobs = append(p.jobs, job)
+	}
+	if job.Status != domain.SF6BackfillRunning || time.Since(p.editedAt) >= sf6BackfillProgressInterval {
+		p.editedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+func (p *sf6BackfillProgress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "バックフィル: sid=%s", p.sid)
+	if len(p.jobs) == 0 {
+		b.WriteString("\n開始しています…")
+	}
+	for _, job := range p.jobs {
+		fetched := job.NextPage - 1
+		total := "?"
+		if job.TotalPage > 0 {
+			total = fmt.Sprintf("%d", job.TotalPage)
+		}
+		fmt.Fprintf(&b, "\n- %s: %d/%s ページ 保存=%d [%s]", job.BattleType, fetched, total, job.SavedCount, sf6BackfillStatusLabel(job.Status))
+	}
+	return b.String()
+}
+
+func sf6BackfillStatusLabel(status string) string {
+	switch status {
+	case domain.SF6BackfillRunning:
+		return "取得中"
+	case domain.SF6BackfillPaused:
+		return "中断"
+	case domain.SF6BackfillDone:
+		return "完了"
+	case domain.SF6BackfillFailed:
+		return "失敗"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,147 @@
+package sf6
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// description は 4096 文字までなので、並べる共通の相手を絞る。
+const sf6CompareMaxRows = 15
+
+func (r *Handler) handleSF6Compare(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6Service == nil || r.SF6AccountService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+	var codeA, codeB, battleType string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "a":
+			codeA = strings.TrimSpace(opt.StringValue())
+		case "b":
+			codeB = strings.TrimSpace(opt.StringValue())
+		case "mode":
+			battleType = parseSF6Mode(opt.StringValue())
+		}
+	}
+	if codeA == "" || codeB == "" {
+		common.RespondEphemeral(s, i, "a/b が必要です")
+		return
+	}
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	sids := []string{codeA, codeB}
+	for idx, code := range sids {
+		if sid, _, ok, err := r.resolveSIDFromMention(ctx, i.GuildID, code); ok {
+			if err != nil {
+				common.FollowupEphemeral(s, i, err.Error())
+				return
+			}
+			sids[idx] = sid
+		}
+	}
+	if sids[0] == sids[1] {
+		common.FollowupEphemeral(s, i, "a と b には別の fighter を指定してください")
+		return
+	}
+	for _, sid := range sids {
+		if err := r.fetchLatestForStats(ctx, i.GuildID, userID, sid); err != nil {
+			common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
+			return
+		}
+	}
+	cmp, err := r.SF6Service.Compare(ctx, i.GuildID, sids[0], sids[1], battleType)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	userA, userB := r.buildStatsEmbedUsers(ctx, s, i.GuildID, sids[0], sids[1])
+	embed := buildSF6CompareEmbed(userA, userB, cmp, r.fighterNames(ctx, i.GuildID), battleType)
+	common.FollowupPublicEmbed(s, i, "", embed, nil)
+}
+
+func buildSF6CompareEmbed(userA, userB statsEmbedUser, cmp *domain.SF6Comparison, names map[string]string, battleType string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: "SF6 Compare",
+		Color: 0x2b6cb0,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:  "Players",
+				Value: fmt.Sprintf("A: %s\nB: %s", formatStatsUserLine(userA), formatStatsUserLine(userB)),
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("共通の相手 %d 人 • Mode %s", len(cmp.Rows), formatSF6ModeLabel(battleType)),
+		},
+	}
+	if len(cmp.Rows) == 0 {
+		embed.Description = "共通の対戦相手がいません"
+		return embed
+	}
+
+	lines := make([]string, 0, min(len(cmp.Rows), sf6CompareMaxRows)+1)
+	for idx, row := range cmp.Rows {
+		if idx >= sf6CompareMaxRows {
+			lines = append(lines, fmt.Sprintf("…ほか %d 人", len(cmp.Rows)-idx))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s\nA %s • B %s %s",
+			sf6FetchFighterLabel(row.OpponentFighterID, names),
+			formatSF6OpponentRecord(row.A), formatSF6OpponentRecord(row.B), sf6CompareMark(row.Leader)))
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	verdict := "互角"
+	switch service.SF6ComparisonLeader(cmp) {
+	case 1:
+		verdict = "**A** の方が好成績"
+	case -1:
+		verdict = "**B** の方が好成績"
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name: "Same Field",
+		Value: fmt.Sprintf("A %s\nB %s\n相手ごと: A 優勢 %d / B 優勢 %d / 互角 %d\n→ %s",
+			formatSF6OpponentRecord(cmp.TotalA), formatSF6OpponentRecord(cmp.TotalB),
+			cmp.BetterA, cmp.BetterB, cmp.Even, verdict),
+	})
+	return embed
+}
+
+func formatSF6OpponentRecord(record domain.SF6OpponentRecord) string {
+	stat := statsTotals{Total: record.Games, Wins: record.Wins, Losses: record.Losses, Draws: record.Draws}
+	if record.Draws > 0 {
+		return fmt.Sprintf("%d-%d-%d (%s)", record.Wins, record.Losses, record.Draws, calcWinRate(stat))
+	}
+	return fmt.Sprintf("%d-%d (%s)", record.Wins, record.Losses, calcWinRate(stat))
+}
+
+func sf6CompareMark(leader int) string {
+	switch leader {
+	case 1:
+		return "◀ A"
+	case -1:
+		return "◀ B"
+	default:
+		return "＝"
+	}
+}
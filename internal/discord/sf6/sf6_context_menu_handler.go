@@ -0,0 +1,256 @@
+package sf6
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"backend/internal/discord/common"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// 「このユーザーとの戦績」で集計する直近の試合数。
+const sf6ContextMenuStatsCount = 100
+
+// Players 欄の各行の末尾にある `sid` を拾う。
+var sf6StatsEmbedSIDPattern = regexp.MustCompile("`([^`]+)`\\)?$")
+
+// sf6StatsEmbedQuery は統計 embed を作り直すための条件。
+type sf6StatsEmbedQuery struct {
+	Kind        string // range / count / set
+	SubjectSID  string
+	OpponentSID string
+	BattleType  string
+	From, To    string
+	Count       int
+	Page        int
+}
+
+// handleSF6RecordVsUser はユーザーのコンテキストメニューから、実行者とそのユーザーの直近の戦績を表示する。
+func (r *Handler) handleSF6RecordVsUser(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6Service == nil || r.SF6AccountService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+	targetID := i.ApplicationCommandData().TargetID
+	if targetID == "" {
+		common.RespondEphemeral(s, i, "対象ユーザーの取得に失敗")
+		return
+	}
+	if targetID == userID {
+		common.RespondEphemeral(s, i, "自分以外のユーザーを選んでください")
+		return
+	}
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	subjectSID, err := r.resolveSubjectSID(ctx, i.GuildID, userID, "")
+	if err != nil {
+		common.FollowupEphemeral(s, i, err.Error())
+		return
+	}
+	target, err := r.SF6AccountService.GetByUser(ctx, i.GuildID, targetID)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "連携状態の取得に失敗: "+err.Error())
+		return
+	}
+	if target == nil {
+		common.FollowupEphemeral(s, i, "<@"+targetID+"> はSF6連携していません")
+		return
+	}
+	if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
+		common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
+		return
+	}
+	embed, files, err := r.buildSF6StatsCountEmbed(ctx, s, i.GuildID, subjectSID, target.FighterID, "", sf6ContextMenuStatsCount)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	common.FollowupPublicEmbed(s, i, "", embed, nil, files...)
+}
+
+// handleSF6ProfileCard はユーザーのコンテキストメニューから、そのユーザーの連携状況とプロフィールを表示する。
+func (r *Handler) handleSF6ProfileCard(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6AccountService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	data := i.ApplicationCommandData()
+	targetID := data.TargetID
+	if targetID == "" {
+		common.RespondEphemeral(s, i, "対象ユーザーの取得に失敗")
+		return
+	}
+	var target *discordgo.User
+	if data.Resolved != nil {
+		target = data.Resolved.Users[targetID]
+	}
+	if err := common.DeferEphemeral(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	embed, _, err := r.buildAccountEmbed(ctx, "SF6 Profile", i.GuildID, targetID, target, 0, 0, 0)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "状態取得に失敗: "+err.Error())
+		return
+	}
+	// 連携/解除ボタンは付けないので、パネルの説明は出さない
+	embed.Description = ""
+	common.FollowupEphemeralEmbed(s, i, embed, nil)
+}
+
+// handleSF6RefreshStats はメッセージのコンテキストメニューから、統計 embed を最新の対戦で作り直して新しいメッセージで返す。
+// 元のメッセージは他人の実行結果のこともあるので編集しない。
+func (r *Handler) handleSF6RefreshStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6Service == nil || r.SF6AccountService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+	data := i.ApplicationCommandData()
+	var msg *discordgo.Message
+	if data.Resolved != nil {
+		msg = data.Resolved.Messages[data.TargetID]
+	}
+	if msg == nil || msg.Author == nil || s.State == nil || s.State.User == nil || msg.Author.ID != s.State.User.ID {
+		common.RespondEphemeral(s, i, "このbotの統計メッセージを選んでください")
+		return
+	}
+	query, ok := parseSF6StatsEmbedQuery(msg)
+	if !ok {
+		common.RespondEphemeral(s, i, "更新できるのは /sf6_stats の range / count / set の結果だけです")
+		return
+	}
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	if err := r.fetchLatestForStats(ctx, i.GuildID, userID, query.SubjectSID); err != nil {
+		common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
+		return
+	}
+	var (
+		embed      *discordgo.MessageEmbed
+		components []discordgo.MessageComponent
+		files      []*discordgo.File
+		err        error
+	)
+	switch query.Kind {
+	case "range":
+		embed, files, err = r.buildSF6StatsRangeEmbed(ctx, s, i.GuildID, query.SubjectSID, query.OpponentSID, query.BattleType, query.From, query.To)
+	case "count":
+		embed, files, err = r.buildSF6StatsCountEmbed(ctx, s, i.GuildID, query.SubjectSID, query.OpponentSID, query.BattleType, query.Count)
+	case "set":
+		// 新しいメッセージのページ操作は実行者のものにする
+		embed, components, files, err = r.buildSF6StatsSetEmbed(ctx, s, i.GuildID, userID, query.SubjectSID, query.OpponentSID, query.BattleType, query.Page)
+	}
+	if err != nil {
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	common.FollowupPublicEmbed(s, i, "", embed, components, files...)
+}
+
+// parseSF6StatsEmbedQuery は buildStatsEmbed で作った embed の表示から、作り直すための条件を読み取る。
+func parseSF6StatsEmbedQuery(msg *discordgo.Message) (sf6StatsEmbedQuery, bool) {
+	var query sf6StatsEmbedQuery
+	if msg == nil || len(msg.Embeds) == 0 || msg.Embeds[0] == nil {
+		return query, false
+	}
+	embed := msg.Embeds[0]
+	for _, field := range embed.Fields {
+		if field == nil || field.Name != "Players" {
+			continue
+		}
+		for _, line := range strings.Split(field.Value, "\n") {
+			name, value, ok := strings.Cut(line, ": ")
+			if !ok {
+				continue
+			}
+			match := sf6StatsEmbedSIDPattern.FindStringSubmatch(strings.TrimSpace(value))
+			if match == nil {
+				continue
+			}
+			switch name {
+			case "subject":
+				query.SubjectSID = match[1]
+			case "opponent":
+				query.OpponentSID = match[1]
+			}
+		}
+	}
+	if query.SubjectSID == "" || query.OpponentSID == "" {
+		return query, false
+	}
+	query.BattleType = parseSF6ModeLabel(embed.Description)
+	label, _, _ := strings.Cut(embed.Description, " / モード: ")
+
+	switch embed.Title {
+	case sf6StatsRangeTitle:
+		period, ok := strings.CutPrefix(label, "期間: ")
+		if !ok {
+			return query, false
+		}
+		period, ok = strings.CutSuffix(period, " (JST)")
+		if !ok {
+			return query, false
+		}
+		query.From, query.To, ok = strings.Cut(period, "〜")
+		if !ok {
+			return query, false
+		}
+		if _, _, err := parseDateRangeJST(query.From, query.To); err != nil {
+			return query, false
+		}
+		query.Kind = "range"
+	case sf6StatsCountTitle:
+		if _, err := fmt.Sscanf(label, "直近 %d 戦", &query.Count); err != nil || query.Count <= 0 {
+			return query, false
+		}
+		query.Kind = "count"
+	case sf6StatsSetTitle:
+		if embed.Footer == nil {
+			return query, false
+		}
+		if _, err := fmt.Sscanf(embed.Footer.Text, "Set %d/", &query.Page); err != nil || query.Page <= 0 {
+			return query, false
+		}
+		query.Kind = "set"
+	default:
+		return query, false
+	}
+	return query, true
+}
@@ -0,0 +1,130 @@
+package sf6
+
+import (
+	"encoding/json"
+	"testing"
+
+	"backend/internal/buckler"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// roundTripSF6Message は送信した embed を、Discord から読み戻したメッセージの形にする。
+func roundTripSF6Message(t *testing.T, embed *discordgo.MessageEmbed) *discordgo.Message {
+	t.Helper()
+	raw, err := json.Marshal(&discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var out discordgo.Message
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return &out
+}
+
+func TestParseSF6StatsEmbedQueryRoundTrip(t *testing.T) {
+	modes := []string{""}
+	for _, kind := range buckler.BattlelogKinds() {
+		modes = append(modes, string(kind))
+	}
+	users := []struct {
+		name              string
+		subject, opponent statsEmbedUser
+	}{
+		{
+			name:     "linked with names",
+			subject:  statsEmbedUser{SID: "1000000001", UserID: "u1", Mention: "<@u1>", DisplayName: "Ryu: Main"},
+			opponent: statsEmbedUser{SID: "1000000002", UserID: "u2", Mention: "<@u2>", DisplayName: "Ken `2`"},
+		},
+		{
+			name:     "linked without names",
+			subject:  statsEmbedUser{SID: "1000000001", UserID: "u1", Mention: "<@u1>"},
+			opponent: statsEmbedUser{SID: "1000000002", UserID: "u2", Mention: "<@u2>"},
+		},
+		{
+			name:     "unlinked",
+			subject:  statsEmbedUser{SID: "1000000001", DisplayName: "Ryu"},
+			opponent: statsEmbedUser{SID: "1000000002"},
+		},
+	}
+	type embedCase struct {
+		name  string
+		want  sf6StatsEmbedQuery
+		build func(subject, opponent statsEmbedUser, battleType string) *discordgo.MessageEmbed
+	}
+	kinds := []embedCase{
+		{
+			name: "range",
+			want: sf6StatsEmbedQuery{Kind: "range", From: "2026-10-01", To: "2026-10-03"},
+			build: func(subject, opponent statsEmbedUser, battleType string) *discordgo.MessageEmbed {
+				return buildStatsEmbed(sf6StatsRangeTitle, sf6StatsRangeLabel("2026-10-01", "2026-10-03", battleType), subject, opponent, nil)
+			},
+		},
+		{
+			name: "range with time",
+			want: sf6StatsEmbedQuery{Kind: "range", From: "2026-10-01 21:00", To: "2026-10-2 03:30"},
+			build: func(subject, opponent statsEmbedUser, battleType string) *discordgo.MessageEmbed {
+				return buildStatsEmbed(sf6StatsRangeTitle, sf6StatsRangeLabel("2026-10-01 21:00", "2026-10-2 03:30", battleType), subject, opponent, nil)
+			},
+		},
+		{
+			name: "count",
+			want: sf6StatsEmbedQuery{Kind: "count", Count: 100},
+			build: func(subject, opponent statsEmbedUser, battleType string) *discordgo.MessageEmbed {
+				return buildStatsEmbed(sf6StatsCountTitle, sf6StatsCountLabel(100, battleType), subject, opponent, nil)
+			},
+		},
+		{
+			name: "set",
+			want: sf6StatsEmbedQuery{Kind: "set", Page: 2},
+			build: func(subject, opponent statsEmbedUser, battleType string) *discordgo.MessageEmbed {
+				embed := buildStatsEmbed(sf6StatsSetTitle, appendSF6ModeLabel("期間: 2026/10/01 21:00〜2026/10/01 22:10 (JST) / 12戦", battleType), subject, opponent, nil)
+				embed.Footer = sf6StatsSetFooter(2, 3)
+				return embed
+			},
+		},
+	}
+
+	for _, kind := range kinds {
+		for _, mode := range modes {
+			for _, user := range users {
+				name := kind.name + "/" + formatSF6ModeLabel(mode) + "/" + user.name
+				t.Run(name, func(t *testing.T) {
+					embed := kind.build(user.subject, user.opponent, mode)
+					got, ok := parseSF6StatsEmbedQuery(roundTripSF6Message(t, embed))
+					if !ok {
+						t.Fatalf("parseSF6StatsEmbedQuery() ok = false, description = %q", embed.Description)
+					}
+					want := kind.want
+					want.SubjectSID, want.OpponentSID, want.BattleType = "1000000001", "1000000002", mode
+					if got != want {
+						t.Fatalf("parseSF6StatsEmbedQuery() = %+v, want %+v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestParseSF6StatsEmbedQueryRejects(t *testing.T) {
+	subject := statsEmbedUser{SID: "1000000001"}
+	opponent := statsEmbedUser{SID: "1000000002"}
+	tests := []struct {
+		name  string
+		embed *discordgo.MessageEmbed
+	}{
+		{"session", buildStatsEmbed("SF6 Stats (Session)", "期間: 2026/10/01 21:00〜 (JST)", subject, opponent, nil)},
+		{"invalid range", buildStatsEmbed(sf6StatsRangeTitle, sf6StatsRangeLabel("2026-10-03", "2026-10-01", ""), subject, opponent, nil)},
+		{"zero count", buildStatsEmbed(sf6StatsCountTitle, sf6StatsCountLabel(0, ""), subject, opponent, nil)},
+		{"unknown opponent", buildStatsEmbed(sf6StatsCountTitle, sf6StatsCountLabel(10, ""), subject, statsEmbedUser{}, nil)},
+		{"set without footer", buildStatsEmbed(sf6StatsSetTitle, "期間: 2026/10/01 21:00〜2026/10/01 22:10 (JST) / 12戦", subject, opponent, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := parseSF6StatsEmbedQuery(roundTripSF6Message(t, tt.embed)); ok {
+				t.Fatalf("parseSF6StatsEmbedQuery() = %+v, want rejected", got)
+			}
+		})
+	}
+}
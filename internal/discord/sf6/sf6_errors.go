@@ -0,0 +1,35 @@
+package sf6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/buckler"
+)
+
+// formatSF6FetchError は Buckler 取得エラーをユーザー向けの文言に変換する。該当しない場合は元のエラーを返す。
+func formatSF6FetchError(err error) string {
+	if err == nil {
+		return ""
+	}
+	switch {
+	case errors.Is(err, buckler.ErrNotAuthenticated):
+		return "Buckler にログインできませんでした。時間をおいて再実行してください（続く場合は管理者に連絡してください）"
+	case errors.Is(err, buckler.ErrRateLimited):
+		if wait, ok := buckler.RetryAfter(err); ok {
+			return fmt.Sprintf("Buckler へのアクセスが制限されています。%s ほど待ってから再実行してください", wait.Round(time.Second))
+		}
+		return "Buckler へのアクセスが制限されています。しばらく待ってから再実行してください"
+	case errors.Is(err, buckler.ErrNotFound):
+		return "プロフィールが見つかりません（ユーザーコードの誤り、または非公開設定の可能性があります）"
+	case errors.Is(err, buckler.ErrMaintenance):
+		return "Buckler はメンテナンス中です。終了後に再実行してください"
+	case errors.Is(err, buckler.ErrBuildIDDrift), errors.Is(err, buckler.ErrSchemaChanged):
+		return "Buckler 側の仕様変更により取得できませんでした。管理者に連絡してください"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Buckler の応答がタイムアウトしました。時間をおいて再実行してください"
+	}
+	return err.Error()
+}
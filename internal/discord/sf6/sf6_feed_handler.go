@@ -0,0 +1,144 @@
+package sf6
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (r *Handler) handleSF6Feed(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6FeedService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		common.RespondEphemeral(s, i, "サブコマンドを指定してください")
+		return
+	}
+	sub := data.Options[0]
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	switch sub.Name {
+	case "channel":
+		if !sf6FetchAllowed(i) {
+			common.RespondEphemeral(s, i, "管理者または許可ユーザーのみ実行できます")
+			return
+		}
+		channelID := ""
+		for _, opt := range sub.Options {
+			if opt.Name == "channel" {
+				if v, ok := opt.Value.(string); ok {
+					channelID = v
+				}
+			}
+		}
+		if channelID == "" {
+			common.RespondEphemeral(s, i, "channel が必要です")
+			return
+		}
+		if err := r.SF6FeedService.SetChannel(ctx, i.GuildID, channelID, userID); err != nil {
+			common.RespondEphemeral(s, i, "設定に失敗: "+err.Error())
+			return
+		}
+		common.RespondEphemeral(s, i, "<#"+channelID+"> に新着対戦を投稿します（これ以降に取得した対戦から）")
+	case "off":
+		if !sf6FetchAllowed(i) {
+			common.RespondEphemeral(s, i, "管理者または許可ユーザーのみ実行できます")
+			return
+		}
+		disabled, err := r.SF6FeedService.DisableChannel(ctx, i.GuildID)
+		if err != nil {
+			common.RespondEphemeral(s, i, "解除に失敗: "+err.Error())
+			return
+		}
+		if !disabled {
+			common.RespondEphemeral(s, i, "投稿先は設定されていません")
+			return
+		}
+		common.RespondEphemeral(s, i, "新着対戦の投稿を停止しました")
+	case "optout", "optin":
+		optOut := sub.Name == "optout"
+		if err := r.SF6FeedService.SetOptOut(ctx, i.GuildID, userID, optOut); err != nil {
+			common.RespondEphemeral(s, i, "設定に失敗: "+err.Error())
+			return
+		}
+		if optOut {
+			common.RespondEphemeral(s, i, "あなたが関わる対戦は新着投稿に出しません")
+			return
+		}
+		common.RespondEphemeral(s, i, "あなたが関わる対戦を新着投稿に出します")
+	default:
+		common.RespondEphemeral(s, i, "不明なサブコマンドです")
+	}
+}
+
+type feedNotifier struct {
+	handler *Handler
+	dg      *discordgo.Session
+}
+
+// FeedNotifier は新着対戦をフィードチャンネルに投稿する notifier を返す。
+func (h *Handler) FeedNotifier(dg *discordgo.Session) service.SF6FeedNotifier {
+	return &feedNotifier{handler: h, dg: dg}
+}
+
+func (n *feedNotifier) PostSF6Feed(ctx context.Context, group domain.SF6FeedGroup) error {
+	if n.dg == nil || group.ChannelID == "" || len(group.Battles) == 0 {
+		return nil
+	}
+	embed := n.handler.buildFeedEmbed(ctx, n.dg, group)
+	_, err := n.dg.ChannelMessageSendEmbed(group.ChannelID, embed)
+	return err
+}
+
+// buildFeedEmbed は /sf6_stats と同じ embed に、今回の新着分だけの成績を載せる。
+func (r *Handler) buildFeedEmbed(ctx context.Context, s *discordgo.Session, group domain.SF6FeedGroup) *discordgo.MessageEmbed {
+	subject := r.buildScoreboardUser(ctx, s, group.GuildID, group.SubjectFighterID)
+	opponent := r.buildScoreboardUser(ctx, s, group.GuildID, group.OpponentFighterID)
+	totals, _ := summarizeStats(historyRowsToStatRows(group.Battles))
+	first := group.Battles[0].BattleAt
+	last := group.Battles[len(group.Battles)-1].BattleAt
+
+	score := fmt.Sprintf("%d-%d", totals.Wins, totals.Losses)
+	if totals.Draws > 0 {
+		score += fmt.Sprintf("-%d", totals.Draws)
+	}
+	period := fmt.Sprintf("%s vs %s: %s（新着 %d 試合）\n%s", feedUserLabel(subject), feedUserLabel(opponent), score, totals.Total, formatFeedPeriod(first, last))
+	embed := buildStatsEmbed("SF6 New Matches", period, subject, opponent, historyRowsToStatRows(group.Battles))
+	embed.Timestamp = last.UTC().Format(time.RFC3339)
+	return embed
+}
+
+func feedUserLabel(user statsEmbedUser) string {
+	if user.Mention != "" {
+		return user.Mention
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return "`" + user.SID + "`"
+}
+
+func formatFeedPeriod(first, last time.Time) string {
+	if first.Equal(last) {
+		return formatJST(first) + " JST"
+	}
+	return fmt.Sprintf("%s 〜 %s JST", formatJST(first), formatJST(last))
+}
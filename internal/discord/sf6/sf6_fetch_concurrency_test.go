@@ -0,0 +1,110 @@
+package sf6
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/buckler/bucklertest"
+	"backend/internal/domain"
+	"backend/internal/repository/repositorytest"
+	"backend/internal/service"
+)
+
+type testPollLogger struct{ t *testing.T }
+
+func (l testPollLogger) Infof(format string, args ...interface{}) { l.t.Logf(format, args...) }
+func (l testPollLogger) Error(args ...interface{})                { l.t.Error(args...) }
+
+func newFakeBucklerClient(t *testing.T) (*buckler.Client, *bucklertest.Server) {
+	t.Helper()
+	srv := bucklertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetClientEnv(t)
+	cfg, err := buckler.LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+	c, err := buckler.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, srv
+}
+
+// TestSF6StatsFetchAndPollerShareFetchPath は /sf6_stats の最新取得とポーラーが同時に同じ fighter を取得する状況を再現する。
+// どちらも SF6Service.FetchAndStoreBattles を通るので、セッション失効後の再ログインが 1 回で済み、保存が重複しないことを確かめる。
+// go test -race で実行すること。
+func TestSF6StatsFetchAndPollerShareFetchPath(t *testing.T) {
+	t.Setenv("SF6_BATTLELOG_KINDS", "custom,rank")
+	t.Setenv("SF6_POLL_MAX_PAGES", "1")
+	client, srv := newFakeBucklerClient(t)
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: bucklertest.FixtureSID},
+		{GuildID: "g2", UserID: "u2", FighterID: bucklertest.FixtureSID},
+	}}
+	battleRepo := repositorytest.NewSF6BattleRepo()
+	svc := service.NewSF6Service(client, battleRepo, accountRepo, 0)
+	accountSvc := service.NewSF6AccountService(accountRepo, nil, battleRepo)
+	h := NewHandler(accountSvc, nil, svc, nil, nil, nil, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	pollCtx, stopPoller := context.WithCancel(ctx)
+	defer stopPoller()
+
+	// ログイン済みのセッションが失効した状態から始める
+	if err := client.EnsureLogin(ctx); err != nil {
+		t.Fatalf("EnsureLogin() error = %v", err)
+	}
+	srv.ExpireSessions()
+	logins := srv.LoginCount()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		opts := service.SF6PollOptions{
+			Tick:     time.Hour,
+			MaxPages: 1,
+			Kinds:    []buckler.BattlelogKind{buckler.BattlelogCustom, buckler.BattlelogRank},
+			Workers:  2,
+			// 1 周で止める
+			AfterRound: func(ctx context.Context, summary service.SF6PollSummary) {
+				if summary.Failed > 0 {
+					t.Errorf("poll round failed jobs = %d", summary.Failed)
+				}
+				stopPoller()
+			},
+		}
+		service.RunSF6Poller(pollCtx, opts, accountRepo, nil, nil, nil, nil, svc, testPollLogger{t})
+	}()
+	for i := 0; i < 6; i++ {
+		account := accountRepo.Accounts[i%len(accountRepo.Accounts)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 別ユーザーが他人の sid を指定しても、取得は連携している本人として行われる
+			if err := h.fetchLatestForStats(ctx, account.GuildID, "requester", account.FighterID); err != nil {
+				t.Errorf("fetchLatestForStats() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := srv.LoginCount() - logins; got != 1 {
+		t.Fatalf("relogins = %d, want 1", got)
+	}
+	// ギルドごとに custom（10 件）と rank（3 件）の 1 ページ目を 1 回ずつ保存する
+	battles := battleRepo.Battles()
+	if got := len(battles); got != 26 {
+		t.Fatalf("stored battles = %d, want 26", got)
+	}
+	for _, battle := range battles {
+		if battle.UserID == "requester" {
+			t.Fatalf("battle %s saved as requester", battle.SourceKey)
+		}
+	}
+}
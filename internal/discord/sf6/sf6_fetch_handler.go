@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -35,6 +37,23 @@ func (r *Handler) handleSF6Fetch(s *discordgo.Session, i *discordgo.InteractionC
 		return
 	}
 
+	data := i.ApplicationCommandData()
+	sub := ""
+	if len(data.Options) > 0 {
+		sub = data.Options[0].Name
+	}
+	switch sub {
+	case "backfill":
+		r.handleSF6FetchBackfill(s, i, userID, data.Options[0])
+	case "status":
+		r.handleSF6FetchStatus(s, i)
+	default:
+		r.handleSF6FetchAll(s, i)
+	}
+}
+
+// handleSF6FetchAll はギルド内の連携アカウントとフレンドの直近ページを取得する。
+func (r *Handler) handleSF6FetchAll(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	ctx, cancel := common.CommandContextForInteraction(s, i)
 	defer cancel()
 	accounts, err := r.SF6AccountService.ListByGuild(ctx, i.GuildID)
@@ -66,14 +85,19 @@ func (r *Handler) handleSF6Fetch(s *discordgo.Session, i *discordgo.InteractionC
 	friendsFetched := 0
 	skippedFriends := 0
 	fetchErrors := 0
+	var lastErr error
 
 	for _, acc := range accounts {
+		if lastErr != nil && service.IsSF6FetchFatal(lastErr) {
+			break
+		}
 		if acc.FighterID == "" || acc.UserID == "" {
 			continue
 		}
-		saved, pages, err := r.initialFetch(ctx, i.GuildID, acc.UserID, acc.FighterID)
+		saved, pages, err := r.initialFetch(ctx, domain.SF6FetchTriggerManual, i.GuildID, acc.UserID, acc.FighterID)
 		if err != nil {
 			fetchErrors++
+			lastErr = err
 			continue
 		}
 		accountsFetched++
@@ -83,6 +107,9 @@ func (r *Handler) handleSF6Fetch(s *discordgo.Session, i *discordgo.InteractionC
 
 	seenFriends := make(map[string]struct{}, len(friends))
 	for _, friend := range friends {
+		if lastErr != nil && service.IsSF6FetchFatal(lastErr) {
+			break
+		}
 		if friend.FighterID == "" || friend.UserID == "" {
 			continue
 		}
@@ -94,9 +121,10 @@ func (r *Handler) handleSF6Fetch(s *discordgo.Session, i *discordgo.InteractionC
 			continue
 		}
 		seenFriends[friend.FighterID] = struct{}{}
-		saved, pages, err := r.initialFetch(ctx, i.GuildID, friend.UserID, friend.FighterID)
+		saved, pages, err := r.initialFetch(ctx, domain.SF6FetchTriggerManual, i.GuildID, friend.UserID, friend.FighterID)
 		if err != nil {
 			fetchErrors++
+			lastErr = err
 			continue
 		}
 		friendsFetched++
@@ -106,6 +134,13 @@ func (r *Handler) handleSF6Fetch(s *discordgo.Session, i *discordgo.InteractionC
 
 	msg := fmt.Sprintf("取得完了。accounts=%d friends=%d skipped=%d saved=%d pages=%d errors=%d",
 		accountsFetched, friendsFetched, skippedFriends, totalSaved, pagesFetched, fetchErrors)
+	if lastErr != nil {
+		if service.IsSF6FetchFatal(lastErr) {
+			msg += "\n途中で中断しました: " + formatSF6FetchError(lastErr)
+		} else {
+			msg += "\n最後のエラー: " + formatSF6FetchError(lastErr)
+		}
+	}
 	common.FollowupEphemeral(s, i, msg)
 }
 
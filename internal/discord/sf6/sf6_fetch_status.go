@@ -0,0 +1,190 @@
+package sf6
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const sf6ColorFetchStatus = 0x3498DB
+
+// embed のフィールドは 1024 文字までなので、表示する fighter 数を絞る。
+const sf6FetchStatusMaxFighters = 12
+
+// handleSF6FetchStatus は fighter ごとの直近の取得・次回予定と、直近の失敗を表示する。
+func (r *Handler) handleSF6FetchStatus(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if r.SF6FetchRunService == nil {
+		common.FollowupEphemeral(s, i, "取得履歴は無効です")
+		return
+	}
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	status, err := r.SF6FetchRunService.Status(ctx, i.GuildID)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "取得状況の取得に失敗: "+err.Error())
+		return
+	}
+	common.FollowupEphemeralEmbed(s, i, buildSF6FetchStatusEmbed(status, r.fighterNames(ctx, i.GuildID)), nil)
+}
+
+// fighterNames はギルドの連携アカウントとフレンドから sid -> 表示名を作る。連携アカウントの名前を優先する。
+func (r *Handler) fighterNames(ctx context.Context, guildID string) map[string]string {
+	names := make(map[string]string)
+	if r.SF6AccountService != nil {
+		if accounts, err := r.SF6AccountService.ListByGuild(ctx, guildID); err == nil {
+			for _, acc := range accounts {
+				if acc.DisplayName != "" {
+					names[acc.FighterID] = acc.DisplayName
+				}
+			}
+		}
+	}
+	if r.SF6FriendService != nil {
+		if friends, err := r.SF6FriendService.ListByGuild(ctx, guildID); err == nil {
+			for _, friend := range friends {
+				if _, ok := names[friend.FighterID]; ok {
+					continue
+				}
+				if friend.Alias != "" {
+					names[friend.FighterID] = friend.Alias
+				} else if friend.DisplayName != "" {
+					names[friend.FighterID] = friend.DisplayName
+				}
+			}
+		}
+	}
+	return names
+}
+
+func buildSF6FetchStatusEmbed(status *domain.SF6FetchStatus, names map[string]string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: "SF6 取得状況",
+		Color: sf6ColorFetchStatus,
+	}
+	if len(status.Fighters) == 0 {
+		embed.Description = "まだ取得履歴がありません"
+		return embed
+	}
+
+	var fighters strings.Builder
+	for idx, fighter := range status.Fighters {
+		if idx >= sf6FetchStatusMaxFighters {
+			fmt.Fprintf(&fighters, "…ほか %d 件", len(status.Fighters)-idx)
+			break
+		}
+		fighters.WriteString(sf6FetchFighterLabel(fighter.FighterID, names))
+		if run := fighter.LastRun; run != nil {
+			fmt.Fprintf(&fighters, "\n%s %s %s %dページ +%d件 %s",
+				sf6FetchRunMark(*run), discordRelativeTime(run.StartedAt), sf6FetchTriggerLabel(run.Trigger),
+				run.Pages, run.Saved, run.Duration.Round(100*time.Millisecond))
+			if run.ErrorClass != "" {
+				fmt.Fprintf(&fighters, " (%s)", sf6FetchErrorClassLabel(run.ErrorClass))
+			}
+		} else {
+			fighters.WriteString("\n未取得")
+		}
+		if fighter.NextPollAt != nil {
+			fmt.Fprintf(&fighters, "\n次回: %s（間隔 %s）", discordRelativeTime(*fighter.NextPollAt), fighter.Interval)
+		}
+		fighters.WriteString("\n")
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "fighter ごとの直近の取得",
+		Value: truncateEmbedValue(fighters.String()),
+	})
+
+	failures := "なし"
+	if len(status.Failures) > 0 {
+		var b strings.Builder
+		for _, run := range status.Failures {
+			fmt.Fprintf(&b, "%s %s %s %s",
+				discordRelativeTime(run.StartedAt), sf6FetchFighterLabel(run.FighterID, names),
+				sf6FetchTriggerLabel(run.Trigger), sf6FetchErrorClassLabel(run.ErrorClass))
+			if run.Attempt > 1 {
+				fmt.Fprintf(&b, "（%d 回目）", run.Attempt)
+			}
+			b.WriteString("\n")
+		}
+		failures = truncateEmbedValue(b.String())
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "直近の失敗",
+		Value: failures,
+	})
+	return embed
+}
+
+func sf6FetchFighterLabel(fighterID string, names map[string]string) string {
+	if name := names[fighterID]; name != "" {
+		return fmt.Sprintf("**%s** `%s`", name, fighterID)
+	}
+	return "`" + fighterID + "`"
+}
+
+func sf6FetchRunMark(run domain.SF6FetchRun) string {
+	if run.ErrorClass != "" {
+		return "❌"
+	}
+	return "✅"
+}
+
+func sf6FetchTriggerLabel(trigger string) string {
+	switch trigger {
+	case domain.SF6FetchTriggerPoller:
+		return "定期"
+	case domain.SF6FetchTriggerManual:
+		return "手動"
+	case domain.SF6FetchTriggerStats:
+		return "統計"
+	case domain.SF6FetchTriggerSession:
+		return "セッション"
+	default:
+		return trigger
+	}
+}
+
+func sf6FetchErrorClassLabel(class string) string {
+	switch class {
+	case service.SF6FetchErrorAuth:
+		return "ログイン失敗"
+	case service.SF6FetchErrorRateLimited:
+		return "レート制限"
+	case service.SF6FetchErrorMaintenance:
+		return "メンテナンス"
+	case service.SF6FetchErrorNotFound:
+		return "プロフィールなし"
+	case service.SF6FetchErrorSchema:
+		return "仕様変更"
+	case service.SF6FetchErrorTimeout:
+		return "タイムアウト"
+	case service.SF6FetchErrorCanceled:
+		return "中断"
+	default:
+		return "エラー"
+	}
+}
+
+// discordRelativeTime は閲覧者のタイムゾーンで「3 分前」のように表示される Discord のタイムスタンプ記法を返す。
+func discordRelativeTime(t time.Time) string {
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
+
+func truncateEmbedValue(value string) string {
+	const limit = 1024
+	value = strings.TrimRight(value, "\n")
+	if len(value) <= limit {
+		return value
+	}
+	cut := strings.LastIndex(value[:limit-len("\n…")], "\n")
+	if cut <= 0 {
+		cut = limit - len("\n…")
+	}
+	return value[:cut] + "\n…"
+}
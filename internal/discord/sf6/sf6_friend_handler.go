@@ -156,7 +156,7 @@ func (r *Handler) handleSF6FriendModalSubmit(s *discordgo.Session, i *discordgo.
 		}
 		var fetchErr error
 		if r.SF6Service != nil {
-			_, _, fetchErr = r.initialFetch(ctx, i.GuildID, userID, userCode)
+			_, _, fetchErr = r.initialFetch(ctx, domain.SF6FetchTriggerManual, i.GuildID, userID, userCode)
 		}
 		friends, err := r.SF6FriendService.List(ctx, i.GuildID, userID)
 		if err != nil {
@@ -166,7 +166,7 @@ func (r *Handler) handleSF6FriendModalSubmit(s *discordgo.Session, i *discordgo.
 		embed := r.buildFriendEmbed(ctx, "SF6 Friends", i.GuildID, userID, user, friends)
 		common.FollowupEphemeralEmbed(s, i, embed, sf6FriendButtons())
 		if fetchErr != nil {
-			common.FollowupEphemeral(s, i, "登録完了。初回取得に失敗: "+fetchErr.Error())
+			common.FollowupEphemeral(s, i, "登録完了。初回取得に失敗: "+formatSF6FetchError(fetchErr))
 		}
 		return true
 	case "sf6_friend_remove_modal":
@@ -39,13 +39,15 @@ func (r *Handler) handleSF6History(s *discordgo.Session, i *discordgo.Interactio
 
 	data := i.ApplicationCommandData()
 	opts := data.Options
-	var opponentCode, subjectCode string
+	var opponentCode, subjectCode, battleType string
 	for _, opt := range opts {
 		switch opt.Name {
 		case "opponent_code":
 			opponentCode = opt.StringValue()
 		case "subject_code":
 			subjectCode = opt.StringValue()
+		case "mode":
+			battleType = parseSF6Mode(opt.StringValue())
 		}
 	}
 	if opponentCode == "" {
@@ -76,12 +78,12 @@ func (r *Handler) handleSF6History(s *discordgo.Session, i *discordgo.Interactio
 	common.Logf("[sf6][history] deferred guild=%s user=%s subject=%s opponent=%s", i.GuildID, userID, subjectSID, opponentCode)
 	if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
 		_ = common.EditInteractionResponse(s, i, "最新取得に失敗しました", nil, nil)
-		common.FollowupEphemeral(s, i, "最新取得に失敗: "+err.Error())
+		common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
 		return
 	}
 	common.Logf("[sf6][history] fetch ok guild=%s user=%s subject=%s opponent=%s", i.GuildID, userID, subjectSID, opponentCode)
 
-	embed, components, err := r.buildSF6HistoryEmbed(ctx, s, i.GuildID, userID, subjectSID, opponentCode, 1)
+	embed, components, err := r.buildSF6HistoryEmbed(ctx, s, i.GuildID, userID, subjectSID, opponentCode, battleType, 1)
 	if err != nil {
 		_ = common.EditInteractionResponse(s, i, "履歴取得に失敗しました", nil, nil)
 		common.FollowupEphemeral(s, i, "履歴取得に失敗: "+err.Error())
@@ -96,7 +98,7 @@ func (r *Handler) handleSF6History(s *discordgo.Session, i *discordgo.Interactio
 }
 
 func (r *Handler) handleSF6HistoryComponent(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
-	ownerID, subjectSID, opponentSID, page, ok := parseSF6HistoryCustomID(customID)
+	ownerID, subjectSID, opponentSID, battleType, page, ok := parseSF6HistoryCustomID(customID)
 	if !ok {
 		common.RespondEphemeral(s, i, "不正な操作です")
 		return
@@ -107,7 +109,7 @@ func (r *Handler) handleSF6HistoryComponent(s *discordgo.Session, i *discordgo.I
 	}
 	ctx, cancel := common.CommandContextForInteraction(s, i)
 	defer cancel()
-	embed, components, err := r.buildSF6HistoryEmbed(ctx, s, i.GuildID, ownerID, subjectSID, opponentSID, page)
+	embed, components, err := r.buildSF6HistoryEmbed(ctx, s, i.GuildID, ownerID, subjectSID, opponentSID, battleType, page)
 	if err != nil {
 		common.RespondEphemeral(s, i, "履歴取得に失敗: "+err.Error())
 		return
@@ -121,11 +123,11 @@ func (r *Handler) handleSF6HistoryComponent(s *discordgo.Session, i *discordgo.I
 	})
 }
 
-func (r *Handler) buildSF6HistoryEmbed(ctx context.Context, s *discordgo.Session, guildID, ownerID, subjectSID, opponentSID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+func (r *Handler) buildSF6HistoryEmbed(ctx context.Context, s *discordgo.Session, guildID, ownerID, subjectSID, opponentSID, battleType string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
 	if page <= 0 {
 		page = 1
 	}
-	total, err := r.SF6Service.CountByOpponent(ctx, guildID, subjectSID, opponentSID)
+	total, err := r.SF6Service.CountByOpponent(ctx, guildID, subjectSID, opponentSID, battleType)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -137,30 +139,30 @@ func (r *Handler) buildSF6HistoryEmbed(ctx context.Context, s *discordgo.Session
 		page = totalPages
 	}
 	offset := (page - 1) * sf6HistoryPageSize
-	rows, err := r.SF6Service.HistoryByOpponent(ctx, guildID, subjectSID, opponentSID, sf6HistoryPageSize, offset)
+	rows, err := r.SF6Service.HistoryByOpponent(ctx, guildID, subjectSID, opponentSID, battleType, sf6HistoryPageSize, offset)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	subject := r.buildSF6HistoryUser(ctx, s, guildID, subjectSID)
 	opponent := r.buildSF6HistoryUser(ctx, s, guildID, opponentSID)
-	desc := buildSF6HistoryDescription(subject, opponent, rows)
+	desc := buildSF6HistoryDescription(subject, opponent, rows, battleType == "")
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "SF6 History",
 		Description: desc,
 		Color:       0xF1C40F,
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Page %d/%d • Total %d", page, totalPages, total),
+			Text: fmt.Sprintf("Page %d/%d • Total %d • Mode %s", page, totalPages, total, formatSF6ModeLabel(battleType)),
 		},
 	}
 	applySF6HistoryIcons(embed, subject, opponent)
 
-	components := buildSF6HistoryButtons(ownerID, subjectSID, opponentSID, page, totalPages)
+	components := buildSF6HistoryButtons(ownerID, subjectSID, opponentSID, battleType, page, totalPages)
 	return embed, components, nil
 }
 
-func buildSF6HistoryDescription(subject, opponent sf6HistoryUser, rows []domain.SF6BattleHistoryRow) string {
+func buildSF6HistoryDescription(subject, opponent sf6HistoryUser, rows []domain.SF6BattleHistoryRow, showMode bool) string {
 	if len(rows) == 0 {
 		return "該当データなし"
 	}
@@ -171,8 +173,12 @@ func buildSF6HistoryDescription(subject, opponent sf6HistoryUser, rows []domain.
 		leftResult, rightResult := formatSF6HistoryResult(row.Result)
 		leftChar := formatSF6Character(row.SelfCharacter)
 		rightChar := formatSF6Character(row.OpponentCharacter)
-		line := fmt.Sprintf("%s JST\n%s [%s]  %s vs %s  [%s] %s",
-			formatJST(row.BattleAt),
+		header := formatJST(row.BattleAt) + " JST"
+		if showMode && row.BattleType != "" {
+			header += " • " + formatSF6ModeLabel(row.BattleType)
+		}
+		line := fmt.Sprintf("%s\n%s [%s]  %s vs %s  [%s] %s",
+			header,
 			leftName, leftChar, leftResult, rightResult, rightChar, rightName,
 		)
 		lines = append(lines, line)
@@ -266,17 +272,17 @@ func formatSF6Character(name string) string {
 	return strings.ToUpper(name)
 }
 
-func buildSF6HistoryButtons(ownerID, subjectSID, opponentSID string, page, totalPages int) []discordgo.MessageComponent {
+func buildSF6HistoryButtons(ownerID, subjectSID, opponentSID, battleType string, page, totalPages int) []discordgo.MessageComponent {
 	firstPage := 1
 	lastPage := totalPages
 	prevPage := page - 1
 	nextPage := page + 1
 	prevDisabled := page <= 1
 	nextDisabled := page >= totalPages
-	firstID := buildSF6HistoryCustomIDWithAction("first", ownerID, subjectSID, opponentSID, firstPage)
-	prevID := buildSF6HistoryCustomIDWithAction("prev", ownerID, subjectSID, opponentSID, maxInt(prevPage, 1))
-	nextID := buildSF6HistoryCustomIDWithAction("next", ownerID, subjectSID, opponentSID, minInt(nextPage, totalPages))
-	lastID := buildSF6HistoryCustomIDWithAction("last", ownerID, subjectSID, opponentSID, lastPage)
+	firstID := buildSF6HistoryCustomIDWithAction("first", ownerID, subjectSID, opponentSID, battleType, firstPage)
+	prevID := buildSF6HistoryCustomIDWithAction("prev", ownerID, subjectSID, opponentSID, battleType, maxInt(prevPage, 1))
+	nextID := buildSF6HistoryCustomIDWithAction("next", ownerID, subjectSID, opponentSID, battleType, minInt(nextPage, totalPages))
+	lastID := buildSF6HistoryCustomIDWithAction("last", ownerID, subjectSID, opponentSID, battleType, lastPage)
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
@@ -309,35 +315,39 @@ func buildSF6HistoryButtons(ownerID, subjectSID, opponentSID string, page, total
 	}
 }
 
-func buildSF6HistoryCustomID(ownerID, subjectSID, opponentSID string, page int) string {
-	return buildSF6HistoryCustomIDWithAction("page", ownerID, subjectSID, opponentSID, page)
+func buildSF6HistoryCustomID(ownerID, subjectSID, opponentSID, battleType string, page int) string {
+	return buildSF6HistoryCustomIDWithAction("page", ownerID, subjectSID, opponentSID, battleType, page)
 }
 
-func buildSF6HistoryCustomIDWithAction(action, ownerID, subjectSID, opponentSID string, page int) string {
+func buildSF6HistoryCustomIDWithAction(action, ownerID, subjectSID, opponentSID, battleType string, page int) string {
 	if page <= 0 {
 		page = 1
 	}
-	return "sf6_history_page:" + action + ":" + ownerID + ":" + subjectSID + ":" + opponentSID + ":" + strconv.Itoa(page)
+	return "sf6_history_page:" + action + ":" + ownerID + ":" + subjectSID + ":" + opponentSID + ":" + strconv.Itoa(page) + ":" + battleType
 }
 
-func parseSF6HistoryCustomID(customID string) (string, string, string, int, bool) {
+func parseSF6HistoryCustomID(customID string) (string, string, string, string, int, bool) {
 	parts := strings.Split(customID, ":")
-	if len(parts) != 5 && len(parts) != 6 {
-		return "", "", "", 0, false
+	if len(parts) < 5 || len(parts) > 7 {
+		return "", "", "", "", 0, false
 	}
 	if parts[0] != "sf6_history_page" {
-		return "", "", "", 0, false
+		return "", "", "", "", 0, false
 	}
 	if len(parts) == 5 {
 		page, err := strconv.Atoi(parts[4])
 		if err != nil || page <= 0 {
-			return "", "", "", 0, false
+			return "", "", "", "", 0, false
 		}
-		return parts[1], parts[2], parts[3], page, true
+		return parts[1], parts[2], parts[3], "", page, true
 	}
 	page, err := strconv.Atoi(parts[5])
 	if err != nil || page <= 0 {
-		return "", "", "", 0, false
+		return "", "", "", "", 0, false
+	}
+	battleType := ""
+	if len(parts) == 7 {
+		battleType = parseSF6Mode(parts[6])
 	}
-	return parts[2], parts[3], parts[4], page, true
+	return parts[2], parts[3], parts[4], battleType, page, true
 }
@@ -0,0 +1,329 @@
+package sf6
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const sf6LeaderboardPageSize = 10
+
+// custom_id に載せる期間は JST の日付（to は最終日）。
+const sf6LeaderboardDateLayout = "20060102"
+
+// sf6LeaderboardQuery は /sf6_leaderboard の条件。ページ送りの custom_id にそのまま載せる。
+type sf6LeaderboardQuery struct {
+	SortBy     string
+	MinGames   int
+	From       string
+	To         string
+	BattleType string
+}
+
+func (r *Handler) handleSF6Leaderboard(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6Service == nil || r.SF6AccountService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+
+	query := sf6LeaderboardQuery{
+		SortBy:   service.SF6LeaderboardSortWinRate,
+		MinGames: service.SF6LeaderboardDefaultMinGames,
+	}
+	var fromStr, toStr string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "sort":
+			query.SortBy = opt.StringValue()
+		case "min_games":
+			query.MinGames = int(opt.IntValue())
+		case "from":
+			fromStr = opt.StringValue()
+		case "to":
+			toStr = opt.StringValue()
+		case "mode":
+			query.BattleType = parseSF6Mode(opt.StringValue())
+		}
+	}
+	if query.MinGames <= 0 {
+		common.RespondEphemeral(s, i, "min_games は 1 以上を指定してください")
+		return
+	}
+	if (fromStr == "") != (toStr == "") {
+		common.RespondEphemeral(s, i, "from と to は両方指定してください（省略すると全期間）")
+		return
+	}
+	if fromStr != "" {
+		startAt, endAt, err := parseDateRangeJST(fromStr, toStr)
+		if err != nil {
+			common.RespondEphemeral(s, i, "日付形式エラー: "+err.Error())
+			return
+		}
+		query.From = startAt.Format(sf6LeaderboardDateLayout)
+		query.To = endAt.AddDate(0, 0, -1).Format(sf6LeaderboardDateLayout)
+	}
+	common.Logf("[sf6][leaderboard] start guild=%s user=%s sort=%s from=%s to=%s", i.GuildID, userID, query.SortBy, query.From, query.To)
+
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	embed, components, err := r.buildSF6LeaderboardEmbed(ctx, i.GuildID, userID, query, 1)
+	if err != nil {
+		_ = common.EditInteractionResponse(s, i, "集計に失敗しました", nil, nil)
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	if err := common.EditInteractionResponse(s, i, "", embed, components); err != nil {
+		common.Logf("[sf6][leaderboard] edit response failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+		common.FollowupPublicEmbed(s, i, "", embed, components)
+	}
+}
+
+func (r *Handler) handleSF6LeaderboardComponent(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	ownerID, query, page, ok := parseSF6LeaderboardCustomID(customID)
+	if !ok {
+		common.RespondEphemeral(s, i, "不正な操作です")
+		return
+	}
+	if ownerID != "" && common.InteractionUserID(i) != ownerID {
+		common.RespondEphemeral(s, i, "この操作は発行者のみ実行できます")
+		return
+	}
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	embed, components, err := r.buildSF6LeaderboardEmbed(ctx, i.GuildID, ownerID, query, page)
+	if err != nil {
+		common.RespondEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func (r *Handler) buildSF6LeaderboardEmbed(ctx context.Context, guildID, ownerID string, query sf6LeaderboardQuery, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	if page <= 0 {
+		page = 1
+	}
+	startAt, endAt, err := query.period()
+	if err != nil {
+		return nil, nil, err
+	}
+	board, err := r.SF6Service.Leaderboard(ctx, guildID, query.BattleType, startAt, endAt, query.SortBy, query.MinGames)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts, err := r.SF6AccountService.ListByGuild(ctx, guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members := make(map[string]domain.SF6Account, len(accounts))
+	for _, acc := range accounts {
+		if _, ok := members[acc.FighterID]; !ok {
+			members[acc.FighterID] = acc
+		}
+	}
+
+	total := len(board.Entries)
+	totalPages := (total + sf6LeaderboardPageSize - 1) / sf6LeaderboardPageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+	offset := (page - 1) * sf6LeaderboardPageSize
+	entries := board.Entries[offset:min(offset+sf6LeaderboardPageSize, total)]
+
+	lines := []string{query.periodLabel()}
+	if len(entries) == 0 {
+		lines = append(lines, "", "該当データなし（連携メンバーどうしの対戦のみ対象です）")
+	}
+	for idx, entry := range entries {
+		lines = append(lines, formatSF6LeaderboardLine(offset+idx+1, entry, members[entry.FighterID], board.SortBy))
+	}
+
+	footer := fmt.Sprintf("Page %d/%d • %s • Mode %s", page, totalPages, formatSF6LeaderboardSort(board.SortBy), formatSF6ModeLabel(query.BattleType))
+	if board.SortBy == service.SF6LeaderboardSortWinRate {
+		footer += fmt.Sprintf(" • %d 戦以上（対象外 %d 人）", board.MinGames, board.Hidden)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "SF6 Leaderboard",
+		Description: strings.Join(lines, "\n"),
+		Color:       0xF1C40F,
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+	}
+	components := buildSF6LeaderboardButtons(ownerID, query, page, totalPages)
+	return embed, components, nil
+}
+
+// formatSF6LeaderboardLine は 1 人分の行。並べ替えに使った値を太字にする。
+func formatSF6LeaderboardLine(rank int, entry domain.SF6LeaderboardEntry, member domain.SF6Account, sortBy string) string {
+	name := "`" + entry.FighterID + "`"
+	if member.UserID != "" {
+		name = "<@" + member.UserID + ">"
+	} else if member.DisplayName != "" {
+		name = member.DisplayName
+	}
+	emphasize := func(key, value string) string {
+		if key == sortBy {
+			return "**" + value + "**"
+		}
+		return value
+	}
+	record := fmt.Sprintf("%d-%d", entry.Wins, entry.Losses)
+	if entry.Draws > 0 {
+		record += fmt.Sprintf("-%d", entry.Draws)
+	}
+	return fmt.Sprintf("%d. %s  %s (%s) • %s • 最長 %s • 相手 %s",
+		rank, name,
+		emphasize(service.SF6LeaderboardSortWinRate, fmt.Sprintf("%.1f%%", entry.WinRate*100)), record,
+		emphasize(service.SF6LeaderboardSortGames, fmt.Sprintf("%d戦", entry.Games)),
+		emphasize(service.SF6LeaderboardSortStreak, fmt.Sprintf("%d連勝", entry.LongestWinStreak)),
+		emphasize(service.SF6LeaderboardSortOpponents, fmt.Sprintf("%d人", entry.Opponents)),
+	)
+}
+
+func formatSF6LeaderboardSort(sortBy string) string {
+	switch sortBy {
+	case service.SF6LeaderboardSortGames:
+		return "試合数順"
+	case service.SF6LeaderboardSortStreak:
+		return "最長連勝順"
+	case service.SF6LeaderboardSortOpponents:
+		return "対戦相手数順"
+	default:
+		return "勝率順"
+	}
+}
+
+// period は集計期間を返す。全期間ならゼロ値。
+func (q sf6LeaderboardQuery) period() (time.Time, time.Time, error) {
+	if q.From == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startAt, err := time.ParseInLocation(sf6LeaderboardDateLayout, q.From, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endAt, err := time.ParseInLocation(sf6LeaderboardDateLayout, q.To, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startAt, endAt.AddDate(0, 0, 1), nil
+}
+
+func (q sf6LeaderboardQuery) periodLabel() string {
+	if q.From == "" {
+		return "期間: 全期間"
+	}
+	startAt, endAt, err := q.period()
+	if err != nil {
+		return "期間: " + q.From + "〜" + q.To
+	}
+	return fmt.Sprintf("期間: %s〜%s (JST)", startAt.Format("2006-01-02"), endAt.AddDate(0, 0, -1).Format("2006-01-02"))
+}
+
+func buildSF6LeaderboardButtons(ownerID string, query sf6LeaderboardQuery, page, totalPages int) []discordgo.MessageComponent {
+	firstPage := 1
+	lastPage := totalPages
+	prevPage := page - 1
+	nextPage := page + 1
+	prevDisabled := page <= 1
+	nextDisabled := page >= totalPages
+	firstID := buildSF6LeaderboardCustomIDWithAction("first", ownerID, query, firstPage)
+	prevID := buildSF6LeaderboardCustomIDWithAction("prev", ownerID, query, maxInt(prevPage, 1))
+	nextID := buildSF6LeaderboardCustomIDWithAction("next", ownerID, query, minInt(nextPage, totalPages))
+	lastID := buildSF6LeaderboardCustomIDWithAction("last", ownerID, query, lastPage)
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "⏮ 最初",
+					Style:    discordgo.SecondaryButton,
+					CustomID: firstID,
+					Disabled: prevDisabled,
+				},
+				discordgo.Button{
+					Label:    "◀ 前へ",
+					Style:    discordgo.SecondaryButton,
+					CustomID: prevID,
+					Disabled: prevDisabled,
+				},
+				discordgo.Button{
+					Label:    "次へ ▶",
+					Style:    discordgo.SecondaryButton,
+					CustomID: nextID,
+					Disabled: nextDisabled,
+				},
+				discordgo.Button{
+					Label:    "最後 ⏭",
+					Style:    discordgo.SecondaryButton,
+					CustomID: lastID,
+					Disabled: nextDisabled,
+				},
+			},
+		},
+	}
+}
+
+func buildSF6LeaderboardCustomIDWithAction(action, ownerID string, query sf6LeaderboardQuery, page int) string {
+	if page <= 0 {
+		page = 1
+	}
+	return strings.Join([]string{
+		"sf6_leaderboard_page", action, ownerID, query.SortBy, strconv.Itoa(query.MinGames),
+		query.From, query.To, query.BattleType, strconv.Itoa(page),
+	}, ":")
+}
+
+func parseSF6LeaderboardCustomID(customID string) (string, sf6LeaderboardQuery, int, bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 9 || parts[0] != "sf6_leaderboard_page" {
+		return "", sf6LeaderboardQuery{}, 0, false
+	}
+	minGames, err := strconv.Atoi(parts[4])
+	if err != nil || minGames <= 0 {
+		return "", sf6LeaderboardQuery{}, 0, false
+	}
+	page, err := strconv.Atoi(parts[8])
+	if err != nil || page <= 0 {
+		return "", sf6LeaderboardQuery{}, 0, false
+	}
+	query := sf6LeaderboardQuery{
+		SortBy:     parts[3],
+		MinGames:   minGames,
+		From:       parts[5],
+		To:         parts[6],
+		BattleType: parseSF6Mode(parts[7]),
+	}
+	return parts[2], query, page, true
+}
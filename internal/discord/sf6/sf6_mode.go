@@ -0,0 +1,58 @@
+package sf6
+
+import (
+	"strings"
+
+	"backend/internal/buckler"
+)
+
+// parseSF6Mode は mode オプションを battle_type の絞り込み値に変換する。all/空は絞り込みなし。
+func parseSF6Mode(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.EqualFold(raw, "all") {
+		return ""
+	}
+	kind, err := buckler.ParseBattlelogKind(raw)
+	if err != nil {
+		return ""
+	}
+	return string(kind)
+}
+
+func formatSF6ModeLabel(battleType string) string {
+	switch buckler.BattlelogKind(battleType) {
+	case buckler.BattlelogRank:
+		return "Ranked"
+	case buckler.BattlelogCasual:
+		return "Casual"
+	case buckler.BattlelogHub:
+		return "Battle Hub"
+	case buckler.BattlelogCustom:
+		return "Custom Room"
+	case "":
+		return "All"
+	default:
+		return battleType
+	}
+}
+
+func appendSF6ModeLabel(label, battleType string) string {
+	if battleType == "" {
+		return label
+	}
+	return label + " / モード: " + formatSF6ModeLabel(battleType)
+}
+
+// parseSF6ModeLabel は appendSF6ModeLabel で付けた表示を battle_type に戻す。モードの表示が無ければ all（空）。
+func parseSF6ModeLabel(label string) string {
+	_, mode, ok := strings.Cut(label, " / モード: ")
+	if !ok {
+		return ""
+	}
+	for _, kind := range buckler.BattlelogKinds() {
+		if formatSF6ModeLabel(string(kind)) == strings.TrimSpace(mode) {
+			return string(kind)
+		}
+	}
+	return ""
+}
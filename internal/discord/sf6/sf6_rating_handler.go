@@ -0,0 +1,187 @@
+package sf6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const sf6ColorRating = 0xD69E2E
+
+const (
+	sf6RatingLeaderboardSize    = 20
+	sf6RatingHistoryDefault     = 20
+	sf6RatingHistoryMax         = 50
+	sf6RatingHistoryChangeLines = 15
+)
+
+func (r *Handler) handleSF6Rating(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		common.RespondEphemeral(s, i, "guildのみ対応")
+		return
+	}
+	if r.SF6RatingService == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です")
+		return
+	}
+	userID := common.InteractionUserID(i)
+	if userID == "" {
+		common.RespondEphemeral(s, i, "user_idの取得に失敗")
+		return
+	}
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		common.RespondEphemeral(s, i, "サブコマンドを指定してください")
+		return
+	}
+	sub := data.Options[0]
+
+	var code, character string
+	count := sf6RatingHistoryDefault
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "code":
+			code = opt.StringValue()
+		case "character":
+			character = service.NormalizeSF6Character(opt.StringValue())
+		case "count":
+			count = int(opt.IntValue())
+		}
+	}
+	if count <= 0 || count > sf6RatingHistoryMax {
+		common.RespondEphemeral(s, i, fmt.Sprintf("count は 1〜%d で指定してください", sf6RatingHistoryMax))
+		return
+	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+	// 定期取得の合間に保存された試合も反映してから表示する
+	if _, err := r.SF6RatingService.Refresh(ctx, i.GuildID); err != nil && !errors.Is(err, service.ErrSF6RatingConflict) {
+		common.FollowupEphemeral(s, i, "レーティングの更新に失敗: "+err.Error())
+		return
+	}
+
+	switch sub.Name {
+	case "leaderboard":
+		ratings, err := r.SF6RatingService.Leaderboard(ctx, i.GuildID, character, sf6RatingLeaderboardSize, 0)
+		if err != nil {
+			common.FollowupEphemeral(s, i, "取得に失敗: "+err.Error())
+			return
+		}
+		common.FollowupPublicEmbed(s, i, "", buildSF6RatingLeaderboardEmbed(character, ratings, r.fighterNames(ctx, i.GuildID)), nil)
+	case "history":
+		r.handleSF6RatingHistory(ctx, s, i, userID, code, character, count)
+	default:
+		common.FollowupEphemeral(s, i, "未対応のサブコマンドです")
+	}
+}
+
+func (r *Handler) handleSF6RatingHistory(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID, code, character string, count int) {
+	sid, err := r.resolveSubjectSID(ctx, i.GuildID, userID, code)
+	if err != nil {
+		common.FollowupEphemeral(s, i, err.Error())
+		return
+	}
+	rating, err := r.SF6RatingService.GetRating(ctx, i.GuildID, sid, character)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "取得に失敗: "+err.Error())
+		return
+	}
+	if rating == nil {
+		common.FollowupEphemeral(s, i, "レーティング対象の試合がありません（ギルド内の登録者どうしの対戦のみ対象です）")
+		return
+	}
+	changes, err := r.SF6RatingService.History(ctx, i.GuildID, sid, character, count)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "取得に失敗: "+err.Error())
+		return
+	}
+	common.FollowupPublicEmbed(s, i, "", buildSF6RatingHistoryEmbed(*rating, changes, r.fighterNames(ctx, i.GuildID)), nil)
+}
+
+func buildSF6RatingLeaderboardEmbed(character string, ratings []domain.SF6Rating, names map[string]string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: "SF6 Rating " + sf6RatingScopeLabel(character),
+		Color: sf6ColorRating,
+	}
+	if len(ratings) == 0 {
+		embed.Description = "まだレーティング対象の試合がありません（ギルド内の登録者どうしの対戦のみ対象です）"
+		return embed
+	}
+	var b strings.Builder
+	for idx, rating := range ratings {
+		fmt.Fprintf(&b, "%d. %s **%.0f** (%s, peak %.0f)\n",
+			idx+1, sf6FetchFighterLabel(rating.FighterID, names), rating.Rating, formatSF6RatingRecord(rating), rating.Peak)
+	}
+	embed.Description = strings.TrimRight(b.String(), "\n")
+	return embed
+}
+
+// buildSF6RatingHistoryEmbed は changes（新しい順）からレーティング推移を描く。
+func buildSF6RatingHistoryEmbed(rating domain.SF6Rating, changes []domain.SF6RatingChange, names map[string]string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: "SF6 Rating History " + sf6RatingScopeLabel(rating.Character),
+		Description: fmt.Sprintf("%s\n現在 **%.0f** / peak %.0f / %s",
+			sf6FetchFighterLabel(rating.FighterID, names), rating.Rating, rating.Peak, formatSF6RatingRecord(rating)),
+		Color: sf6ColorRating,
+	}
+	if len(changes) == 0 {
+		return embed
+	}
+
+	values := make([]float64, 0, len(changes)+1)
+	values = append(values, changes[len(changes)-1].Before)
+	for idx := len(changes) - 1; idx >= 0; idx-- {
+		values = append(values, changes[idx].After)
+	}
+	if len(values) > sf6SparklineMaxPoints {
+		values = values[len(values)-sf6SparklineMaxPoints:]
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  fmt.Sprintf("Trend (last %d)", len(changes)),
+		Value: fmt.Sprintf("`%s`\n%.0f → %.0f（min %.0f / max %.0f）", sparkline(values, lo, hi), values[0], values[len(values)-1], lo, hi),
+	})
+
+	var b strings.Builder
+	for idx, change := range changes {
+		if idx >= sf6RatingHistoryChangeLines {
+			break
+		}
+		fmt.Fprintf(&b, "%+.1f vs %s (%s) %s\n",
+			change.After-change.Before, sf6FetchFighterLabel(change.OpponentFighterID, names), change.Result, discordRelativeTime(change.BattleAt))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Recent",
+		Value: truncateEmbedValue(b.String()),
+	})
+	return embed
+}
+
+func sf6RatingScopeLabel(character string) string {
+	if character == "" {
+		return "(All Characters)"
+	}
+	return "(" + character + ")"
+}
+
+func formatSF6RatingRecord(rating domain.SF6Rating) string {
+	if rating.Draws > 0 {
+		return fmt.Sprintf("%d-%d-%d", rating.Wins, rating.Losses, rating.Draws)
+	}
+	return fmt.Sprintf("%d-%d", rating.Wins, rating.Losses)
+}
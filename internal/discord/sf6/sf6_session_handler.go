@@ -1,10 +1,12 @@
 package sf6
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"backend/internal/discord/common"
+	"backend/internal/domain"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -46,13 +48,17 @@ func (r *Handler) handleSF6Session(s *discordgo.Session, i *discordgo.Interactio
 			subjectCode = opt.StringValue()
 		}
 	}
+
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	if sub.Name == "list" {
+		r.handleSF6SessionList(ctx, s, i, userID, opponentCode)
+		return
+	}
 	if opponentCode == "" {
 		common.FollowupEphemeral(s, i, "opponent_code が必要です")
 		return
 	}
-
-	ctx, cancel := common.CommandContextForInteraction(s, i)
-	defer cancel()
 	if sid, _, ok, err := r.resolveSIDFromMention(ctx, i.GuildID, opponentCode); ok {
 		if err != nil {
 			common.FollowupEphemeral(s, i, err.Error())
@@ -69,13 +75,23 @@ func (r *Handler) handleSF6Session(s *discordgo.Session, i *discordgo.Interactio
 	switch sub.Name {
 	case "start":
 		startedAt := time.Now().UTC()
-		_, err := r.SF6SessionService.Start(ctx, i.GuildID, userID, opponentCode, startedAt)
+		session, err := r.SF6SessionService.Start(ctx, i.GuildID, userID, subjectSID, opponentCode, startedAt)
 		if err != nil {
 			common.FollowupEphemeral(s, i, "開始に失敗: "+err.Error())
 			return
 		}
 		label := fmt.Sprintf("セッション開始: subject=%s opponent=%s", subjectSID, opponentCode)
-		common.FollowupEphemeral(s, i, label)
+		session.ChannelID = i.ChannelID
+		channelID, messageID, err := r.updateSessionScoreboard(ctx, s, *session, nil)
+		if err != nil {
+			common.Logf("[sf6][session] scoreboard post failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+			common.FollowupEphemeral(s, i, label+"\nスコアボードの投稿に失敗しました: "+err.Error())
+			return
+		}
+		if err := r.SF6SessionService.AttachMessage(ctx, session.ID, channelID, messageID); err != nil {
+			common.Logf("[sf6][session] scoreboard attach failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+		}
+		common.FollowupEphemeral(s, i, label+"\nスコアボードを投稿しました（対戦は自動で反映されます）")
 	case "end":
 		endedAt := time.Now().UTC()
 		session, err := r.SF6SessionService.End(ctx, i.GuildID, userID, opponentCode, endedAt)
@@ -93,21 +109,187 @@ func (r *Handler) handleSF6Session(s *discordgo.Session, i *discordgo.Interactio
 				fetchUserID = owner.UserID
 			}
 		}
-		if _, _, err := r.initialFetch(ctx, i.GuildID, fetchUserID, subjectSID); err != nil {
-			common.FollowupEphemeral(s, i, "対戦記録の取得に失敗: "+err.Error())
+		if _, _, err := r.initialFetch(ctx, domain.SF6FetchTriggerSession, i.GuildID, fetchUserID, subjectSID); err != nil {
+			common.FollowupEphemeral(s, i, "対戦記録の取得に失敗: "+formatSF6FetchError(err))
 			return
 		}
-		endExclusive := endedAt.Add(time.Nanosecond)
-		stats, err := r.SF6Service.StatsByOpponentRange(ctx, i.GuildID, subjectSID, opponentCode, session.StartedAt, endExclusive)
+		if _, err := r.SF6Service.AssignSessions(ctx, i.GuildID, subjectSID); err != nil {
+			common.Logf("[sf6][session] assign sessions failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+		}
+		embed, files, err := r.buildSF6SessionStatsEmbed(ctx, s, *session, subjectSID)
 		if err != nil {
 			common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
 			return
 		}
-		label := fmt.Sprintf("セッション: %s〜%s (JST)", formatJST(session.StartedAt), formatJST(endedAt))
-		subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, i.GuildID, subjectSID, opponentCode)
-		embed := buildStatsEmbed("SF6 Stats (Session)", label, subjectUser, opponentUser, stats)
-		common.FollowupPublicEmbed(s, i, "", embed, nil)
+		if session.ChannelID != "" {
+			battles, err := r.SF6Service.HistoryBySession(ctx, session.ID)
+			if err != nil {
+				common.Logf("[sf6][session] scoreboard history failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+			} else if _, _, err := r.updateSessionScoreboard(ctx, s, *session, battles); err != nil {
+				common.Logf("[sf6][session] scoreboard final update failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
+			}
+		}
+		common.FollowupPublicEmbed(s, i, "", embed, nil, files...)
 	default:
 		common.FollowupEphemeral(s, i, "不明なサブコマンドです")
 	}
 }
+
+const sf6SessionListLimit = 25
+
+func (r *Handler) handleSF6SessionList(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID, opponentCode string) {
+	if opponentCode != "" {
+		if sid, _, ok, err := r.resolveSIDFromMention(ctx, i.GuildID, opponentCode); ok {
+			if err != nil {
+				common.FollowupEphemeral(s, i, err.Error())
+				return
+			}
+			opponentCode = sid
+		}
+	}
+	sessions, err := r.SF6SessionService.ListRecent(ctx, i.GuildID, userID, opponentCode, sf6SessionListLimit)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "一覧取得に失敗: "+err.Error())
+		return
+	}
+	if len(sessions) == 0 {
+		common.FollowupEphemeral(s, i, "セッションがありません")
+		return
+	}
+	options := make([]discordgo.SelectMenuOption, 0, len(sessions))
+	for _, session := range sessions {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       formatSF6SessionPeriod(session),
+			Value:       session.ID,
+			Description: formatSF6SessionDescription(session),
+		})
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "SF6 Sessions",
+		Description: fmt.Sprintf("直近 %d 件のセッション。選択すると統計を表示します。", len(sessions)),
+		Color:       0x2b6cb0,
+	}
+	minValues := 1
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "sf6_session_list:" + userID,
+					Placeholder: "セッションを選択",
+					MinValues:   &minValues,
+					MaxValues:   1,
+					Options:     options,
+				},
+			},
+		},
+	}
+	common.FollowupEphemeralEmbed(s, i, embed, components)
+}
+
+func (r *Handler) handleSF6SessionListComponent(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	ownerID, ok := common.ParseOwnedCustomID(customID, "sf6_session_list")
+	if !ok {
+		common.RespondEphemeral(s, i, "不正な操作です")
+		return
+	}
+	if ownerID != "" && common.InteractionUserID(i) != ownerID {
+		common.RespondEphemeral(s, i, "この操作は発行者のみ実行できます")
+		return
+	}
+	if r.SF6SessionService == nil || r.SF6Service == nil {
+		common.RespondEphemeral(s, i, "sf6機能が無効です（Buckler設定未完）")
+		return
+	}
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		common.RespondEphemeral(s, i, "セッションを選択してください")
+		return
+	}
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+	ctx, cancel := common.CommandContextForInteraction(s, i)
+	defer cancel()
+	session, err := r.SF6SessionService.GetByID(ctx, values[0])
+	if err != nil {
+		common.FollowupEphemeral(s, i, "セッション取得に失敗: "+err.Error())
+		return
+	}
+	if session == nil || session.GuildID != i.GuildID {
+		common.FollowupEphemeral(s, i, "セッションが見つかりません")
+		return
+	}
+	subjectSID := session.SubjectFighterID
+	if subjectSID == "" {
+		subjectSID, err = r.resolveSubjectSID(ctx, i.GuildID, session.UserID, "")
+		if err != nil {
+			common.FollowupEphemeral(s, i, err.Error())
+			return
+		}
+	}
+	embed, files, err := r.buildSF6SessionStatsEmbed(ctx, s, *session, subjectSID)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	common.FollowupPublicEmbed(s, i, "", embed, nil, files...)
+}
+
+// buildSF6SessionStatsEmbed はセッションに紐づく対戦を集計する。
+// subject が記録されていない旧セッションは期間で集計する。
+func (r *Handler) buildSF6SessionStatsEmbed(ctx context.Context, s *discordgo.Session, session domain.SF6Session, subjectSID string) (*discordgo.MessageEmbed, []*discordgo.File, error) {
+	var (
+		stats      []domain.SF6BattleStatRow
+		momentum   domain.SF6Momentum
+		roundStats domain.SF6RoundStats
+		err        error
+	)
+	if session.SubjectFighterID != "" {
+		stats, err = r.SF6Service.StatsBySession(ctx, session.ID)
+		if err == nil {
+			momentum, err = r.SF6Service.MomentumBySession(ctx, session.ID)
+		}
+		if err == nil {
+			roundStats, err = r.SF6Service.RoundStatsBySession(ctx, session.ID)
+		}
+	} else {
+		endAt := time.Now().UTC()
+		if session.EndedAt != nil {
+			endAt = *session.EndedAt
+		}
+		endExclusive := endAt.Add(time.Nanosecond)
+		stats, err = r.SF6Service.StatsByOpponentRange(ctx, session.GuildID, subjectSID, session.OpponentFighterID, "", session.StartedAt, endExclusive)
+		if err == nil {
+			momentum, err = r.SF6Service.MomentumByOpponentRange(ctx, session.GuildID, subjectSID, session.OpponentFighterID, "", session.StartedAt, endExclusive)
+		}
+		if err == nil {
+			roundStats, err = r.SF6Service.RoundStatsByOpponentRange(ctx, session.GuildID, subjectSID, session.OpponentFighterID, "", session.StartedAt, endExclusive)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	label := "セッション: " + formatSF6SessionPeriod(session) + " (JST)"
+	subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, session.GuildID, subjectSID, session.OpponentFighterID)
+	embed := buildStatsEmbed("SF6 Stats (Session)", label, subjectUser, opponentUser, stats)
+	appendMomentumFields(embed, momentum)
+	appendRoundFields(embed, roundStats)
+	files := attachStatsChart(embed, statsChartPanels(stats, &momentum)...)
+	return embed, files, nil
+}
+
+func formatSF6SessionPeriod(session domain.SF6Session) string {
+	if session.EndedAt == nil {
+		return formatJST(session.StartedAt) + "〜"
+	}
+	return formatJST(session.StartedAt) + "〜" + formatJST(*session.EndedAt)
+}
+
+func formatSF6SessionDescription(session domain.SF6Session) string {
+	status := "終了"
+	if session.Status == "active" {
+		status = "進行中"
+	}
+	return "vs " + session.OpponentFighterID + " • " + status
+}
@@ -0,0 +1,206 @@
+package sf6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const sf6ScoreboardRecentLimit = 10
+
+type sessionScoreboardNotifier struct {
+	handler *Handler
+	dg      *discordgo.Session
+}
+
+// SessionScoreboardNotifier はセッション監視からスコアボードを更新するための notifier を返す。
+func (h *Handler) SessionScoreboardNotifier(dg *discordgo.Session) service.SF6SessionNotifier {
+	return &sessionScoreboardNotifier{handler: h, dg: dg}
+}
+
+func (n *sessionScoreboardNotifier) UpdateSessionScoreboard(ctx context.Context, session domain.SF6Session, battles []domain.SF6BattleHistoryRow) (string, string, error) {
+	return n.handler.updateSessionScoreboard(ctx, n.dg, session, battles)
+}
+
+// updateSessionScoreboard はスコアボードを編集する。メッセージが消されていた場合のみ投稿し直す。
+func (r *Handler) updateSessionScoreboard(ctx context.Context, s *discordgo.Session, session domain.SF6Session, battles []domain.SF6BattleHistoryRow) (string, string, error) {
+	if s == nil || session.ChannelID == "" {
+		return "", "", errors.New("scoreboard channel is not set")
+	}
+	embed := r.buildSessionScoreboardEmbed(ctx, s, session, battles, time.Now().UTC())
+	if session.MessageID != "" {
+		_, err := s.ChannelMessageEditEmbed(session.ChannelID, session.MessageID, embed)
+		if err == nil {
+			return session.ChannelID, session.MessageID, nil
+		}
+		var restErr *discordgo.RESTError
+		if !errors.As(err, &restErr) || restErr.Response == nil || restErr.Response.StatusCode != http.StatusNotFound {
+			return "", "", err
+		}
+		common.Logf("[sf6][session] scoreboard message missing, repost: session=%s", session.ID)
+	}
+	msg, err := s.ChannelMessageSendEmbed(session.ChannelID, embed)
+	if err != nil {
+		return "", "", err
+	}
+	return msg.ChannelID, msg.ID, nil
+}
+
+func (r *Handler) buildSessionScoreboardEmbed(ctx context.Context, s *discordgo.Session, session domain.SF6Session, battles []domain.SF6BattleHistoryRow, now time.Time) *discordgo.MessageEmbed {
+	totals, byChar := summarizeStats(historyRowsToStatRows(battles))
+	momentum := service.SummarizeSF6Momentum(historyRowsToResults(battles), service.SF6MomentumWindow)
+
+	title := "SF6 Live Session"
+	color := 0x2ecc71
+	status := fmt.Sprintf("監視中（最終更新: %s JST）", formatJST(now))
+	if session.Status == "ended" {
+		title = "SF6 Session (Ended)"
+		color = 0x95a5a6
+		endedAt := now
+		if session.EndedAt != nil {
+			endedAt = *session.EndedAt
+		}
+		status = fmt.Sprintf("終了: %s JST", formatJST(endedAt))
+	}
+
+	subject := r.buildScoreboardUser(ctx, s, session.GuildID, session.SubjectFighterID)
+	opponent := r.buildScoreboardUser(ctx, s, session.GuildID, session.OpponentFighterID)
+	players := fmt.Sprintf("subject: %s\nopponent: %s", formatStatsUserLine(subject), formatStatsUserLine(opponent))
+
+	charLines := formatCharStatsTable(byChar)
+	if charLines == "" {
+		charLines = "no data"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: fmt.Sprintf("開始: %s JST\n%s", formatJST(session.StartedAt), status),
+		Color:       color,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Players",
+				Value:  players,
+				Inline: false,
+			},
+			{
+				Name:   "Score (W-L-D)",
+				Value:  fmt.Sprintf("**%d - %d - %d**", totals.Wins, totals.Losses, totals.Draws),
+				Inline: true,
+			},
+			{
+				Name:   "Win Rate (no draws)",
+				Value:  fmt.Sprintf("**%s**", calcWinRate(totals)),
+				Inline: true,
+			},
+			{
+				Name:   "Streak",
+				Value:  formatSessionStreak(momentum),
+				Inline: true,
+			},
+			{
+				Name:   "Recent",
+				Value:  formatSessionRecent(battles, sf6ScoreboardRecentLimit),
+				Inline: false,
+			},
+			{
+				Name:   "By Character",
+				Value:  charLines,
+				Inline: false,
+			},
+		},
+	}
+	applyStatsIcons(embed, subject, opponent)
+	return embed
+}
+
+// buildScoreboardUser はポーリングごとに呼ばれるため、Buckler への問い合わせ（カード取得）は行わない。
+func (r *Handler) buildScoreboardUser(ctx context.Context, s *discordgo.Session, guildID, fighterID string) statsEmbedUser {
+	info := statsEmbedUser{SID: fighterID}
+	if r == nil || r.SF6AccountService == nil || fighterID == "" {
+		return info
+	}
+	account, err := r.SF6AccountService.GetByFighter(ctx, guildID, fighterID)
+	if err != nil || account == nil || account.UserID == "" {
+		return info
+	}
+	info.UserID = account.UserID
+	info.Mention = "<@" + account.UserID + ">"
+	if s != nil {
+		if user, err := s.User(account.UserID); err == nil && user != nil {
+			info.IconURL = user.AvatarURL("")
+		}
+	}
+	return info
+}
+
+func historyRowsToStatRows(rows []domain.SF6BattleHistoryRow) []domain.SF6BattleStatRow {
+	type key struct {
+		Char   string
+		Result string
+	}
+	counts := make(map[key]int)
+	order := make([]key, 0)
+	for _, row := range rows {
+		k := key{Char: row.SelfCharacter, Result: row.Result}
+		if _, ok := counts[k]; !ok {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+	out := make([]domain.SF6BattleStatRow, 0, len(order))
+	for _, k := range order {
+		out = append(out, domain.SF6BattleStatRow{SelfCharacter: k.Char, Result: k.Result, Count: counts[k]})
+	}
+	return out
+}
+
+// historyRowsToResults は古い順の対戦を勝敗の列にする。
+func historyRowsToResults(rows []domain.SF6BattleHistoryRow) []domain.SF6BattleResult {
+	out := make([]domain.SF6BattleResult, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.SF6BattleResult{BattleAt: row.BattleAt, Result: row.Result})
+	}
+	return out
+}
+
+func formatSessionStreak(m domain.SF6Momentum) string {
+	current := "-"
+	switch {
+	case m.CurrentStreak > 0:
+		current = fmt.Sprintf("🔥 **%d連勝**", m.CurrentStreak)
+	case m.CurrentStreak < 0:
+		current = fmt.Sprintf("**%d連敗**", -m.CurrentStreak)
+	}
+	return fmt.Sprintf("%s\n最長: %d連勝 / %d連敗", current, m.LongestWinStreak, m.LongestLossStreak)
+}
+
+func formatSessionRecent(rows []domain.SF6BattleHistoryRow, limit int) string {
+	if len(rows) == 0 {
+		return "まだ対戦がありません"
+	}
+	start := 0
+	if len(rows) > limit {
+		start = len(rows) - limit
+	}
+	var b strings.Builder
+	for _, row := range rows[start:] {
+		switch row.Result {
+		case "win":
+			b.WriteString("🟩")
+		case "loss":
+			b.WriteString("🟥")
+		default:
+			b.WriteString("🟨")
+		}
+	}
+	return b.String() + " (古→新)"
+}
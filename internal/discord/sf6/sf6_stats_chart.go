@@ -0,0 +1,134 @@
+package sf6
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"backend/internal/chart"
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const sf6StatsChartFileName = "sf6_stats.png"
+
+// キャラ別の棒グラフは表（formatCharStatsTable）と同じ数まで。
+const sf6StatsChartMaxChars = 8
+
+// セットの並びは直近のセットまでの 20 セット分。
+const sf6StatsChartMaxSets = 20
+
+// attachStatsChart は panels を PNG に描いて embed の画像にし、添付するファイルを返す。
+// 描くものが無いか描画に失敗したときは画像なしの embed のままにする。
+func attachStatsChart(embed *discordgo.MessageEmbed, panels ...chart.Panel) []*discordgo.File {
+	if embed == nil || len(panels) == 0 {
+		return nil
+	}
+	data, err := chart.Render(chart.DefaultWidth, panels...)
+	if err != nil {
+		common.Logf("[sf6][chart] render failed: %v", err)
+		return nil
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + sf6StatsChartFileName}
+	return []*discordgo.File{{
+		Name:        sf6StatsChartFileName,
+		ContentType: "image/png",
+		Reader:      bytes.NewReader(data),
+	}}
+}
+
+// rewindStatsChart は送信に失敗した添付を、別のメッセージで送り直せるよう先頭に戻す。
+func rewindStatsChart(files []*discordgo.File) {
+	for _, file := range files {
+		if seeker, ok := file.Reader.(io.Seeker); ok {
+			_, _ = seeker.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+// statsChartPanels は勝率推移の折れ線とキャラ別勝率の棒グラフを作る。
+func statsChartPanels(rows []domain.SF6BattleStatRow, momentum *domain.SF6Momentum) []chart.Panel {
+	var panels []chart.Panel
+	if momentum != nil && len(momentum.RollingWinRates) >= 2 {
+		panels = append(panels, chart.Line{
+			Title:  fmt.Sprintf("Win Rate Trend (last %d)", momentum.Window),
+			Values: momentum.RollingWinRates,
+			Min:    0,
+			Max:    1,
+			Guides: []float64{0, 0.5, 1},
+			Format: func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+		})
+	}
+	if bars := charWinRateBars(rows); len(bars) > 0 {
+		panels = append(panels, chart.Bars{Title: "Win Rate by Character", Bars: bars, Guide: 0.5})
+	}
+	return panels
+}
+
+func charWinRateBars(rows []domain.SF6BattleStatRow) []chart.Bar {
+	_, byChar := summarizeStats(rows)
+	chars := make([]string, 0, len(byChar))
+	for char := range byChar {
+		chars = append(chars, char)
+	}
+	sort.Slice(chars, func(a, b int) bool {
+		if byChar[chars[a]].Total != byChar[chars[b]].Total {
+			return byChar[chars[a]].Total > byChar[chars[b]].Total
+		}
+		return chars[a] < chars[b]
+	})
+	if len(chars) > sf6StatsChartMaxChars {
+		chars = chars[:sf6StatsChartMaxChars]
+	}
+	bars := make([]chart.Bar, 0, len(chars))
+	for _, char := range chars {
+		stat := byChar[char]
+		rate := 0.0
+		if decided := stat.Wins + stat.Losses; decided > 0 {
+			rate = float64(stat.Wins) / float64(decided)
+		}
+		bars = append(bars, chart.Bar{
+			Label: char,
+			Value: rate,
+			Text:  fmt.Sprintf("%s %dG", calcWinRate(stat), stat.Total),
+			Tone:  winRateTone(stat.Wins, stat.Losses),
+		})
+	}
+	return bars
+}
+
+// setStripPanel は groups（新しい順）のうち page 番目のセットまでの直近のセットを、古い順に並べる。
+func setStripPanel(groups []statsSetGroup, page int) chart.Panel {
+	if page <= 0 || page > len(groups) {
+		return nil
+	}
+	last := min(page-1+sf6StatsChartMaxSets, len(groups))
+	cells := make([]chart.Cell, 0, last-page+1)
+	for idx := last - 1; idx >= page-1; idx-- {
+		group := groups[idx]
+		cells = append(cells, chart.Cell{
+			Label: fmt.Sprintf("%d-%d", group.Wins, group.Losses),
+			Tone:  winRateTone(group.Wins, group.Losses),
+		})
+	}
+	// セット番号は古い順に 1 から振る
+	newest := len(groups) - page + 1
+	return chart.Strip{
+		Title: fmt.Sprintf("Sets #%d-#%d (W-L, right = this set)", newest-len(cells)+1, newest),
+		Cells: cells,
+	}
+}
+
+func winRateTone(wins, losses int) chart.Tone {
+	switch {
+	case wins > losses:
+		return chart.ToneWin
+	case wins < losses:
+		return chart.ToneLoss
+	default:
+		return chart.ToneNeutral
+	}
+}
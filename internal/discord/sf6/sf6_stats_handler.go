@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"backend/internal/discord/common"
+	"backend/internal/domain"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -41,13 +42,15 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 	switch sub.Name {
 	case "range":
 		opts := sub.Options
-		var opponentCode, subjectCode, fromStr, toStr string
+		var opponentCode, subjectCode, fromStr, toStr, battleType string
 		for _, opt := range opts {
 			switch opt.Name {
 			case "opponent_code":
 				opponentCode = opt.StringValue()
 			case "subject_code":
 				subjectCode = opt.StringValue()
+			case "mode":
+				battleType = parseSF6Mode(opt.StringValue())
 			case "from":
 				fromStr = opt.StringValue()
 			case "to":
@@ -58,8 +61,7 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 			common.RespondEphemeral(s, i, "opponent_code/from/to が必要です")
 			return
 		}
-		startAt, endAt, err := parseDateRangeJST(fromStr, toStr)
-		if err != nil {
+		if _, _, err := parseDateRangeJST(fromStr, toStr); err != nil {
 			common.RespondEphemeral(s, i, "日付形式エラー: "+err.Error())
 			return
 		}
@@ -80,21 +82,18 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 			return
 		}
 		if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
-			common.FollowupEphemeral(s, i, "最新取得に失敗: "+err.Error())
+			common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
 			return
 		}
-		stats, err := r.SF6Service.StatsByOpponentRange(ctx, i.GuildID, subjectSID, opponentCode, startAt, endAt)
+		embed, files, err := r.buildSF6StatsRangeEmbed(ctx, s, i.GuildID, subjectSID, opponentCode, battleType, fromStr, toStr)
 		if err != nil {
 			common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
 			return
 		}
-		label := fmt.Sprintf("期間: %s〜%s (JST)", fromStr, toStr)
-		subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, i.GuildID, subjectSID, opponentCode)
-		embed := buildStatsEmbed("SF6 Stats (Range)", label, subjectUser, opponentUser, stats)
-		common.FollowupPublicEmbed(s, i, "", embed, nil)
+		common.FollowupPublicEmbed(s, i, "", embed, nil, files...)
 	case "count":
 		opts := sub.Options
-		var opponentCode, subjectCode string
+		var opponentCode, subjectCode, battleType string
 		count := 0
 		for _, opt := range opts {
 			switch opt.Name {
@@ -102,6 +101,8 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 				opponentCode = opt.StringValue()
 			case "subject_code":
 				subjectCode = opt.StringValue()
+			case "mode":
+				battleType = parseSF6Mode(opt.StringValue())
 			case "count":
 				count = int(opt.IntValue())
 			}
@@ -127,34 +128,33 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 			return
 		}
 		if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
-			common.FollowupEphemeral(s, i, "最新取得に失敗: "+err.Error())
+			common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
 			return
 		}
-		stats, err := r.SF6Service.StatsByOpponentCount(ctx, i.GuildID, subjectSID, opponentCode, count)
+		embed, files, err := r.buildSF6StatsCountEmbed(ctx, s, i.GuildID, subjectSID, opponentCode, battleType, count)
 		if err != nil {
 			common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
 			return
 		}
-		label := fmt.Sprintf("直近 %d 戦", count)
-		subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, i.GuildID, subjectSID, opponentCode)
-		embed := buildStatsEmbed("SF6 Stats (Count)", label, subjectUser, opponentUser, stats)
-		common.FollowupPublicEmbed(s, i, "", embed, nil)
+		common.FollowupPublicEmbed(s, i, "", embed, nil, files...)
 	case "set":
 		opts := sub.Options
-		var opponentCode, subjectCode string
+		var opponentCode, subjectCode, battleType string
 		for _, opt := range opts {
 			switch opt.Name {
 			case "opponent_code":
 				opponentCode = opt.StringValue()
 			case "subject_code":
 				subjectCode = opt.StringValue()
+			case "mode":
+				battleType = parseSF6Mode(opt.StringValue())
 			}
 		}
 		if opponentCode == "" {
 			common.RespondEphemeral(s, i, "opponent_code が必要です")
 			return
 		}
-		common.Logf("[sf6][stats-set] params guild=%s user=%s subject=%s opponent=%s", i.GuildID, userID, subjectCode, opponentCode)
+		common.Logf("[sf6][stats-set] params guild=%s user=%s subject=%s opponent=%s mode=%s", i.GuildID, userID, subjectCode, opponentCode, battleType)
 		if err := common.DeferPublic(s, i); err != nil {
 			common.RespondEphemeral(s, i, "受付に失敗しました")
 			return
@@ -174,27 +174,100 @@ func (r *Handler) handleSF6Stats(s *discordgo.Session, i *discordgo.InteractionC
 		}
 		if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
 			_ = common.EditInteractionResponse(s, i, "最新取得に失敗しました", nil, nil)
-			common.FollowupEphemeral(s, i, "最新取得に失敗: "+err.Error())
+			common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
 			return
 		}
 		common.Logf("[sf6][stats-set] fetch ok guild=%s user=%s subject=%s opponent=%s", i.GuildID, userID, subjectSID, opponentCode)
-		embed, components, err := r.buildSF6StatsSetEmbed(ctx, s, i.GuildID, userID, subjectSID, opponentCode, 1)
+		embed, components, files, err := r.buildSF6StatsSetEmbed(ctx, s, i.GuildID, userID, subjectSID, opponentCode, battleType, 1)
 		if err != nil {
 			_ = common.EditInteractionResponse(s, i, "集計に失敗しました", nil, nil)
 			common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
 			return
 		}
-		if err := common.EditInteractionResponse(s, i, "", embed, components); err != nil {
+		if err := common.EditInteractionResponse(s, i, "", embed, components, files...); err != nil {
 			common.Logf("[sf6][stats-set] edit response failed: guild=%s user=%s err=%v", i.GuildID, userID, err)
-			common.FollowupPublicEmbed(s, i, "", embed, components)
+			rewindStatsChart(files)
+			common.FollowupPublicEmbed(s, i, "", embed, components, files...)
 		} else {
 			common.Logf("[sf6][stats-set] response updated guild=%s user=%s", i.GuildID, userID)
 		}
+	case "matchup":
+		r.handleSF6StatsMatchup(ctx, s, i, userID, sub)
 	default:
 		common.RespondEphemeral(s, i, "不明なサブコマンドです")
 	}
 }
 
+// buildSF6StatsRangeEmbed は期間指定（JST）の統計 embed と、添付するグラフを作る。
+func (r *Handler) buildSF6StatsRangeEmbed(ctx context.Context, s *discordgo.Session, guildID, subjectSID, opponentSID, battleType, fromStr, toStr string) (*discordgo.MessageEmbed, []*discordgo.File, error) {
+	startAt, endAt, err := parseDateRangeJST(fromStr, toStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats, err := r.SF6Service.StatsByOpponentRange(ctx, guildID, subjectSID, opponentSID, battleType, startAt, endAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	momentum, err := r.SF6Service.MomentumByOpponentRange(ctx, guildID, subjectSID, opponentSID, battleType, startAt, endAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	roundStats, err := r.SF6Service.RoundStatsByOpponentRange(ctx, guildID, subjectSID, opponentSID, battleType, startAt, endAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, guildID, subjectSID, opponentSID)
+	embed := buildStatsEmbed(sf6StatsRangeTitle, sf6StatsRangeLabel(fromStr, toStr, battleType), subjectUser, opponentUser, stats)
+	appendMomentumFields(embed, momentum)
+	appendRoundFields(embed, roundStats)
+	files := attachStatsChart(embed, statsChartPanels(stats, &momentum)...)
+	return embed, files, nil
+}
+
+// 統計 embed のタイトル。Refresh ではタイトルから range / count / set を見分ける。
+const (
+	sf6StatsRangeTitle = "SF6 Stats (Range)"
+	sf6StatsCountTitle = "SF6 Stats (Count)"
+	sf6StatsSetTitle   = "SF6 Stats (Set)"
+)
+
+// sf6StatsRangeLabel は期間指定の embed の説明文。parseSF6StatsEmbedQuery で読み戻す。
+func sf6StatsRangeLabel(fromStr, toStr, battleType string) string {
+	return appendSF6ModeLabel(fmt.Sprintf("期間: %s〜%s (JST)", fromStr, toStr), battleType)
+}
+
+// sf6StatsCountLabel は試合数指定の embed の説明文。parseSF6StatsEmbedQuery で読み戻す。
+func sf6StatsCountLabel(count int, battleType string) string {
+	return appendSF6ModeLabel(fmt.Sprintf("直近 %d 戦", count), battleType)
+}
+
+// sf6StatsSetFooter はセット表示のページ番号。parseSF6StatsEmbedQuery で読み戻す。
+func sf6StatsSetFooter(page, totalPages int) *discordgo.MessageEmbedFooter {
+	return &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Set %d/%d • gap<=30m", page, totalPages)}
+}
+
+// buildSF6StatsCountEmbed は直近 count 戦の統計 embed と、添付するグラフを作る。
+func (r *Handler) buildSF6StatsCountEmbed(ctx context.Context, s *discordgo.Session, guildID, subjectSID, opponentSID, battleType string, count int) (*discordgo.MessageEmbed, []*discordgo.File, error) {
+	stats, err := r.SF6Service.StatsByOpponentCount(ctx, guildID, subjectSID, opponentSID, battleType, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	momentum, err := r.SF6Service.MomentumByOpponentCount(ctx, guildID, subjectSID, opponentSID, battleType, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	roundStats, err := r.SF6Service.RoundStatsByOpponentCount(ctx, guildID, subjectSID, opponentSID, battleType, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, guildID, subjectSID, opponentSID)
+	embed := buildStatsEmbed(sf6StatsCountTitle, sf6StatsCountLabel(count, battleType), subjectUser, opponentUser, stats)
+	appendMomentumFields(embed, momentum)
+	appendRoundFields(embed, roundStats)
+	files := attachStatsChart(embed, statsChartPanels(stats, &momentum)...)
+	return embed, files, nil
+}
+
 func parseDateRangeJST(fromStr, toStr string) (time.Time, time.Time, error) {
 	loc, err := time.LoadLocation("Asia/Tokyo")
 	if err != nil {
@@ -283,12 +356,12 @@ func (r *Handler) fetchLatestForStats(ctx context.Context, guildID, requestUserI
 			fetchUserID = owner.UserID
 		}
 	}
-	_, _, err := r.initialFetch(ctx, guildID, fetchUserID, subjectSID)
+	_, _, err := r.initialFetch(ctx, domain.SF6FetchTriggerStats, guildID, fetchUserID, subjectSID)
 	return err
 }
 
 func (r *Handler) handleSF6StatsSetComponent(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
-	ownerID, subjectSID, opponentSID, page, ok := parseSF6StatsSetCustomID(customID)
+	ownerID, subjectSID, opponentSID, battleType, page, ok := parseSF6StatsSetCustomID(customID)
 	if !ok {
 		common.RespondEphemeral(s, i, "不正な操作です")
 		return
@@ -299,7 +372,7 @@ func (r *Handler) handleSF6StatsSetComponent(s *discordgo.Session, i *discordgo.
 	}
 	ctx, cancel := common.CommandContextForInteraction(s, i)
 	defer cancel()
-	embed, components, err := r.buildSF6StatsSetEmbed(ctx, s, i.GuildID, ownerID, subjectSID, opponentSID, page)
+	embed, components, files, err := r.buildSF6StatsSetEmbed(ctx, s, i.GuildID, ownerID, subjectSID, opponentSID, battleType, page)
 	if err != nil {
 		common.RespondEphemeral(s, i, "集計に失敗: "+err.Error())
 		return
@@ -309,27 +382,33 @@ func (r *Handler) handleSF6StatsSetComponent(s *discordgo.Session, i *discordgo.
 		Data: &discordgo.InteractionResponseData{
 			Embeds:     []*discordgo.MessageEmbed{embed},
 			Components: components,
+			Files:      files,
+			// 前のページのグラフは残さず、今回の添付に差し替える
+			Attachments: &[]*discordgo.MessageAttachment{},
 		},
 	})
 }
 
 type statsSetGroup struct {
-	Start time.Time
-	End   time.Time
-	Count int
+	Start  time.Time
+	End    time.Time
+	Count  int
+	Wins   int
+	Losses int
+	Draws  int
 }
 
-func (r *Handler) buildSF6StatsSetEmbed(ctx context.Context, s *discordgo.Session, guildID, ownerID, subjectSID, opponentSID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+func (r *Handler) buildSF6StatsSetEmbed(ctx context.Context, s *discordgo.Session, guildID, ownerID, subjectSID, opponentSID, battleType string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, []*discordgo.File, error) {
 	if page <= 0 {
 		page = 1
 	}
-	times, err := r.SF6Service.BattleTimesByOpponent(ctx, guildID, subjectSID, opponentSID)
+	results, err := r.SF6Service.ResultsByOpponent(ctx, guildID, subjectSID, opponentSID, battleType)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	groups := groupStatsSet(times, 30*time.Minute)
+	groups := groupStatsSet(results, 30*time.Minute)
 	if len(groups) == 0 {
-		return nil, nil, fmt.Errorf("該当データなし")
+		return nil, nil, nil, fmt.Errorf("該当データなし")
 	}
 	totalPages := len(groups)
 	if page > totalPages {
@@ -337,61 +416,70 @@ func (r *Handler) buildSF6StatsSetEmbed(ctx context.Context, s *discordgo.Sessio
 	}
 	group := groups[page-1]
 	endExclusive := group.End.Add(time.Nanosecond)
-	stats, err := r.SF6Service.StatsByOpponentRange(ctx, guildID, subjectSID, opponentSID, group.Start, endExclusive)
+	stats, err := r.SF6Service.StatsByOpponentRange(ctx, guildID, subjectSID, opponentSID, battleType, group.Start, endExclusive)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	label := fmt.Sprintf("期間: %s〜%s (JST) / %d戦", formatJST(group.Start), formatJST(group.End), group.Count)
+	label := appendSF6ModeLabel(fmt.Sprintf("期間: %s〜%s (JST) / %d戦", formatJST(group.Start), formatJST(group.End), group.Count), battleType)
 	subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, guildID, subjectSID, opponentSID)
-	embed := buildStatsEmbed("SF6 Stats (Set)", label, subjectUser, opponentUser, stats)
-	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text: fmt.Sprintf("Set %d/%d • gap<=30m", page, totalPages),
-	}
-	components := buildSF6StatsSetButtons(ownerID, subjectSID, opponentSID, page, totalPages)
-	return embed, components, nil
+	embed := buildStatsEmbed(sf6StatsSetTitle, label, subjectUser, opponentUser, stats)
+	embed.Footer = sf6StatsSetFooter(page, totalPages)
+	components := buildSF6StatsSetButtons(ownerID, subjectSID, opponentSID, battleType, page, totalPages)
+	panels := statsChartPanels(stats, nil)
+	if strip := setStripPanel(groups, page); strip != nil {
+		panels = append(panels, strip)
+	}
+	files := attachStatsChart(embed, panels...)
+	return embed, components, files, nil
 }
 
-func groupStatsSet(times []time.Time, gap time.Duration) []statsSetGroup {
-	if len(times) == 0 {
+// groupStatsSet は新しい順の results を、間隔が gap 以内の連戦ごとのセットにまとめる（新しい順）。
+func groupStatsSet(results []domain.SF6BattleResult, gap time.Duration) []statsSetGroup {
+	if len(results) == 0 {
 		return nil
 	}
-	groups := make([]statsSetGroup, 0, len(times))
+	groups := make([]statsSetGroup, 0, len(results))
 	current := statsSetGroup{
-		Start: times[0],
-		End:   times[0],
-		Count: 1,
-	}
-	prev := times[0]
-	for i := 1; i < len(times); i++ {
-		t := times[i]
-		if prev.Sub(t) <= gap {
-			current.Start = t
-			current.Count++
-		} else {
+		Start: results[0].BattleAt,
+		End:   results[0].BattleAt,
+	}
+	prev := results[0].BattleAt
+	for _, result := range results {
+		t := result.BattleAt
+		if prev.Sub(t) > gap {
 			groups = append(groups, current)
 			current = statsSetGroup{
 				Start: t,
 				End:   t,
-				Count: 1,
 			}
 		}
+		current.Start = t
+		current.Count++
+		switch result.Result {
+		case "win":
+			current.Wins++
+		case "loss":
+			current.Losses++
+		case "draw":
+			current.Draws++
+		}
 		prev = t
 	}
 	groups = append(groups, current)
 	return groups
 }
 
-func buildSF6StatsSetButtons(ownerID, subjectSID, opponentSID string, page, totalPages int) []discordgo.MessageComponent {
+func buildSF6StatsSetButtons(ownerID, subjectSID, opponentSID, battleType string, page, totalPages int) []discordgo.MessageComponent {
 	firstPage := 1
 	lastPage := totalPages
 	prevPage := page - 1
 	nextPage := page + 1
 	prevDisabled := page <= 1
 	nextDisabled := page >= totalPages
-	firstID := buildSF6StatsSetCustomIDWithAction("first", ownerID, subjectSID, opponentSID, firstPage)
-	prevID := buildSF6StatsSetCustomIDWithAction("prev", ownerID, subjectSID, opponentSID, maxInt(prevPage, 1))
-	nextID := buildSF6StatsSetCustomIDWithAction("next", ownerID, subjectSID, opponentSID, minInt(nextPage, totalPages))
-	lastID := buildSF6StatsSetCustomIDWithAction("last", ownerID, subjectSID, opponentSID, lastPage)
+	firstID := buildSF6StatsSetCustomIDWithAction("first", ownerID, subjectSID, opponentSID, battleType, firstPage)
+	prevID := buildSF6StatsSetCustomIDWithAction("prev", ownerID, subjectSID, opponentSID, battleType, maxInt(prevPage, 1))
+	nextID := buildSF6StatsSetCustomIDWithAction("next", ownerID, subjectSID, opponentSID, battleType, minInt(nextPage, totalPages))
+	lastID := buildSF6StatsSetCustomIDWithAction("last", ownerID, subjectSID, opponentSID, battleType, lastPage)
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
@@ -424,35 +512,39 @@ func buildSF6StatsSetButtons(ownerID, subjectSID, opponentSID string, page, tota
 	}
 }
 
-func buildSF6StatsSetCustomID(ownerID, subjectSID, opponentSID string, page int) string {
-	return buildSF6StatsSetCustomIDWithAction("page", ownerID, subjectSID, opponentSID, page)
+func buildSF6StatsSetCustomID(ownerID, subjectSID, opponentSID, battleType string, page int) string {
+	return buildSF6StatsSetCustomIDWithAction("page", ownerID, subjectSID, opponentSID, battleType, page)
 }
 
-func buildSF6StatsSetCustomIDWithAction(action, ownerID, subjectSID, opponentSID string, page int) string {
+func buildSF6StatsSetCustomIDWithAction(action, ownerID, subjectSID, opponentSID, battleType string, page int) string {
 	if page <= 0 {
 		page = 1
 	}
-	return "sf6_stats_set_page:" + action + ":" + ownerID + ":" + subjectSID + ":" + opponentSID + ":" + strconv.Itoa(page)
+	return "sf6_stats_set_page:" + action + ":" + ownerID + ":" + subjectSID + ":" + opponentSID + ":" + strconv.Itoa(page) + ":" + battleType
 }
 
-func parseSF6StatsSetCustomID(customID string) (string, string, string, int, bool) {
+func parseSF6StatsSetCustomID(customID string) (string, string, string, string, int, bool) {
 	parts := strings.Split(customID, ":")
-	if len(parts) != 5 && len(parts) != 6 {
-		return "", "", "", 0, false
+	if len(parts) < 5 || len(parts) > 7 {
+		return "", "", "", "", 0, false
 	}
 	if parts[0] != "sf6_stats_set_page" {
-		return "", "", "", 0, false
+		return "", "", "", "", 0, false
 	}
 	if len(parts) == 5 {
 		page, err := strconv.Atoi(parts[4])
 		if err != nil || page <= 0 {
-			return "", "", "", 0, false
+			return "", "", "", "", 0, false
 		}
-		return parts[1], parts[2], parts[3], page, true
+		return parts[1], parts[2], parts[3], "", page, true
 	}
 	page, err := strconv.Atoi(parts[5])
 	if err != nil || page <= 0 {
-		return "", "", "", 0, false
+		return "", "", "", "", 0, false
+	}
+	battleType := ""
+	if len(parts) == 7 {
+		battleType = parseSF6Mode(parts[6])
 	}
-	return parts[2], parts[3], parts[4], page, true
+	return parts[2], parts[3], parts[4], battleType, page, true
 }
@@ -0,0 +1,159 @@
+package sf6
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend/internal/discord/common"
+	"backend/internal/domain"
+	"backend/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// description は 4096 文字までなので、表に載せる組み合わせ数を絞る。
+const sf6MatchupMaxLines = 40
+
+func (r *Handler) handleSF6StatsMatchup(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	var opponentCode, subjectCode, battleType string
+	minGames := service.SF6MatchupDefaultMinGames
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "opponent_code":
+			opponentCode = opt.StringValue()
+		case "subject_code":
+			subjectCode = opt.StringValue()
+		case "mode":
+			battleType = parseSF6Mode(opt.StringValue())
+		case "min_games":
+			minGames = int(opt.IntValue())
+		}
+	}
+	if opponentCode == "" {
+		common.RespondEphemeral(s, i, "opponent_code が必要です")
+		return
+	}
+	if minGames <= 0 {
+		common.RespondEphemeral(s, i, "min_games は 1 以上を指定してください")
+		return
+	}
+	if err := common.DeferPublic(s, i); err != nil {
+		common.RespondEphemeral(s, i, "受付に失敗しました")
+		return
+	}
+	if sid, _, ok, err := r.resolveSIDFromMention(ctx, i.GuildID, opponentCode); ok {
+		if err != nil {
+			common.FollowupEphemeral(s, i, err.Error())
+			return
+		}
+		opponentCode = sid
+	}
+	subjectSID, err := r.resolveSubjectSID(ctx, i.GuildID, userID, subjectCode)
+	if err != nil {
+		common.FollowupEphemeral(s, i, err.Error())
+		return
+	}
+	if err := r.fetchLatestForStats(ctx, i.GuildID, userID, subjectSID); err != nil {
+		common.FollowupEphemeral(s, i, "最新取得に失敗: "+formatSF6FetchError(err))
+		return
+	}
+	matrix, err := r.SF6Service.MatchupMatrix(ctx, i.GuildID, subjectSID, opponentCode, battleType, minGames)
+	if err != nil {
+		common.FollowupEphemeral(s, i, "集計に失敗: "+err.Error())
+		return
+	}
+	label := appendSF6ModeLabel(fmt.Sprintf("全期間 / %d 戦以上の組み合わせ", matrix.MinGames), battleType)
+	subjectUser, opponentUser := r.buildStatsEmbedUsers(ctx, s, i.GuildID, subjectSID, opponentCode)
+	common.FollowupPublicEmbed(s, i, "", buildMatchupEmbed(label, subjectUser, opponentUser, matrix), nil)
+}
+
+func buildMatchupEmbed(periodLabel string, subject, opponent statsEmbedUser, matrix *domain.SF6MatchupMatrix) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "SF6 Stats (Matchup)",
+		Description: periodLabel + "\n" + formatMatchupTable(matrix),
+		Color:       0x2b6cb0,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:  "Players",
+				Value: fmt.Sprintf("subject: %s\nopponent: %s", formatStatsUserLine(subject), formatStatsUserLine(opponent)),
+			},
+		},
+	}
+	if len(matrix.Worst) > 0 {
+		lines := make([]string, 0, len(matrix.Worst))
+		for _, cell := range matrix.Worst {
+			lines = append(lines, fmt.Sprintf("▼ **%s** vs **%s**: %d-%d (%s)",
+				matchupCharName(cell.SelfCharacter), matchupCharName(cell.OpponentCharacter),
+				cell.Wins, cell.Losses, calcWinRate(matchupTotals(cell))))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Worst Matchups",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	if matrix.HiddenCells > 0 {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d 戦未満の %d 組（計 %d 戦）は非表示", matrix.MinGames, matrix.HiddenCells, matrix.HiddenGames),
+		}
+	}
+	applyStatsIcons(embed, subject, opponent)
+	return embed
+}
+
+// formatMatchupTable は自キャラごとに相手キャラとの成績を並べた表を作る。苦手な組み合わせには ▼ を付ける。
+func formatMatchupTable(matrix *domain.SF6MatchupMatrix) string {
+	if len(matrix.Cells) == 0 {
+		return "no data"
+	}
+	const maxNameWidth = 10
+	nameWidth := 4
+	for _, cell := range matrix.Cells {
+		for _, name := range []string{cell.SelfCharacter, cell.OpponentCharacter} {
+			if w := runeLen(matchupCharName(name)); w > nameWidth {
+				nameWidth = w
+			}
+		}
+	}
+	if nameWidth > maxNameWidth {
+		nameWidth = maxNameWidth
+	}
+	worst := make(map[[2]string]bool, len(matrix.Worst))
+	for _, cell := range matrix.Worst {
+		worst[[2]string{cell.SelfCharacter, cell.OpponentCharacter}] = true
+	}
+
+	lines := []string{fmt.Sprintf("  %s  %s  %4s %7s %6s",
+		padRight("MINE", nameWidth), padRight("VS", nameWidth), "G", "W-L", "WR")}
+	prevSelf := ""
+	for idx, cell := range matrix.Cells {
+		if idx >= sf6MatchupMaxLines {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(matrix.Cells)-idx))
+			break
+		}
+		self := ""
+		if idx == 0 || cell.SelfCharacter != prevSelf {
+			self = truncateName(matchupCharName(cell.SelfCharacter), nameWidth)
+		}
+		prevSelf = cell.SelfCharacter
+		mark := " "
+		if worst[[2]string{cell.SelfCharacter, cell.OpponentCharacter}] {
+			mark = "▼"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s  %s  %4d %7s %6s",
+			mark, padRight(self, nameWidth), padRight(truncateName(matchupCharName(cell.OpponentCharacter), nameWidth), nameWidth),
+			cell.Games, fmt.Sprintf("%d-%d", cell.Wins, cell.Losses), calcWinRate(matchupTotals(cell))))
+	}
+	return "```\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+func matchupTotals(cell domain.SF6MatchupRow) statsTotals {
+	return statsTotals{Total: cell.Games, Wins: cell.Wins, Losses: cell.Losses, Draws: cell.Draws}
+}
+
+func matchupCharName(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,87 @@
+package sf6
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"backend/internal/domain"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// embed の横幅に収まるよう、勝率推移は直近の点だけを描く。
+const sf6SparklineMaxPoints = 30
+
+var sf6SparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// appendMomentumFields は連勝・連敗と勝率推移のフィールドを By Character の前に差し込む。
+func appendMomentumFields(embed *discordgo.MessageEmbed, m domain.SF6Momentum) {
+	if embed == nil || len(m.RollingWinRates) == 0 {
+		return
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Streak",
+			Value:  fmt.Sprintf("Current **%s**\nBest **%s** / Worst **%s**", formatStreak(m.CurrentStreak), formatStreak(m.LongestWinStreak), formatStreak(-m.LongestLossStreak)),
+			Inline: true,
+		},
+		{
+			Name:   fmt.Sprintf("Win Rate Trend (last %d)", m.Window),
+			Value:  formatWinRateSparkline(m.RollingWinRates),
+			Inline: true,
+		},
+	}
+	insertStatsFields(embed, fields...)
+}
+
+// insertStatsFields は By Character（キャラ別の表）の前にフィールドを差し込む。
+func insertStatsFields(embed *discordgo.MessageEmbed, fields ...*discordgo.MessageEmbedField) {
+	pos := len(embed.Fields)
+	for idx, field := range embed.Fields {
+		if field.Name == "By Character" {
+			pos = idx
+			break
+		}
+	}
+	embed.Fields = append(embed.Fields[:pos], append(fields, embed.Fields[pos:]...)...)
+}
+
+// formatStreak は連勝を W3、連敗を L2 のように表す。
+func formatStreak(streak int) string {
+	switch {
+	case streak > 0:
+		return fmt.Sprintf("W%d", streak)
+	case streak < 0:
+		return fmt.Sprintf("L%d", -streak)
+	default:
+		return "-"
+	}
+}
+
+func formatWinRateSparkline(rates []float64) string {
+	if len(rates) > sf6SparklineMaxPoints {
+		rates = rates[len(rates)-sf6SparklineMaxPoints:]
+	}
+	first, last := rates[0], rates[len(rates)-1]
+	return fmt.Sprintf("`%s`\n%.1f%% → %.1f%%", sparkline(rates, 0, 1), first*100, last*100)
+}
+
+// sparkline は lo〜hi の値を 8 段階のブロック文字で描く。
+func sparkline(values []float64, lo, hi float64) string {
+	var b strings.Builder
+	top := len(sf6SparklineBlocks) - 1
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int(math.Round((v - lo) / (hi - lo) * float64(top)))
+		}
+		if idx < 0 {
+			idx = 0
+		} else if idx > top {
+			idx = top
+		}
+		b.WriteRune(sf6SparklineBlocks[idx])
+	}
+	return b.String()
+}
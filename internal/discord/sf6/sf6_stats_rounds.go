@@ -0,0 +1,51 @@
+package sf6
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// 決着の内訳として表示する種類。
+var sf6HighlightFinishes = []struct {
+	Finish string
+	Label  string
+}{
+	{buckler.RoundFinishPerfect, "Perfect"},
+	{buckler.RoundFinishSuperArt, "Super Art"},
+	{buckler.RoundFinishCriticalArt, "Critical Art"},
+}
+
+// appendRoundFields は 1 ラウンド目の取得率・逆転率と、決着の種類の内訳を追加する。
+func appendRoundFields(embed *discordgo.MessageEmbed, stats domain.SF6RoundStats) {
+	if embed == nil || stats.Battles == 0 {
+		return
+	}
+	rounds := fmt.Sprintf("R1 Win: **%s** (%d/%d)\nComeback: **%s** (%d/%d)\nRounds: %d-%d",
+		formatRatio(stats.RoundOneWins, stats.RoundOneDecided), stats.RoundOneWins, stats.RoundOneDecided,
+		formatRatio(stats.Comebacks, stats.LostRoundOne), stats.Comebacks, stats.LostRoundOne,
+		stats.SelfRounds, stats.OpponentRounds)
+
+	lines := make([]string, 0, len(sf6HighlightFinishes))
+	for _, f := range sf6HighlightFinishes {
+		self, oppo := stats.SelfFinishes[f.Finish], stats.OpponentFinishes[f.Finish]
+		lines = append(lines, fmt.Sprintf("%s: %d (%s) / %d (%s)",
+			f.Label, self, formatRatio(self, stats.SelfRounds), oppo, formatRatio(oppo, stats.OpponentRounds)))
+	}
+	insertStatsFields(embed,
+		&discordgo.MessageEmbedField{Name: "Rounds", Value: rounds, Inline: true},
+		&discordgo.MessageEmbedField{Name: "Finishes (subject / opponent)", Value: strings.Join(lines, "\n"), Inline: true},
+	)
+}
+
+// formatRatio は n / d を百分率で表す。d が 0 なら "-"。
+func formatRatio(n, d int) string {
+	if d == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)/float64(d)*100)
+}
@@ -6,4 +6,31 @@ type HealthReport struct {
 	DB      bool   `json:"db"`
 	Version string `json:"version,omitempty"`
 	Time    string `json:"time"`
+	// Buckler は Buckler クライアントが有効な場合のみ設定する。
+	Buckler *BucklerHealth `json:"buckler,omitempty"`
+	// Leader は定期ジョブのリーダー選出が有効な場合のみ設定する。
+	Leader *LeaderHealth `json:"leader,omitempty"`
+}
+
+// BucklerHealth は Buckler 系ホストへのリクエスト数と制限状況。
+type BucklerHealth struct {
+	Requests     int64            `json:"requests"`
+	ByHost       map[string]int64 `json:"by_host"`
+	Throttled    int64            `json:"throttled"`
+	Rejected     int64            `json:"rejected"`
+	RateLimited  int64            `json:"rate_limited"`
+	DailyCount   int              `json:"daily_count"`
+	DailyCap     int              `json:"daily_cap"`
+	DailyResetAt string           `json:"daily_reset_at"`
+	BlockedUntil string           `json:"blocked_until,omitempty"`
+}
+
+// LeaderHealth は定期ジョブを実行しているインスタンス（advisory lock の保持者）。
+type LeaderHealth struct {
+	Lock     string `json:"lock"`
+	Instance string `json:"instance"`
+	IsLeader bool   `json:"is_leader"`
+	// Leader は現在ロックを保持しているインスタンス。誰も保持していなければ空。
+	Leader string `json:"leader,omitempty"`
+	Since  string `json:"since,omitempty"`
 }
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// SF6 バックフィルジョブの状態。
+const (
+	SF6BackfillRunning = "running"
+	SF6BackfillPaused  = "paused"
+	SF6BackfillDone    = "done"
+	SF6BackfillFailed  = "failed"
+)
+
+// SF6BackfillJob は 1 つの sid・対戦種別について battlelog の全ページを遡る取得の進捗。
+// NextPage まで取得済みとして、再開時はそこから続ける。
+type SF6BackfillJob struct {
+	ID               string
+	GuildID          string
+	UserID           string
+	SubjectFighterID string
+	BattleType       string
+	Status           string
+	NextPage         int
+	TotalPage        int
+	SavedCount       int
+	LastError        string
+	StartedAt        time.Time
+	FinishedAt       *time.Time
+	UpdatedAt        time.Time
+}
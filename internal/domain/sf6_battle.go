@@ -10,6 +10,7 @@ type SF6Battle struct {
 	GuildID           string
 	UserID            string
 	OwnerKind         string
+	BattleType        string
 	SubjectFighterID  string
 	OpponentFighterID string
 	BattleAt          time.Time
@@ -21,12 +22,26 @@ type SF6Battle struct {
 	SourceKey         string
 	SessionID         *string
 	RawPayload        json.RawMessage
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	// Rounds は round_results から求めたラウンドごとの結果。保存時に sf6_battle_rounds へ書き込む。
+	Rounds    []SF6BattleRound
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SF6BattleRound は 1 ラウンドの勝者と決着の種類（buckler.RoundFinish*）。
+type SF6BattleRound struct {
+	BattleID string
+	RoundNo  int
+	// Winner は self / opponent / draw。
+	Winner       string
+	Finish       string
+	SelfCode     int
+	OpponentCode int
 }
 
 type SF6BattleHistoryRow struct {
 	BattleAt          time.Time
+	BattleType        string
 	Result            string
 	SelfCharacter     string
 	OpponentCharacter string
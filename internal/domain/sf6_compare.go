@@ -0,0 +1,34 @@
+package domain
+
+// SF6OpponentRecord は 1 人の相手に対する通算成績。
+type SF6OpponentRecord struct {
+	OpponentFighterID string
+	Games             int
+	Wins              int
+	Losses            int
+	Draws             int
+}
+
+// SF6ComparisonRow は共通の相手 1 人に対する 2 人の成績。
+type SF6ComparisonRow struct {
+	OpponentFighterID string
+	A                 SF6OpponentRecord
+	B                 SF6OpponentRecord
+	// Leader は勝率（引き分け除く）の高い方。1: A / -1: B / 0: 互角か比べられない。
+	Leader int
+}
+
+// SF6Comparison は 2 人の fighter を、両者が対戦したことのある相手だけで比べたもの。
+type SF6Comparison struct {
+	FighterA string
+	FighterB string
+	// Rows は共通の相手ごとの成績。2 人の試合数の合計が多い順。
+	Rows []SF6ComparisonRow
+	// TotalA / TotalB は共通の相手に対する成績の合計（OpponentFighterID は空）。
+	TotalA SF6OpponentRecord
+	TotalB SF6OpponentRecord
+	// BetterA / BetterB / Even は相手ごとの勝率（引き分け除く）でどちらが上回ったかの数。
+	BetterA int
+	BetterB int
+	Even    int
+}
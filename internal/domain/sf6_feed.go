@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// SF6FeedChannel は新着対戦を投稿するギルドのチャンネル。
+// CursorAt までに保存された対戦（sf6_battles.created_at）は通知済み。
+type SF6FeedChannel struct {
+	GuildID      string
+	ChannelID    string
+	ConfiguredBy string
+	CursorAt     time.Time
+	UpdatedAt    time.Time
+}
+
+// SF6FeedGroup は 1 回の通知にまとめる、同じ subject / opponent の新着対戦。
+type SF6FeedGroup struct {
+	GuildID           string
+	ChannelID         string
+	SubjectFighterID  string
+	OpponentFighterID string
+	// SubjectUserID は subject の戦績の保存先ユーザー。
+	SubjectUserID string
+	// Battles は古い順。
+	Battles []SF6BattleHistoryRow
+}
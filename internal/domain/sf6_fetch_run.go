@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// 取得のきっかけ（sf6_fetch_runs.trigger）。
+const (
+	SF6FetchTriggerPoller  = "poller"
+	SF6FetchTriggerManual  = "manual"
+	SF6FetchTriggerStats   = "stats"
+	SF6FetchTriggerSession = "session"
+)
+
+// SF6FetchRun は 1 fighter の取得 1 回分（再試行は別の行）の記録。成功時は ErrorClass が空。
+type SF6FetchRun struct {
+	ID           string
+	GuildID      string
+	FighterID    string
+	Trigger      string
+	Attempt      int
+	Pages        int
+	Saved        int
+	Duration     time.Duration
+	ErrorClass   string
+	ErrorMessage string
+	StartedAt    time.Time
+}
+
+// SF6FetchFighterStatus は fighter ごとの直近の取得と次回の定期取得予定。
+type SF6FetchFighterStatus struct {
+	FighterID string
+	// LastRun は直近の取得。まだ一度も取得していなければ nil。
+	LastRun *SF6FetchRun
+	// NextPollAt は次回の定期取得予定。予定が無ければ nil。
+	NextPollAt *time.Time
+	Interval   time.Duration
+}
+
+// SF6FetchStatus はギルドの取得状況。
+type SF6FetchStatus struct {
+	Fighters []SF6FetchFighterStatus
+	Failures []SF6FetchRun
+}
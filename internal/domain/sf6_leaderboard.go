@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// SF6LeaderboardGame はギルドの連携メンバーどうしの 1 試合を、FighterID から見た勝敗で表したもの。
+// 1 試合につき両者の分の 2 件になる。
+type SF6LeaderboardGame struct {
+	FighterID         string
+	OpponentFighterID string
+	BattleAt          time.Time
+	Result            string
+}
+
+// SF6LeaderboardEntry はランキングの 1 人分。
+type SF6LeaderboardEntry struct {
+	FighterID string
+	Games     int
+	Wins      int
+	Losses    int
+	Draws     int
+	// WinRate は引き分けを除いた勝率（0〜1）。
+	WinRate          float64
+	LongestWinStreak int
+	// Opponents は対戦したメンバーの数。
+	Opponents int
+}
+
+// SF6Leaderboard は並べ替え済みのランキング。
+type SF6Leaderboard struct {
+	Entries []SF6LeaderboardEntry
+	SortBy  string
+	// MinGames は勝率順のときに対象にする最低試合数。Hidden はそれに満たず除いた人数。
+	MinGames int
+	Hidden   int
+}
@@ -0,0 +1,16 @@
+package domain
+
+// SF6OpponentSuggestion の候補元。
+const (
+	SF6OpponentSourceFriend = "friend"
+	SF6OpponentSourceMember = "member"
+	SF6OpponentSourceRecent = "recent"
+)
+
+// SF6OpponentSuggestion は opponent_code の入力候補 1 件。
+type SF6OpponentSuggestion struct {
+	FighterID string
+	// Name はフレンドの別名・表示名や連携アカウントの表示名。分からなければ空。
+	Name   string
+	Source string
+}
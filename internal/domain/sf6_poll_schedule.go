@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// SF6PollSchedule は fighter ごとの定期取得の予定。Interval は直近の取得結果に応じて伸び縮みする。
+type SF6PollSchedule struct {
+	GuildID         string
+	FighterID       string
+	UserID          string
+	Interval        time.Duration
+	NextPollAt      time.Time
+	LastPolledAt    *time.Time
+	LastNewBattleAt *time.Time
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// SF6Rating はギルド内の対戦から求めた fighter のレーティング。
+// Character が空なら全キャラ通算、それ以外はそのキャラを使った対戦だけのレーティング。
+type SF6Rating struct {
+	GuildID      string
+	FighterID    string
+	Character    string
+	Rating       float64
+	Peak         float64
+	Games        int
+	Wins         int
+	Losses       int
+	Draws        int
+	LastBattleAt time.Time
+}
+
+// SF6RatingChange は 1 試合でのレーティングの変化（Result は FighterID から見た勝敗）。
+type SF6RatingChange struct {
+	GuildID           string
+	FighterID         string
+	Character         string
+	OpponentFighterID string
+	Result            string
+	BattleAt          time.Time
+	Before            float64
+	After             float64
+}
+
+// SF6RatingBattle はレーティング計算に使う 1 試合。双方が登録されていて 2 行ある試合も 1 件にまとめる。
+type SF6RatingBattle struct {
+	SubjectFighterID  string
+	OpponentFighterID string
+	SelfCharacter     string
+	OpponentCharacter string
+	Result            string
+	BattleAt          time.Time
+	// MatchKey は対戦した 2 人の sid を小さい順に並べ、replay を区別する値を続けたもの。同時刻の試合の並び順にも使う。
+	MatchKey string
+}
+
+// SF6RatingCursor はギルドのレーティングをどの試合まで反映したか。
+type SF6RatingCursor struct {
+	GuildID  string
+	BattleAt time.Time
+	MatchKey string
+	// CheckedAt 以降に保存された試合が BattleAt より前なら、最初から計算し直す。
+	CheckedAt time.Time
+	// Version は同時に更新しないための楽観ロック。
+	Version int64
+}
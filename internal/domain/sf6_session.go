@@ -6,8 +6,13 @@ type SF6Session struct {
 	ID                string
 	GuildID           string
 	UserID            string
+	SubjectFighterID  string
 	OpponentFighterID string
 	Status            string
 	StartedAt         time.Time
 	EndedAt           *time.Time
+	LastPolledAt      time.Time
+	LastSeenBattleAt  *time.Time
+	ChannelID         string
+	MessageID         string
 }
@@ -1,7 +1,65 @@
 package domain
 
+import "time"
+
 type SF6BattleStatRow struct {
 	SelfCharacter string
 	Result        string
 	Count         int
 }
+
+// SF6BattleResult は連勝や勝率推移を出すための、時系列順の 1 試合の勝敗。
+type SF6BattleResult struct {
+	BattleAt time.Time
+	Result   string
+}
+
+// SF6Momentum は時系列順の勝敗から求めた連勝・連敗と勝率の推移。
+type SF6Momentum struct {
+	// CurrentStreak は直近から数えた連勝数（正）または連敗数（負）。直近が引き分けなら 0。
+	CurrentStreak     int
+	LongestWinStreak  int
+	LongestLossStreak int
+	// Window は RollingWinRates の各点で見る試合数。
+	Window int
+	// RollingWinRates は古い順の、直近 Window 戦の勝率（0〜1、引き分け除く）。
+	RollingWinRates []float64
+}
+
+// SF6MatchupRow は自キャラ × 相手キャラの組み合わせごとの成績。
+type SF6MatchupRow struct {
+	SelfCharacter     string
+	OpponentCharacter string
+	Games             int
+	Wins              int
+	Losses            int
+	Draws             int
+}
+
+// SF6MatchupMatrix はキャラ対キャラの成績表。
+type SF6MatchupMatrix struct {
+	// Cells は MinGames 以上の組み合わせ。自キャラの試合数が多い順、同じ自キャラ内は試合数順。
+	Cells []SF6MatchupRow
+	// Worst は Cells のうち勝率 50% 未満の組み合わせを勝率の低い順に並べたもの。
+	Worst    []SF6MatchupRow
+	MinGames int
+	// HiddenCells / HiddenGames は MinGames 未満で表示しない組み合わせとその試合数。
+	HiddenCells int
+	HiddenGames int
+}
+
+// SF6RoundStats はラウンド単位の集計。ラウンド結果が保存されている対戦だけを数える。
+type SF6RoundStats struct {
+	Battles int
+	// RoundOneWins / RoundOneDecided は 1 ラウンド目を取った数と、勝敗のついた 1 ラウンド目の数。
+	RoundOneWins    int
+	RoundOneDecided int
+	// Comebacks は 1 ラウンド目を落としてから勝った対戦数。LostRoundOne が母数。
+	Comebacks    int
+	LostRoundOne int
+	// SelfRounds / OpponentRounds は取ったラウンド数、*Finishes はその決着の種類ごとの内訳。
+	SelfRounds       int
+	OpponentRounds   int
+	SelfFinishes     map[string]int
+	OpponentFinishes map[string]int
+}
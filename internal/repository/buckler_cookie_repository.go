@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// BucklerCookieRepository は暗号化済みの Buckler ログインCookieを保存する（buckler.CookieStore を満たす）。
+type BucklerCookieRepository interface {
+	LoadCookieSnapshot(ctx context.Context, accountKey string) (string, string, error)
+	SaveCookieSnapshot(ctx context.Context, accountKey, keyID, payload string) error
+}
+
+type bucklerCookieRepository struct {
+	db *sql.DB
+}
+
+func NewBucklerCookieRepository(db *sql.DB) BucklerCookieRepository {
+	return &bucklerCookieRepository{db: db}
+}
+
+// LoadCookieSnapshot は保存済みの key_id と暗号文を返す。未保存なら空文字を返す。
+func (r *bucklerCookieRepository) LoadCookieSnapshot(ctx context.Context, accountKey string) (string, string, error) {
+	if accountKey == "" {
+		return "", "", errors.New("accountKey is required")
+	}
+	var keyID, payload string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT key_id, payload
+         FROM buckler_cookie_snapshots
+         WHERE account_key = $1`,
+		accountKey,
+	).Scan(&keyID, &payload)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	return keyID, payload, nil
+}
+
+func (r *bucklerCookieRepository) SaveCookieSnapshot(ctx context.Context, accountKey, keyID, payload string) error {
+	if accountKey == "" || keyID == "" || payload == "" {
+		return errors.New("accountKey, keyID, payload are required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO buckler_cookie_snapshots (account_key, key_id, payload)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (account_key) DO UPDATE
+         SET key_id = EXCLUDED.key_id,
+             payload = EXCLUDED.payload,
+             updated_at = now()`,
+		accountKey, keyID, payload,
+	)
+	return err
+}
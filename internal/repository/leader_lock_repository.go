@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"hash/fnv"
+	"time"
+)
+
+// ErrLeaderLockLost はロックを保持していた接続が切れた、またはロックが外された場合のエラー。
+var ErrLeaderLockLost = errors.New("leader lock lost")
+
+// Postgres の application_name は 63 バイトまで。
+const maxApplicationNameLen = 63
+
+// Release で unlock にかける時間。
+const leaderLockReleaseTimeout = 5 * time.Second
+
+// LeaderLock は取得済みの advisory lock。ロックは専用の接続（セッション）に紐づくので、
+// プロセスが落ちて接続が切れれば Postgres 側で自動的に解放される。
+type LeaderLock interface {
+	// Check は接続が生きていてロックを保持し続けているかを確認する。
+	Check(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+type LeaderLockRepository interface {
+	// TryAcquire はロックを待たずに取得を試みる。他のインスタンスが保持していれば nil を返す。
+	TryAcquire(ctx context.Context, name, instanceID string) (LeaderLock, error)
+	// Holder は現在ロックを保持しているインスタンス ID を返す。誰も保持していなければ空文字。
+	Holder(ctx context.Context, name string) (string, error)
+}
+
+type leaderLockRepository struct {
+	db *sql.DB
+}
+
+func NewLeaderLockRepository(db *sql.DB) LeaderLockRepository {
+	return &leaderLockRepository{db: db}
+}
+
+// leaderLockKey はロック名を advisory lock の bigint キーに変換する。
+func leaderLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// pg_locks 上では bigint キーが classid（上位 32bit）と objid（下位 32bit）に分かれ、objsubid = 1 になる。
+func leaderLockOIDs(key int64) (int64, int64) {
+	return int64(uint32(uint64(key) >> 32)), int64(uint32(key))
+}
+
+func (r *leaderLockRepository) TryAcquire(ctx context.Context, name, instanceID string) (LeaderLock, error) {
+	if name == "" || instanceID == "" {
+		return nil, errors.New("name, instanceID are required")
+	}
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := leaderLockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, nil
+	}
+	// 他のインスタンスから保持者が分かるよう、ロックを持つセッションに application_name を付ける
+	if len(instanceID) > maxApplicationNameLen {
+		instanceID = instanceID[:maxApplicationNameLen]
+	}
+	lock := &leaderLock{conn: conn, key: key}
+	if _, err := conn.ExecContext(ctx, `SELECT set_config('application_name', $1, false)`, instanceID); err != nil {
+		_ = lock.Release(context.WithoutCancel(ctx))
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (r *leaderLockRepository) Holder(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	classID, objID := leaderLockOIDs(leaderLockKey(name))
+	var holder string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(a.application_name, '')
+         FROM pg_locks l
+         JOIN pg_stat_activity a ON a.pid = l.pid
+         WHERE l.locktype = 'advisory'
+           AND l.granted
+           AND l.classid = $1::oid
+           AND l.objid = $2::oid
+           AND l.objsubid = 1
+         LIMIT 1`,
+		classID, objID,
+	).Scan(&holder)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return holder, nil
+}
+
+type leaderLock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+func (l *leaderLock) Check(ctx context.Context) error {
+	classID, objID := leaderLockOIDs(l.key)
+	var held bool
+	err := l.conn.QueryRowContext(ctx,
+		`SELECT EXISTS (
+            SELECT 1 FROM pg_locks
+            WHERE locktype = 'advisory'
+              AND pid = pg_backend_pid()
+              AND granted
+              AND classid = $1::oid
+              AND objid = $2::oid
+              AND objsubid = 1
+         )`,
+		classID, objID,
+	).Scan(&held)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLeaderLockLost
+	}
+	return nil
+}
+
+// Release はロックを外して接続をプールに返す。
+// 呼び出し元の context がキャンセル済みでも外せるよう、unlock はキャンセルを引き継がない context で行う。
+// unlock に失敗した接続はロックを持ったままかもしれないので、プールに返さず破棄する
+// （接続が切れればロックは Postgres 側で解放される）。
+func (l *leaderLock) Release(ctx context.Context) error {
+	unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaderLockReleaseTimeout)
+	defer cancel()
+	_, err := l.conn.ExecContext(unlockCtx,
+		`SELECT pg_advisory_unlock($1), set_config('application_name', '', false)`,
+		l.key,
+	)
+	if err != nil {
+		_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = l.conn.Close()
+		return err
+	}
+	return l.conn.Close()
+}
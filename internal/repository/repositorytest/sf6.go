@@ -0,0 +1,89 @@
+// Package repositorytest はサービスやハンドラのテストで使う、メモリ上の repository を提供する。
+//
+// 使わないメソッドは埋め込んだ interface（nil）に委ねるので、呼ばれると panic する。
+package repositorytest
+
+import (
+	"context"
+	"sync"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// SF6AccountRepo は Accounts を連携アカウントとして返す。
+type SF6AccountRepo struct {
+	repository.SF6AccountRepository
+	Accounts []domain.SF6Account
+}
+
+func (r *SF6AccountRepo) ListActive(ctx context.Context) ([]domain.SF6Account, error) {
+	return r.Accounts, nil
+}
+
+func (r *SF6AccountRepo) GetByFighter(ctx context.Context, guildID, fighterID string) (*domain.SF6Account, error) {
+	for _, account := range r.Accounts {
+		if account.GuildID == guildID && account.FighterID == fighterID {
+			a := account
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *SF6AccountRepo) ListByGuild(ctx context.Context, guildID string) ([]domain.SF6Account, error) {
+	var out []domain.SF6Account
+	for _, account := range r.Accounts {
+		if account.GuildID == guildID {
+			out = append(out, account)
+		}
+	}
+	return out, nil
+}
+
+// SF6BattleRepo は保存された対戦を guild と source_key ごとに持つ。並行に呼んでよい。
+type SF6BattleRepo struct {
+	repository.SF6BattleRepository
+	mu      sync.Mutex
+	battles map[string]domain.SF6Battle
+}
+
+func NewSF6BattleRepo() *SF6BattleRepo {
+	return &SF6BattleRepo{battles: make(map[string]domain.SF6Battle)}
+}
+
+func (r *SF6BattleRepo) ExistingSourceKeys(ctx context.Context, guildID, subjectFighterID string, keys []string) (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]struct{})
+	for _, key := range keys {
+		if _, ok := r.battles[guildID+":"+key]; ok {
+			out[key] = struct{}{}
+		}
+	}
+	return out, nil
+}
+
+func (r *SF6BattleRepo) BulkUpsert(ctx context.Context, battles []domain.SF6Battle) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, battle := range battles {
+		r.battles[battle.GuildID+":"+battle.SourceKey] = battle
+	}
+	return len(battles), nil
+}
+
+func (r *SF6BattleRepo) AssignSessions(ctx context.Context, guildID, subjectFighterID string) (int64, error) {
+	return 0, nil
+}
+
+// Battles は保存された対戦を返す。
+func (r *SF6BattleRepo) Battles() []domain.SF6Battle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domain.SF6Battle, 0, len(r.battles))
+	for _, battle := range r.battles {
+		out = append(out, battle)
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"backend/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SF6BackfillRepository interface {
+	Start(ctx context.Context, guildID, userID, subjectFighterID, battleType string, restart bool, lease time.Duration) (*domain.SF6BackfillJob, error)
+	Heartbeat(ctx context.Context, id string) error
+	Checkpoint(ctx context.Context, id string, nextPage, totalPage, saved int) error
+	Finish(ctx context.Context, id, status, lastError string) error
+	Get(ctx context.Context, guildID, subjectFighterID, battleType string) (*domain.SF6BackfillJob, error)
+	ListUnfinished(ctx context.Context) ([]domain.SF6BackfillJob, error)
+}
+
+type sf6BackfillRepository struct {
+	db *sql.DB
+}
+
+func NewSF6BackfillRepository(db *sql.DB) SF6BackfillRepository {
+	return &sf6BackfillRepository{db: db}
+}
+
+const sf6BackfillColumns = `id, guild_id, user_id, subject_fighter_id, battle_type, status,
+            next_page, total_page, saved_count, COALESCE(last_error, ''), started_at, finished_at, updated_at`
+
+func scanSF6BackfillJob(row sf6SessionScanner) (domain.SF6BackfillJob, error) {
+	var job domain.SF6BackfillJob
+	err := row.Scan(
+		&job.ID,
+		&job.GuildID,
+		&job.UserID,
+		&job.SubjectFighterID,
+		&job.BattleType,
+		&job.Status,
+		&job.NextPage,
+		&job.TotalPage,
+		&job.SavedCount,
+		&job.LastError,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.UpdatedAt,
+	)
+	return job, err
+}
+
+// Start はジョブを running にして返す。既存のジョブがあれば続きから再開する。
+// 完了済みのジョブ、または restart 指定時は 1 ページ目からやり直す。
+// 別のプロセスが running にしていて heartbeat が lease 以内なら取得せず nil を返す。
+func (r *sf6BackfillRepository) Start(ctx context.Context, guildID, userID, subjectFighterID, battleType string, restart bool, lease time.Duration) (*domain.SF6BackfillJob, error) {
+	if guildID == "" || userID == "" || subjectFighterID == "" || battleType == "" {
+		return nil, errors.New("guildID, userID, subjectFighterID, battleType are required")
+	}
+	if err := ensureGuildAndUser(ctx, r.db, guildID, userID); err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx,
+		`INSERT INTO sf6_backfill_jobs (
+            guild_id, user_id, subject_fighter_id, battle_type, status, heartbeat_at
+         ) VALUES (
+            $1, $2, $3, $4, 'running', now()
+         )
+         ON CONFLICT (guild_id, subject_fighter_id, battle_type) DO UPDATE SET
+            user_id = EXCLUDED.user_id,
+            status = 'running',
+            next_page = CASE WHEN $5 OR sf6_backfill_jobs.status = 'done' THEN 1 ELSE sf6_backfill_jobs.next_page END,
+            total_page = CASE WHEN $5 OR sf6_backfill_jobs.status = 'done' THEN 0 ELSE sf6_backfill_jobs.total_page END,
+            saved_count = CASE WHEN $5 OR sf6_backfill_jobs.status = 'done' THEN 0 ELSE sf6_backfill_jobs.saved_count END,
+            started_at = CASE WHEN $5 OR sf6_backfill_jobs.status = 'done' THEN now() ELSE sf6_backfill_jobs.started_at END,
+            last_error = NULL,
+            finished_at = NULL,
+            heartbeat_at = now(),
+            updated_at = now()
+         WHERE sf6_backfill_jobs.status <> 'running'
+            OR sf6_backfill_jobs.heartbeat_at IS NULL
+            OR sf6_backfill_jobs.heartbeat_at < now() - $6 * interval '1 second'
+         RETURNING `+sf6BackfillColumns,
+		guildID, userID, subjectFighterID, battleType, restart, lease.Seconds(),
+	)
+	job, err := scanSF6BackfillJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Heartbeat は実行中のジョブの lease を延ばす。
+func (r *sf6BackfillRepository) Heartbeat(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_backfill_jobs
+         SET heartbeat_at = now()
+         WHERE id = $1 AND status = 'running'`,
+		id,
+	)
+	return err
+}
+
+// Checkpoint は取得済みページまでの進捗を保存する。saved は今回のページで保存した件数。
+func (r *sf6BackfillRepository) Checkpoint(ctx context.Context, id string, nextPage, totalPage, saved int) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_backfill_jobs
+         SET next_page = $2, total_page = $3, saved_count = saved_count + $4, heartbeat_at = now(), updated_at = now()
+         WHERE id = $1`,
+		id, nextPage, totalPage, saved,
+	)
+	return err
+}
+
+func (r *sf6BackfillRepository) Finish(ctx context.Context, id, status, lastError string) error {
+	if id == "" || status == "" {
+		return errors.New("id, status are required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_backfill_jobs
+         SET status = $2,
+             last_error = $3,
+             finished_at = CASE WHEN $2 = 'done' THEN now() ELSE NULL END,
+             heartbeat_at = NULL,
+             updated_at = now()
+         WHERE id = $1`,
+		id, status, nullIfEmpty(lastError),
+	)
+	return err
+}
+
+func (r *sf6BackfillRepository) Get(ctx context.Context, guildID, subjectFighterID, battleType string) (*domain.SF6BackfillJob, error) {
+	if guildID == "" || subjectFighterID == "" || battleType == "" {
+		return nil, errors.New("guildID, subjectFighterID, battleType are required")
+	}
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+sf6BackfillColumns+`
+         FROM sf6_backfill_jobs
+         WHERE guild_id = $1 AND subject_fighter_id = $2 AND battle_type = $3`,
+		guildID, subjectFighterID, battleType,
+	)
+	job, err := scanSF6BackfillJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListUnfinished は再起動などで途中になっているジョブ（running / paused）を返す。
+func (r *sf6BackfillRepository) ListUnfinished(ctx context.Context) ([]domain.SF6BackfillJob, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+sf6BackfillColumns+`
+         FROM sf6_backfill_jobs
+         WHERE status IN ('running', 'paused')
+         ORDER BY updated_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []domain.SF6BackfillJob
+	for rows.Next() {
+		job, err := scanSF6BackfillJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
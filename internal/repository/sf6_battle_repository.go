@@ -16,11 +16,24 @@ type SF6BattleRepository interface {
 	ReassignOwnerBySubject(ctx context.Context, guildID, subjectFighterID, newUserID string) (int64, error)
 	MarkOwnerKindUnlinkedBySubject(ctx context.Context, guildID, subjectFighterID string) (int64, error)
 	ExistingSourceKeys(ctx context.Context, guildID, subjectFighterID string, keys []string) (map[string]struct{}, error)
-	StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error)
-	StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit int) ([]domain.SF6BattleStatRow, error)
-	HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit, offset int) ([]domain.SF6BattleHistoryRow, error)
-	CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) (int, error)
-	BattleTimesByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) ([]time.Time, error)
+	AssignSessions(ctx context.Context, guildID, subjectFighterID string) (int64, error)
+	StatsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleStatRow, error)
+	HistoryBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleHistoryRow, error)
+	StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error)
+	StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleStatRow, error)
+	ResultsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleResult, error)
+	ResultsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleResult, error)
+	ResultsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleResult, error)
+	RoundsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleRound, error)
+	RoundsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleRound, error)
+	RoundsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleRound, error)
+	MatchupsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6MatchupRow, error)
+	HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit, offset int) ([]domain.SF6BattleHistoryRow, error)
+	CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) (int, error)
+	ResultsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6BattleResult, error)
+	LeaderboardGames(ctx context.Context, guildID, battleType string, startAt, endAt time.Time) ([]domain.SF6LeaderboardGame, error)
+	RecordsBySubject(ctx context.Context, guildID, subjectFighterID, battleType string) ([]domain.SF6OpponentRecord, error)
+	RecentOpponents(ctx context.Context, guildID, userID string, limit int) ([]string, error)
 	DeleteByUser(ctx context.Context, guildID, userID string) (int64, error)
 }
 
@@ -42,18 +55,29 @@ func (r *sf6BattleRepository) Upsert(ctx context.Context, battle domain.SF6Battl
 	if battle.OwnerKind == "" {
 		battle.OwnerKind = "account"
 	}
+	if battle.BattleType == "" {
+		battle.BattleType = "custom"
+	}
 	if err := ensureGuildAndUser(ctx, r.db, battle.GuildID, battle.UserID); err != nil {
 		return err
 	}
-	_, err := r.db.ExecContext(ctx,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var battleID string
+	var inserted bool
+	if err := tx.QueryRowContext(ctx,
 		`INSERT INTO sf6_battles (
             guild_id, user_id, owner_kind, subject_fighter_id, opponent_fighter_id, battle_at, result,
             self_character, opponent_character, round_wins, round_losses,
-            source_key, session_id, raw_payload
+            source_key, session_id, raw_payload, battle_type
          ) VALUES (
             $1, $2, $3, $4, $5, $6, $7,
             $8, $9, $10, $11,
-            $12, $13, $14
+            $12, $13, $14, $15
          )
          ON CONFLICT (guild_id, subject_fighter_id, source_key)
          DO UPDATE SET user_id = EXCLUDED.user_id,
@@ -65,9 +89,11 @@ func (r *sf6BattleRepository) Upsert(ctx context.Context, battle domain.SF6Battl
                        opponent_character = EXCLUDED.opponent_character,
                        round_wins = EXCLUDED.round_wins,
                        round_losses = EXCLUDED.round_losses,
-                       session_id = EXCLUDED.session_id,
+                       session_id = COALESCE(EXCLUDED.session_id, sf6_battles.session_id),
                        raw_payload = EXCLUDED.raw_payload,
-                       updated_at = now()`,
+                       battle_type = EXCLUDED.battle_type,
+                       updated_at = now()
+         RETURNING id, (xmax = 0)`,
 		battle.GuildID,
 		battle.UserID,
 		battle.OwnerKind,
@@ -82,8 +108,14 @@ func (r *sf6BattleRepository) Upsert(ctx context.Context, battle domain.SF6Battl
 		battle.SourceKey,
 		battle.SessionID,
 		nullIfEmptyBytes(battle.RawPayload),
-	)
-	return err
+		battle.BattleType,
+	).Scan(&battleID, &inserted); err != nil {
+		return err
+	}
+	if err := replaceSF6BattleRounds(ctx, tx, battleID, inserted, battle.Rounds); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.SF6Battle) (int, error) {
@@ -104,11 +136,11 @@ func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.S
 		`INSERT INTO sf6_battles (
             guild_id, user_id, owner_kind, subject_fighter_id, opponent_fighter_id, battle_at, result,
             self_character, opponent_character, round_wins, round_losses,
-            source_key, session_id, raw_payload
+            source_key, session_id, raw_payload, battle_type
          ) VALUES (
             $1, $2, $3, $4, $5, $6, $7,
             $8, $9, $10, $11,
-            $12, $13, $14
+            $12, $13, $14, $15
          )
          ON CONFLICT (guild_id, subject_fighter_id, source_key)
          DO UPDATE SET user_id = EXCLUDED.user_id,
@@ -120,9 +152,11 @@ func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.S
                        opponent_character = EXCLUDED.opponent_character,
                        round_wins = EXCLUDED.round_wins,
                        round_losses = EXCLUDED.round_losses,
-                       session_id = EXCLUDED.session_id,
+                       session_id = COALESCE(EXCLUDED.session_id, sf6_battles.session_id),
                        raw_payload = EXCLUDED.raw_payload,
-                       updated_at = now()`,
+                       battle_type = EXCLUDED.battle_type,
+                       updated_at = now()
+         RETURNING id, (xmax = 0)`,
 	)
 	if err != nil {
 		return 0, err
@@ -137,7 +171,12 @@ func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.S
 		if battle.OwnerKind == "" {
 			battle.OwnerKind = "account"
 		}
-		if _, err := stmt.ExecContext(ctx,
+		if battle.BattleType == "" {
+			battle.BattleType = "custom"
+		}
+		var battleID string
+		var inserted bool
+		if err := stmt.QueryRowContext(ctx,
 			battle.GuildID,
 			battle.UserID,
 			battle.OwnerKind,
@@ -152,7 +191,11 @@ func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.S
 			battle.SourceKey,
 			battle.SessionID,
 			nullIfEmptyBytes(battle.RawPayload),
-		); err != nil {
+			battle.BattleType,
+		).Scan(&battleID, &inserted); err != nil {
+			return 0, err
+		}
+		if err := replaceSF6BattleRounds(ctx, tx, battleID, inserted, battle.Rounds); err != nil {
 			return 0, err
 		}
 		count++
@@ -163,6 +206,81 @@ func (r *sf6BattleRepository) BulkUpsert(ctx context.Context, battles []domain.S
 	return count, nil
 }
 
+// replaceSF6BattleRounds は対戦のラウンド結果を書き直す。rounds が空なら既存の行を残す。
+// 既存の対戦は取り直しのたびに upsert されるので、保存済みのラウンドと同じなら書き込まない。
+func replaceSF6BattleRounds(ctx context.Context, tx *sql.Tx, battleID string, inserted bool, rounds []domain.SF6BattleRound) error {
+	if len(rounds) == 0 {
+		return nil
+	}
+	if !inserted {
+		existing, err := listSF6BattleRounds(ctx, tx, battleID)
+		if err != nil {
+			return err
+		}
+		if sameSF6BattleRounds(existing, rounds) {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM sf6_battle_rounds WHERE battle_id = $1`,
+			battleID,
+		); err != nil {
+			return err
+		}
+	}
+	for _, round := range rounds {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO sf6_battle_rounds (battle_id, round_no, winner, finish, self_code, opponent_code)
+             VALUES ($1, $2, $3, $4, $5, $6)`,
+			battleID, round.RoundNo, round.Winner, round.Finish, round.SelfCode, round.OpponentCode,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listSF6BattleRounds(ctx context.Context, tx *sql.Tx, battleID string) ([]domain.SF6BattleRound, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT round_no, winner, finish, self_code, opponent_code
+         FROM sf6_battle_rounds
+         WHERE battle_id = $1
+         ORDER BY round_no`,
+		battleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rounds []domain.SF6BattleRound
+	for rows.Next() {
+		round := domain.SF6BattleRound{BattleID: battleID}
+		if err := rows.Scan(&round.RoundNo, &round.Winner, &round.Finish, &round.SelfCode, &round.OpponentCode); err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, round)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rounds, nil
+}
+
+// sameSF6BattleRounds は round_no 順に並んだ保存済みのラウンドと、取得したラウンドが同じかを返す。
+func sameSF6BattleRounds(existing, rounds []domain.SF6BattleRound) bool {
+	if len(existing) != len(rounds) {
+		return false
+	}
+	for i, round := range rounds {
+		got := existing[i]
+		if got.RoundNo != round.RoundNo || got.Winner != round.Winner || got.Finish != round.Finish ||
+			got.SelfCode != round.SelfCode || got.OpponentCode != round.OpponentCode {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *sf6BattleRepository) ReassignOwnerBySubject(ctx context.Context, guildID, subjectFighterID, newUserID string) (int64, error) {
 	if guildID == "" || subjectFighterID == "" || newUserID == "" {
 		return 0, errors.New("guildID, subjectFighterID, newUserID are required")
@@ -231,7 +349,98 @@ func (r *sf6BattleRepository) ExistingSourceKeys(ctx context.Context, guildID, s
 	return exists, nil
 }
 
-func (r *sf6BattleRepository) StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error) {
+// AssignSessions は session_id が未設定の対戦に、subject/opponent と battle_at が一致するセッションを割り当てる。
+// 複数のセッションに該当する場合は開始が新しいものを優先する。
+func (r *sf6BattleRepository) AssignSessions(ctx context.Context, guildID, subjectFighterID string) (int64, error) {
+	if guildID == "" || subjectFighterID == "" {
+		return 0, errors.New("guildID and subjectFighterID are required")
+	}
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_battles AS b
+         SET session_id = m.session_id, updated_at = now()
+         FROM (
+            SELECT DISTINCT ON (b2.id) b2.id AS battle_id, s.id AS session_id
+            FROM sf6_battles AS b2
+            JOIN sf6_sessions AS s
+              ON s.guild_id = b2.guild_id
+             AND s.subject_fighter_id = b2.subject_fighter_id
+             AND s.opponent_fighter_id = b2.opponent_fighter_id
+             AND b2.battle_at >= s.started_at
+             AND (s.ended_at IS NULL OR b2.battle_at <= s.ended_at)
+            WHERE b2.guild_id = $1 AND b2.subject_fighter_id = $2 AND b2.session_id IS NULL
+            ORDER BY b2.id, s.started_at DESC
+         ) AS m
+         WHERE b.id = m.battle_id`,
+		guildID, subjectFighterID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sf6BattleRepository) StatsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleStatRow, error) {
+	if sessionID == "" {
+		return nil, errors.New("sessionID is required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT self_character, result, COUNT(*)
+         FROM sf6_battles
+         WHERE session_id = $1
+         GROUP BY self_character, result`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.SF6BattleStatRow
+	for rows.Next() {
+		var row domain.SF6BattleStatRow
+		if err := rows.Scan(&row.SelfCharacter, &row.Result, &row.Count); err != nil {
+			return nil, err
+		}
+		stats = append(stats, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// HistoryBySession はセッションに属する対戦を古い順に返す。
+func (r *sf6BattleRepository) HistoryBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleHistoryRow, error) {
+	if sessionID == "" {
+		return nil, errors.New("sessionID is required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT battle_at, battle_type, result, self_character, opponent_character
+         FROM sf6_battles
+         WHERE session_id = $1
+         ORDER BY battle_at ASC, source_key ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6BattleHistoryRow
+	for rows.Next() {
+		var row domain.SF6BattleHistoryRow
+		if err := rows.Scan(&row.BattleAt, &row.BattleType, &row.Result, &row.SelfCharacter, &row.OpponentCharacter); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6BattleRepository) StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error) {
 	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
 	}
@@ -240,8 +449,9 @@ func (r *sf6BattleRepository) StatsByOpponentRange(ctx context.Context, guildID,
          FROM sf6_battles
          WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
            AND battle_at >= $4 AND battle_at < $5
+           AND ($6 = '' OR battle_type = $6)
          GROUP BY self_character, result`,
-		guildID, subjectFighterID, opponentFighterID, startAt, endAt,
+		guildID, subjectFighterID, opponentFighterID, startAt, endAt, battleType,
 	)
 	if err != nil {
 		return nil, err
@@ -262,7 +472,7 @@ func (r *sf6BattleRepository) StatsByOpponentRange(ctx context.Context, guildID,
 	return stats, nil
 }
 
-func (r *sf6BattleRepository) StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit int) ([]domain.SF6BattleStatRow, error) {
+func (r *sf6BattleRepository) StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleStatRow, error) {
 	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
 	}
@@ -275,11 +485,12 @@ func (r *sf6BattleRepository) StatsByOpponentCount(ctx context.Context, guildID,
             SELECT self_character, result
             FROM sf6_battles
             WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+              AND ($5 = '' OR battle_type = $5)
             ORDER BY battle_at DESC
             LIMIT $4
          ) AS recent
          GROUP BY self_character, result`,
-		guildID, subjectFighterID, opponentFighterID, limit,
+		guildID, subjectFighterID, opponentFighterID, limit, battleType,
 	)
 	if err != nil {
 		return nil, err
@@ -300,7 +511,81 @@ func (r *sf6BattleRepository) StatsByOpponentCount(ctx context.Context, guildID,
 	return stats, nil
 }
 
-func (r *sf6BattleRepository) HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit, offset int) ([]domain.SF6BattleHistoryRow, error) {
+// ResultsBySession はセッションに属する対戦の勝敗を古い順に返す。
+func (r *sf6BattleRepository) ResultsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleResult, error) {
+	if sessionID == "" {
+		return nil, errors.New("sessionID is required")
+	}
+	return r.listResults(ctx,
+		`SELECT battle_at, result
+         FROM sf6_battles
+         WHERE session_id = $1
+         ORDER BY battle_at ASC, source_key ASC`,
+		sessionID,
+	)
+}
+
+// ResultsByOpponentRange は期間内の対戦の勝敗を古い順に返す。
+func (r *sf6BattleRepository) ResultsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleResult, error) {
+	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
+	}
+	return r.listResults(ctx,
+		`SELECT battle_at, result
+         FROM sf6_battles
+         WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+           AND battle_at >= $4 AND battle_at < $5
+           AND ($6 = '' OR battle_type = $6)
+         ORDER BY battle_at ASC, source_key ASC`,
+		guildID, subjectFighterID, opponentFighterID, startAt, endAt, battleType,
+	)
+}
+
+// ResultsByOpponentCount は直近 limit 戦の勝敗を古い順に返す。
+func (r *sf6BattleRepository) ResultsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleResult, error) {
+	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	return r.listResults(ctx,
+		`SELECT battle_at, result
+         FROM (
+            SELECT battle_at, result, source_key
+            FROM sf6_battles
+            WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+              AND ($5 = '' OR battle_type = $5)
+            ORDER BY battle_at DESC, source_key DESC
+            LIMIT $4
+         ) AS recent
+         ORDER BY battle_at ASC, source_key ASC`,
+		guildID, subjectFighterID, opponentFighterID, limit, battleType,
+	)
+}
+
+func (r *sf6BattleRepository) listResults(ctx context.Context, query string, args ...interface{}) ([]domain.SF6BattleResult, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6BattleResult
+	for rows.Next() {
+		var row domain.SF6BattleResult
+		if err := rows.Scan(&row.BattleAt, &row.Result); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6BattleRepository) HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit, offset int) ([]domain.SF6BattleHistoryRow, error) {
 	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
 	}
@@ -311,12 +596,13 @@ func (r *sf6BattleRepository) HistoryByOpponent(ctx context.Context, guildID, su
 		return nil, errors.New("offset must be >= 0")
 	}
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT battle_at, result, self_character, opponent_character
+		`SELECT battle_at, battle_type, result, self_character, opponent_character
          FROM sf6_battles
          WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+           AND ($6 = '' OR battle_type = $6)
          ORDER BY battle_at DESC, source_key DESC
          LIMIT $4 OFFSET $5`,
-		guildID, subjectFighterID, opponentFighterID, limit, offset,
+		guildID, subjectFighterID, opponentFighterID, limit, offset, battleType,
 	)
 	if err != nil {
 		return nil, err
@@ -326,7 +612,119 @@ func (r *sf6BattleRepository) HistoryByOpponent(ctx context.Context, guildID, su
 	var out []domain.SF6BattleHistoryRow
 	for rows.Next() {
 		var row domain.SF6BattleHistoryRow
-		if err := rows.Scan(&row.BattleAt, &row.Result, &row.SelfCharacter, &row.OpponentCharacter); err != nil {
+		if err := rows.Scan(&row.BattleAt, &row.BattleType, &row.Result, &row.SelfCharacter, &row.OpponentCharacter); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RoundsBySession はセッションに属する対戦のラウンド結果を対戦の古い順・ラウンド順に返す。
+func (r *sf6BattleRepository) RoundsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleRound, error) {
+	if sessionID == "" {
+		return nil, errors.New("sessionID is required")
+	}
+	return r.listRounds(ctx,
+		`SELECT br.battle_id, br.round_no, br.winner, br.finish, br.self_code, br.opponent_code
+         FROM sf6_battle_rounds br
+         JOIN sf6_battles b ON b.id = br.battle_id
+         WHERE b.session_id = $1
+         ORDER BY b.battle_at ASC, b.source_key ASC, br.round_no ASC`,
+		sessionID,
+	)
+}
+
+// RoundsByOpponentRange は期間内の対戦のラウンド結果を対戦の古い順・ラウンド順に返す。
+func (r *sf6BattleRepository) RoundsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleRound, error) {
+	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
+	}
+	return r.listRounds(ctx,
+		`SELECT br.battle_id, br.round_no, br.winner, br.finish, br.self_code, br.opponent_code
+         FROM sf6_battle_rounds br
+         JOIN sf6_battles b ON b.id = br.battle_id
+         WHERE b.guild_id = $1 AND b.subject_fighter_id = $2 AND b.opponent_fighter_id = $3
+           AND b.battle_at >= $4 AND b.battle_at < $5
+           AND ($6 = '' OR b.battle_type = $6)
+         ORDER BY b.battle_at ASC, b.source_key ASC, br.round_no ASC`,
+		guildID, subjectFighterID, opponentFighterID, startAt, endAt, battleType,
+	)
+}
+
+// RoundsByOpponentCount は直近 limit 戦のラウンド結果を対戦の古い順・ラウンド順に返す。
+func (r *sf6BattleRepository) RoundsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleRound, error) {
+	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	return r.listRounds(ctx,
+		`SELECT br.battle_id, br.round_no, br.winner, br.finish, br.self_code, br.opponent_code
+         FROM (
+            SELECT id, battle_at, source_key
+            FROM sf6_battles
+            WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+              AND ($5 = '' OR battle_type = $5)
+            ORDER BY battle_at DESC, source_key DESC
+            LIMIT $4
+         ) AS recent
+         JOIN sf6_battle_rounds br ON br.battle_id = recent.id
+         ORDER BY recent.battle_at ASC, recent.source_key ASC, br.round_no ASC`,
+		guildID, subjectFighterID, opponentFighterID, limit, battleType,
+	)
+}
+
+func (r *sf6BattleRepository) listRounds(ctx context.Context, query string, args ...interface{}) ([]domain.SF6BattleRound, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6BattleRound
+	for rows.Next() {
+		var row domain.SF6BattleRound
+		if err := rows.Scan(&row.BattleID, &row.RoundNo, &row.Winner, &row.Finish, &row.SelfCode, &row.OpponentCode); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MatchupsByOpponent は自キャラ × 相手キャラごとの試合数と勝敗を返す。
+func (r *sf6BattleRepository) MatchupsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6MatchupRow, error) {
+	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT self_character, opponent_character, COUNT(*),
+                COUNT(*) FILTER (WHERE result = 'win'),
+                COUNT(*) FILTER (WHERE result = 'loss'),
+                COUNT(*) FILTER (WHERE result = 'draw')
+         FROM sf6_battles
+         WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+           AND ($4 = '' OR battle_type = $4)
+         GROUP BY self_character, opponent_character`,
+		guildID, subjectFighterID, opponentFighterID, battleType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6MatchupRow
+	for rows.Next() {
+		var row domain.SF6MatchupRow
+		if err := rows.Scan(&row.SelfCharacter, &row.OpponentCharacter, &row.Games, &row.Wins, &row.Losses, &row.Draws); err != nil {
 			return nil, err
 		}
 		out = append(out, row)
@@ -337,7 +735,7 @@ func (r *sf6BattleRepository) HistoryByOpponent(ctx context.Context, guildID, su
 	return out, nil
 }
 
-func (r *sf6BattleRepository) CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) (int, error) {
+func (r *sf6BattleRepository) CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) (int, error) {
 	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
 		return 0, errors.New("guildID, subjectFighterID, opponentFighterID are required")
 	}
@@ -345,37 +743,166 @@ func (r *sf6BattleRepository) CountByOpponent(ctx context.Context, guildID, subj
 	if err := r.db.QueryRowContext(ctx,
 		`SELECT COUNT(*)
          FROM sf6_battles
-         WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3`,
-		guildID, subjectFighterID, opponentFighterID,
+         WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+           AND ($4 = '' OR battle_type = $4)`,
+		guildID, subjectFighterID, opponentFighterID, battleType,
 	).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *sf6BattleRepository) BattleTimesByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) ([]time.Time, error) {
+// ResultsByOpponent は対戦の勝敗を新しい順にすべて返す。
+func (r *sf6BattleRepository) ResultsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6BattleResult, error) {
 	if guildID == "" || subjectFighterID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, subjectFighterID, opponentFighterID are required")
 	}
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT battle_at
+	return r.listResults(ctx,
+		`SELECT battle_at, result
          FROM sf6_battles
          WHERE guild_id = $1 AND subject_fighter_id = $2 AND opponent_fighter_id = $3
+           AND ($4 = '' OR battle_type = $4)
          ORDER BY battle_at DESC, source_key DESC`,
-		guildID, subjectFighterID, opponentFighterID,
+		guildID, subjectFighterID, opponentFighterID, battleType,
+	)
+}
+
+// LeaderboardGames はギルドの連携メンバーどうしの試合を、メンバーごとに古い順で返す。
+// 片方の側にしか保存されていない試合も両者の分を返し、双方に保存されている試合は 1 件として数える。
+// startAt / endAt がゼロ値ならその側の期間を絞らない。
+func (r *sf6BattleRepository) LeaderboardGames(ctx context.Context, guildID, battleType string, startAt, endAt time.Time) ([]domain.SF6LeaderboardGame, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`WITH members AS (
+            SELECT DISTINCT fighter_id
+            FROM sf6_accounts
+            WHERE guild_id = $1 AND fighter_id <> ''
+         ),
+         battles AS (
+            SELECT subject_fighter_id, opponent_fighter_id, battle_at, result
+            FROM sf6_battles
+            WHERE guild_id = $1
+              AND ($2 = '' OR battle_type = $2)
+              AND ($3::timestamptz IS NULL OR battle_at >= $3)
+              AND ($4::timestamptz IS NULL OR battle_at < $4)
+              AND subject_fighter_id IN (SELECT fighter_id FROM members)
+              AND opponent_fighter_id IN (SELECT fighter_id FROM members)
+              AND subject_fighter_id <> opponent_fighter_id
+         )
+         SELECT DISTINCT ON (fighter_id, battle_at, opponent_fighter_id)
+                fighter_id, opponent_fighter_id, battle_at, result
+         FROM (
+            SELECT subject_fighter_id AS fighter_id, opponent_fighter_id, battle_at, result, 0 AS mirrored
+            FROM battles
+            UNION ALL
+            SELECT opponent_fighter_id, subject_fighter_id, battle_at,
+                   CASE result WHEN 'win' THEN 'loss' WHEN 'loss' THEN 'win' ELSE result END, 1
+            FROM battles
+         ) AS games
+         ORDER BY fighter_id, battle_at, opponent_fighter_id, mirrored`,
+		guildID, battleType, nullIfZeroTime(startAt), nullIfZeroTime(endAt),
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var out []time.Time
+	var out []domain.SF6LeaderboardGame
 	for rows.Next() {
-		var t time.Time
-		if err := rows.Scan(&t); err != nil {
+		var game domain.SF6LeaderboardGame
+		if err := rows.Scan(&game.FighterID, &game.OpponentFighterID, &game.BattleAt, &game.Result); err != nil {
 			return nil, err
 		}
-		out = append(out, t)
+		out = append(out, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecordsBySubject は subject が対戦した相手ごとの試合数と勝敗を返す。
+// 相手側にしか保存されていない試合も結果を反転して数え、双方に保存されている試合は 1 件として数える（LeaderboardGames と同じ）。
+func (r *sf6BattleRepository) RecordsBySubject(ctx context.Context, guildID, subjectFighterID, battleType string) ([]domain.SF6OpponentRecord, error) {
+	if guildID == "" || subjectFighterID == "" {
+		return nil, errors.New("guildID, subjectFighterID are required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`WITH games AS (
+            SELECT DISTINCT ON (opponent_fighter_id, battle_at) opponent_fighter_id, result
+            FROM (
+               SELECT opponent_fighter_id, battle_at, result, 0 AS mirrored
+               FROM sf6_battles
+               WHERE guild_id = $1 AND subject_fighter_id = $2
+                 AND ($3 = '' OR battle_type = $3)
+               UNION ALL
+               SELECT subject_fighter_id, battle_at,
+                      CASE result WHEN 'win' THEN 'loss' WHEN 'loss' THEN 'win' ELSE result END, 1
+               FROM sf6_battles
+               WHERE guild_id = $1 AND opponent_fighter_id = $2
+                 AND ($3 = '' OR battle_type = $3)
+            ) AS g
+            WHERE opponent_fighter_id <> $2
+            ORDER BY opponent_fighter_id, battle_at, mirrored
+         )
+         SELECT opponent_fighter_id, COUNT(*),
+                COUNT(*) FILTER (WHERE result = 'win'),
+                COUNT(*) FILTER (WHERE result = 'loss'),
+                COUNT(*) FILTER (WHERE result = 'draw')
+         FROM games
+         GROUP BY opponent_fighter_id`,
+		guildID, subjectFighterID, battleType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6OpponentRecord
+	for rows.Next() {
+		var record domain.SF6OpponentRecord
+		if err := rows.Scan(&record.OpponentFighterID, &record.Games, &record.Wins, &record.Losses, &record.Draws); err != nil {
+			return nil, err
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecentOpponents は userID の連携アカウントの対戦相手を、最後に対戦した日時の新しい順に返す。
+func (r *sf6BattleRepository) RecentOpponents(ctx context.Context, guildID, userID string, limit int) ([]string, error) {
+	if guildID == "" || userID == "" {
+		return nil, errors.New("guildID and userID are required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT opponent_fighter_id
+         FROM sf6_battles
+         WHERE guild_id = $1 AND user_id = $2 AND owner_kind = 'account'
+         GROUP BY opponent_fighter_id
+         ORDER BY MAX(battle_at) DESC, opponent_fighter_id ASC
+         LIMIT $3`,
+		guildID, userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var fighterID string
+		if err := rows.Scan(&fighterID); err != nil {
+			return nil, err
+		}
+		out = append(out, fighterID)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -430,6 +957,13 @@ func nullIfEmpty(value string) sql.NullString {
 	return sql.NullString{String: value, Valid: true}
 }
 
+func nullIfZeroTime(value time.Time) sql.NullTime {
+	if value.IsZero() {
+		return sql.NullTime{Valid: false}
+	}
+	return sql.NullTime{Time: value, Valid: true}
+}
+
 func nullIfEmptyBytes(value []byte) []byte {
 	if len(value) == 0 {
 		return nil
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"backend/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SF6FeedRepository interface {
+	SetChannel(ctx context.Context, guildID, channelID, userID string) error
+	DeleteChannel(ctx context.Context, guildID string) (int64, error)
+	GetChannel(ctx context.Context, guildID string) (*domain.SF6FeedChannel, error)
+	ListChannels(ctx context.Context) ([]domain.SF6FeedChannel, error)
+	AdvanceCursor(ctx context.Context, guildID string, cursorAt time.Time) error
+	SetOptOut(ctx context.Context, guildID, userID string, optOut bool) error
+	ListOptOuts(ctx context.Context, guildID string) (map[string]struct{}, error)
+	// SettledCutoff は delay より前で、書き込み中のトランザクションが保存しうる行より前の時刻を DB の時計で返す。
+	SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error)
+	// BattlesCreatedBetween は (after, until] に保存された対戦を subject / opponent / battle_at 順に返す。
+	BattlesCreatedBetween(ctx context.Context, guildID string, after, until time.Time) ([]domain.SF6Battle, error)
+}
+
+type sf6FeedRepository struct {
+	db *sql.DB
+}
+
+func NewSF6FeedRepository(db *sql.DB) SF6FeedRepository {
+	return &sf6FeedRepository{db: db}
+}
+
+// SetChannel は投稿先を設定する。通知は設定した時点以降に保存された対戦から始める。
+func (r *sf6FeedRepository) SetChannel(ctx context.Context, guildID, channelID, userID string) error {
+	if guildID == "" || channelID == "" || userID == "" {
+		return errors.New("guildID, channelID, userID are required")
+	}
+	if err := ensureGuildAndUser(ctx, r.db, guildID, userID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sf6_feed_channels (guild_id, channel_id, configured_by)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (guild_id) DO UPDATE SET
+            channel_id = EXCLUDED.channel_id,
+            configured_by = EXCLUDED.configured_by,
+            updated_at = now()`,
+		guildID, channelID, userID,
+	)
+	return err
+}
+
+func (r *sf6FeedRepository) DeleteChannel(ctx context.Context, guildID string) (int64, error) {
+	if guildID == "" {
+		return 0, errors.New("guildID is required")
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM sf6_feed_channels WHERE guild_id = $1`, guildID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sf6FeedRepository) GetChannel(ctx context.Context, guildID string) (*domain.SF6FeedChannel, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	var channel domain.SF6FeedChannel
+	err := r.db.QueryRowContext(ctx,
+		`SELECT guild_id, channel_id, configured_by, cursor_at, updated_at
+         FROM sf6_feed_channels
+         WHERE guild_id = $1`,
+		guildID,
+	).Scan(&channel.GuildID, &channel.ChannelID, &channel.ConfiguredBy, &channel.CursorAt, &channel.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *sf6FeedRepository) ListChannels(ctx context.Context) ([]domain.SF6FeedChannel, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT guild_id, channel_id, configured_by, cursor_at, updated_at
+         FROM sf6_feed_channels
+         ORDER BY guild_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []domain.SF6FeedChannel
+	for rows.Next() {
+		var channel domain.SF6FeedChannel
+		if err := rows.Scan(&channel.GuildID, &channel.ChannelID, &channel.ConfiguredBy, &channel.CursorAt, &channel.UpdatedAt); err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// AdvanceCursor は通知済みの位置を進める。巻き戻しはしない。
+func (r *sf6FeedRepository) AdvanceCursor(ctx context.Context, guildID string, cursorAt time.Time) error {
+	if guildID == "" {
+		return errors.New("guildID is required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_feed_channels
+         SET cursor_at = GREATEST(cursor_at, $2), updated_at = now()
+         WHERE guild_id = $1`,
+		guildID, cursorAt,
+	)
+	return err
+}
+
+func (r *sf6FeedRepository) SetOptOut(ctx context.Context, guildID, userID string, optOut bool) error {
+	if guildID == "" || userID == "" {
+		return errors.New("guildID, userID are required")
+	}
+	if !optOut {
+		_, err := r.db.ExecContext(ctx,
+			`DELETE FROM sf6_feed_optouts WHERE guild_id = $1 AND user_id = $2`,
+			guildID, userID,
+		)
+		return err
+	}
+	if err := ensureGuildAndUser(ctx, r.db, guildID, userID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sf6_feed_optouts (guild_id, user_id)
+         VALUES ($1, $2)
+         ON CONFLICT (guild_id, user_id) DO NOTHING`,
+		guildID, userID,
+	)
+	return err
+}
+
+func (r *sf6FeedRepository) ListOptOuts(ctx context.Context, guildID string) (map[string]struct{}, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id FROM sf6_feed_optouts WHERE guild_id = $1`,
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]struct{})
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		out[userID] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6FeedRepository) SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error) {
+	return settledCutoff(ctx, r.db, delay)
+}
+
+func (r *sf6FeedRepository) BattlesCreatedBetween(ctx context.Context, guildID string, after, until time.Time) ([]domain.SF6Battle, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT guild_id, user_id, battle_type, subject_fighter_id, opponent_fighter_id, battle_at,
+                result, self_character, opponent_character, created_at
+         FROM sf6_battles
+         WHERE guild_id = $1 AND created_at > $2 AND created_at <= $3
+         ORDER BY subject_fighter_id, opponent_fighter_id, battle_at, source_key`,
+		guildID, after, until,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var battles []domain.SF6Battle
+	for rows.Next() {
+		var battle domain.SF6Battle
+		if err := rows.Scan(
+			&battle.GuildID,
+			&battle.UserID,
+			&battle.BattleType,
+			&battle.SubjectFighterID,
+			&battle.OpponentFighterID,
+			&battle.BattleAt,
+			&battle.Result,
+			&battle.SelfCharacter,
+			&battle.OpponentCharacter,
+			&battle.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		battles = append(battles, battle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return battles, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"backend/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SF6FetchRunRepository interface {
+	Insert(ctx context.Context, run domain.SF6FetchRun) error
+	LatestByFighter(ctx context.Context, guildID string) ([]domain.SF6FetchRun, error)
+	RecentFailures(ctx context.Context, guildID string, limit int) ([]domain.SF6FetchRun, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type sf6FetchRunRepository struct {
+	db *sql.DB
+}
+
+func NewSF6FetchRunRepository(db *sql.DB) SF6FetchRunRepository {
+	return &sf6FetchRunRepository{db: db}
+}
+
+const sf6FetchRunColumns = `id, guild_id, fighter_id, trigger, attempt, pages, saved_count, duration_ms,
+            COALESCE(error_class, ''), COALESCE(error_message, ''), started_at`
+
+func (r *sf6FetchRunRepository) Insert(ctx context.Context, run domain.SF6FetchRun) error {
+	if run.GuildID == "" || run.FighterID == "" || run.Trigger == "" {
+		return errors.New("guildID, fighterID, trigger are required")
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO guilds (id) VALUES ($1)
+         ON CONFLICT (id) DO NOTHING`,
+		run.GuildID,
+	); err != nil {
+		return err
+	}
+	attempt := run.Attempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sf6_fetch_runs (
+            guild_id, fighter_id, trigger, attempt, pages, saved_count, duration_ms, error_class, error_message, started_at
+         ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+         )`,
+		run.GuildID,
+		run.FighterID,
+		run.Trigger,
+		attempt,
+		run.Pages,
+		run.Saved,
+		run.Duration.Milliseconds(),
+		nullIfEmpty(run.ErrorClass),
+		nullIfEmpty(run.ErrorMessage),
+		run.StartedAt,
+	)
+	return err
+}
+
+// LatestByFighter はギルド内の fighter ごとに直近の取得を 1 件ずつ返す。
+func (r *sf6FetchRunRepository) LatestByFighter(ctx context.Context, guildID string) ([]domain.SF6FetchRun, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	return r.list(ctx,
+		`SELECT DISTINCT ON (fighter_id) `+sf6FetchRunColumns+`
+         FROM sf6_fetch_runs
+         WHERE guild_id = $1
+         ORDER BY fighter_id, started_at DESC`,
+		guildID,
+	)
+}
+
+// RecentFailures はギルドで失敗した取得を新しい順に返す。
+func (r *sf6FetchRunRepository) RecentFailures(ctx context.Context, guildID string, limit int) ([]domain.SF6FetchRun, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	return r.list(ctx,
+		`SELECT `+sf6FetchRunColumns+`
+         FROM sf6_fetch_runs
+         WHERE guild_id = $1 AND error_class IS NOT NULL
+         ORDER BY started_at DESC
+         LIMIT $2`,
+		guildID, limit,
+	)
+}
+
+// DeleteBefore は保持期間を過ぎた記録を削除する。
+func (r *sf6FetchRunRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM sf6_fetch_runs WHERE started_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sf6FetchRunRepository) list(ctx context.Context, query string, args ...interface{}) ([]domain.SF6FetchRun, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []domain.SF6FetchRun
+	for rows.Next() {
+		var run domain.SF6FetchRun
+		var durationMS int64
+		if err := rows.Scan(
+			&run.ID,
+			&run.GuildID,
+			&run.FighterID,
+			&run.Trigger,
+			&run.Attempt,
+			&run.Pages,
+			&run.Saved,
+			&durationMS,
+			&run.ErrorClass,
+			&run.ErrorMessage,
+			&run.StartedAt,
+		); err != nil {
+			return nil, err
+		}
+		run.Duration = time.Duration(durationMS) * time.Millisecond
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
 func ensureGuildAndUser(ctx context.Context, db *sql.DB, guildID, userID string) error {
@@ -26,3 +27,21 @@ func ensureGuildAndUser(ctx context.Context, db *sql.DB, guildID, userID string)
 	}
 	return nil
 }
+
+// settledCutoff は created_at で行を区切るときの上限を DB の時計で返す。
+// created_at はトランザクション開始時刻なので、書き込み中のトランザクションがあればその開始より前で止める。
+func settledCutoff(ctx context.Context, db *sql.DB, delay time.Duration) (time.Time, error) {
+	var cutoff time.Time
+	err := db.QueryRowContext(ctx,
+		`SELECT LEAST(
+            now() - $1 * interval '1 microsecond',
+            COALESCE(
+               (SELECT min(xact_start) FROM pg_stat_activity
+                WHERE datname = current_database() AND backend_xid IS NOT NULL AND pid <> pg_backend_pid()),
+               'infinity'
+            ) - interval '1 microsecond'
+         )`,
+		delay.Microseconds(),
+	).Scan(&cutoff)
+	return cutoff, err
+}
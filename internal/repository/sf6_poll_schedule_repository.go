@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"backend/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SF6PollScheduleRepository interface {
+	List(ctx context.Context) ([]domain.SF6PollSchedule, error)
+	ListByGuild(ctx context.Context, guildID string) ([]domain.SF6PollSchedule, error)
+	Save(ctx context.Context, schedule domain.SF6PollSchedule) error
+}
+
+type sf6PollScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewSF6PollScheduleRepository(db *sql.DB) SF6PollScheduleRepository {
+	return &sf6PollScheduleRepository{db: db}
+}
+
+func (r *sf6PollScheduleRepository) List(ctx context.Context) ([]domain.SF6PollSchedule, error) {
+	return r.list(ctx,
+		`SELECT guild_id, fighter_id, user_id, interval_seconds, next_poll_at, last_polled_at, last_new_battle_at
+         FROM sf6_poll_schedules
+         ORDER BY next_poll_at`,
+	)
+}
+
+func (r *sf6PollScheduleRepository) ListByGuild(ctx context.Context, guildID string) ([]domain.SF6PollSchedule, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	return r.list(ctx,
+		`SELECT guild_id, fighter_id, user_id, interval_seconds, next_poll_at, last_polled_at, last_new_battle_at
+         FROM sf6_poll_schedules
+         WHERE guild_id = $1
+         ORDER BY next_poll_at`,
+		guildID,
+	)
+}
+
+func (r *sf6PollScheduleRepository) list(ctx context.Context, query string, args ...interface{}) ([]domain.SF6PollSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.SF6PollSchedule
+	for rows.Next() {
+		var schedule domain.SF6PollSchedule
+		var intervalSeconds int
+		if err := rows.Scan(
+			&schedule.GuildID,
+			&schedule.FighterID,
+			&schedule.UserID,
+			&intervalSeconds,
+			&schedule.NextPollAt,
+			&schedule.LastPolledAt,
+			&schedule.LastNewBattleAt,
+		); err != nil {
+			return nil, err
+		}
+		schedule.Interval = time.Duration(intervalSeconds) * time.Second
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *sf6PollScheduleRepository) Save(ctx context.Context, schedule domain.SF6PollSchedule) error {
+	if schedule.GuildID == "" || schedule.FighterID == "" || schedule.UserID == "" {
+		return errors.New("guildID, fighterID, userID are required")
+	}
+	if err := ensureGuildAndUser(ctx, r.db, schedule.GuildID, schedule.UserID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sf6_poll_schedules (
+            guild_id, fighter_id, user_id, interval_seconds, next_poll_at, last_polled_at, last_new_battle_at
+         ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7
+         )
+         ON CONFLICT (guild_id, fighter_id) DO UPDATE SET
+            user_id = EXCLUDED.user_id,
+            interval_seconds = EXCLUDED.interval_seconds,
+            next_poll_at = EXCLUDED.next_poll_at,
+            last_polled_at = EXCLUDED.last_polled_at,
+            last_new_battle_at = EXCLUDED.last_new_battle_at,
+            updated_at = now()`,
+		schedule.GuildID,
+		schedule.FighterID,
+		schedule.UserID,
+		int(schedule.Interval/time.Second),
+		schedule.NextPollAt,
+		schedule.LastPolledAt,
+		schedule.LastNewBattleAt,
+	)
+	return err
+}
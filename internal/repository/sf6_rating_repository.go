@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"backend/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SF6RatingRepository interface {
+	GuildIDs(ctx context.Context) ([]string, error)
+	GetCursor(ctx context.Context, guildID string) (*domain.SF6RatingCursor, error)
+	// SettledCutoff は delay より前で、書き込み中のトランザクションが保存しうる行より前の時刻を DB の時計で返す。
+	SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error)
+	// HasLateBattles は createdAfter より後、createdUntil までに保存された試合のうち、(battleAt, matchKey) 以前のものがあるかを返す。
+	// 反映済みの試合（createdAfter までに保存されていた同じ試合）をもう一方の視点から保存した行は数えない。
+	HasLateBattles(ctx context.Context, guildID string, createdAfter, createdUntil, battleAt time.Time, matchKey string) (bool, error)
+	// BattlesAfter は createdUntil までに保存された試合のうち、(battleAt, matchKey) より後のものを古い順に limit 件返す。
+	// 同じ replay を双方向に保存した行は 1 件にまとめ、同じ 2 人の同時刻の別の試合はそれぞれ返す。
+	BattlesAfter(ctx context.Context, guildID string, createdUntil, battleAt time.Time, matchKey string, limit int) ([]domain.SF6RatingBattle, error)
+	ListRatings(ctx context.Context, guildID string) ([]domain.SF6Rating, error)
+	// Save はレーティングと履歴を書き込み、cursor を進める。cursor.Version が保存済みの値と違えば false を返して何もしない。
+	// reset なら先にギルドのレーティングと履歴を消す。
+	Save(ctx context.Context, cursor domain.SF6RatingCursor, ratings []domain.SF6Rating, changes []domain.SF6RatingChange, reset bool) (bool, error)
+	GetRating(ctx context.Context, guildID, fighterID, character string) (*domain.SF6Rating, error)
+	Leaderboard(ctx context.Context, guildID, character string, limit, offset int) ([]domain.SF6Rating, error)
+	History(ctx context.Context, guildID, fighterID, character string, limit int) ([]domain.SF6RatingChange, error)
+}
+
+type sf6RatingRepository struct {
+	db *sql.DB
+}
+
+func NewSF6RatingRepository(db *sql.DB) SF6RatingRepository {
+	return &sf6RatingRepository{db: db}
+}
+
+const sf6RatingColumns = `guild_id, fighter_id, character, rating, peak, games, wins, losses, draws, last_battle_at`
+
+// sf6RatingMatchKeyExpr は双方の sid を小さい順に並べ、replay を区別する値を続けた対戦キーの式を返す。
+// 双方向に保存された同じ試合は同じキーになる。t は sf6_battles の別名（なければ空）。
+// replay_id がなかった行の source_key は保存した側の視点を含むので、sid の小さい側から見たキャラクターと勝者に置き換える。
+func sf6RatingMatchKeyExpr(t string) string {
+	if t != "" {
+		t += "."
+	}
+	winner := `CASE ` + t + `result WHEN 'win' THEN ` + t + `subject_fighter_id WHEN 'loss' THEN ` + t + `opponent_fighter_id ELSE 'draw' END`
+	return `LEAST(` + t + `subject_fighter_id, ` + t + `opponent_fighter_id) || ':' || GREATEST(` + t + `subject_fighter_id, ` + t + `opponent_fighter_id) || ':' ||
+    CASE WHEN strpos(` + t + `source_key, ':') = 0 THEN ` + t + `source_key
+         WHEN ` + t + `subject_fighter_id <= ` + t + `opponent_fighter_id THEN ` + t + `self_character || '/' || ` + t + `opponent_character || '/' || ` + winner + `
+         ELSE ` + t + `opponent_character || '/' || ` + t + `self_character || '/' || ` + winner + `
+    END`
+}
+
+func (r *sf6RatingRepository) GuildIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT guild_id FROM sf6_battles ORDER BY guild_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var guildID string
+		if err := rows.Scan(&guildID); err != nil {
+			return nil, err
+		}
+		out = append(out, guildID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6RatingRepository) GetCursor(ctx context.Context, guildID string) (*domain.SF6RatingCursor, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	var cursor domain.SF6RatingCursor
+	err := r.db.QueryRowContext(ctx,
+		`SELECT guild_id, battle_at, match_key, checked_at, version
+         FROM sf6_rating_cursors
+         WHERE guild_id = $1`,
+		guildID,
+	).Scan(&cursor.GuildID, &cursor.BattleAt, &cursor.MatchKey, &cursor.CheckedAt, &cursor.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (r *sf6RatingRepository) SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error) {
+	return settledCutoff(ctx, r.db, delay)
+}
+
+func (r *sf6RatingRepository) HasLateBattles(ctx context.Context, guildID string, createdAfter, createdUntil, battleAt time.Time, matchKey string) (bool, error) {
+	if guildID == "" {
+		return false, errors.New("guildID is required")
+	}
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (
+            SELECT 1
+            FROM sf6_battles AS b
+            WHERE b.guild_id = $1 AND b.created_at > $2 AND b.created_at <= $3
+              AND b.battle_at <= $4
+              AND (b.battle_at, `+sf6RatingMatchKeyExpr("b")+`) <= ($4, $5)
+              AND NOT EXISTS (
+                 SELECT 1
+                 FROM sf6_battles AS a
+                 WHERE a.guild_id = b.guild_id AND a.battle_at = b.battle_at AND a.created_at <= $2
+                   AND `+sf6RatingMatchKeyExpr("a")+` = `+sf6RatingMatchKeyExpr("b")+`
+              )
+         )`,
+		guildID, createdAfter, createdUntil, battleAt, matchKey,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *sf6RatingRepository) BattlesAfter(ctx context.Context, guildID string, createdUntil, battleAt time.Time, matchKey string, limit int) ([]domain.SF6RatingBattle, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT DISTINCT ON (battle_at, match_key)
+                subject_fighter_id, opponent_fighter_id, self_character, opponent_character, result, battle_at, match_key
+         FROM (
+            SELECT subject_fighter_id, opponent_fighter_id, self_character, opponent_character, result, battle_at,
+                   `+sf6RatingMatchKeyExpr("")+` AS match_key
+            FROM sf6_battles
+            WHERE guild_id = $1 AND battle_at >= $2 AND created_at <= $5
+         ) AS b
+         WHERE (battle_at, match_key) > ($2, $3)
+         ORDER BY battle_at, match_key, subject_fighter_id
+         LIMIT $4`,
+		guildID, battleAt, matchKey, limit, createdUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6RatingBattle
+	for rows.Next() {
+		var battle domain.SF6RatingBattle
+		if err := rows.Scan(
+			&battle.SubjectFighterID,
+			&battle.OpponentFighterID,
+			&battle.SelfCharacter,
+			&battle.OpponentCharacter,
+			&battle.Result,
+			&battle.BattleAt,
+			&battle.MatchKey,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, battle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6RatingRepository) ListRatings(ctx context.Context, guildID string) ([]domain.SF6Rating, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	return r.listRatings(ctx,
+		`SELECT `+sf6RatingColumns+`
+         FROM sf6_ratings
+         WHERE guild_id = $1`,
+		guildID,
+	)
+}
+
+func (r *sf6RatingRepository) Save(ctx context.Context, cursor domain.SF6RatingCursor, ratings []domain.SF6Rating, changes []domain.SF6RatingChange, reset bool) (bool, error) {
+	if cursor.GuildID == "" {
+		return false, errors.New("guildID is required")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var res sql.Result
+	if cursor.Version == 0 {
+		res, err = tx.ExecContext(ctx,
+			`INSERT INTO sf6_rating_cursors (guild_id, battle_at, match_key, checked_at, version)
+             VALUES ($1, $2, $3, $4, 1)
+             ON CONFLICT (guild_id) DO NOTHING`,
+			cursor.GuildID, cursor.BattleAt, cursor.MatchKey, cursor.CheckedAt,
+		)
+	} else {
+		res, err = tx.ExecContext(ctx,
+			`UPDATE sf6_rating_cursors
+             SET battle_at = $2, match_key = $3, checked_at = $4, version = version + 1, updated_at = now()
+             WHERE guild_id = $1 AND version = $5`,
+			cursor.GuildID, cursor.BattleAt, cursor.MatchKey, cursor.CheckedAt, cursor.Version,
+		)
+	}
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if reset {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sf6_ratings WHERE guild_id = $1`, cursor.GuildID); err != nil {
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sf6_rating_history WHERE guild_id = $1`, cursor.GuildID); err != nil {
+			return false, err
+		}
+	}
+	for _, rating := range ratings {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO sf6_ratings (`+sf6RatingColumns+`)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+             ON CONFLICT (guild_id, fighter_id, character)
+             DO UPDATE SET rating = EXCLUDED.rating,
+                           peak = EXCLUDED.peak,
+                           games = EXCLUDED.games,
+                           wins = EXCLUDED.wins,
+                           losses = EXCLUDED.losses,
+                           draws = EXCLUDED.draws,
+                           last_battle_at = EXCLUDED.last_battle_at,
+                           updated_at = now()`,
+			cursor.GuildID, rating.FighterID, rating.Character, rating.Rating, rating.Peak,
+			rating.Games, rating.Wins, rating.Losses, rating.Draws, rating.LastBattleAt,
+		); err != nil {
+			return false, err
+		}
+	}
+	if len(changes) > 0 {
+		stmt, err := tx.PrepareContext(ctx,
+			`INSERT INTO sf6_rating_history (
+                guild_id, fighter_id, character, opponent_fighter_id, result, battle_at, rating_before, rating_after
+             ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		)
+		if err != nil {
+			return false, err
+		}
+		defer stmt.Close()
+		for _, change := range changes {
+			if _, err := stmt.ExecContext(ctx,
+				cursor.GuildID, change.FighterID, change.Character, change.OpponentFighterID,
+				change.Result, change.BattleAt, change.Before, change.After,
+			); err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *sf6RatingRepository) GetRating(ctx context.Context, guildID, fighterID, character string) (*domain.SF6Rating, error) {
+	if guildID == "" || fighterID == "" {
+		return nil, errors.New("guildID, fighterID are required")
+	}
+	ratings, err := r.listRatings(ctx,
+		`SELECT `+sf6RatingColumns+`
+         FROM sf6_ratings
+         WHERE guild_id = $1 AND fighter_id = $2 AND character = $3`,
+		guildID, fighterID, character,
+	)
+	if err != nil || len(ratings) == 0 {
+		return nil, err
+	}
+	return &ratings[0], nil
+}
+
+func (r *sf6RatingRepository) Leaderboard(ctx context.Context, guildID, character string, limit, offset int) ([]domain.SF6Rating, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must be >= 0")
+	}
+	return r.listRatings(ctx,
+		`SELECT `+sf6RatingColumns+`
+         FROM sf6_ratings
+         WHERE guild_id = $1 AND character = $2
+         ORDER BY rating DESC, games DESC, fighter_id ASC
+         LIMIT $3 OFFSET $4`,
+		guildID, character, limit, offset,
+	)
+}
+
+func (r *sf6RatingRepository) History(ctx context.Context, guildID, fighterID, character string, limit int) ([]domain.SF6RatingChange, error) {
+	if guildID == "" || fighterID == "" {
+		return nil, errors.New("guildID, fighterID are required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT guild_id, fighter_id, character, opponent_fighter_id, result, battle_at, rating_before, rating_after
+         FROM sf6_rating_history
+         WHERE guild_id = $1 AND fighter_id = $2 AND character = $3
+         ORDER BY battle_at DESC, id DESC
+         LIMIT $4`,
+		guildID, fighterID, character, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6RatingChange
+	for rows.Next() {
+		var change domain.SF6RatingChange
+		if err := rows.Scan(
+			&change.GuildID,
+			&change.FighterID,
+			&change.Character,
+			&change.OpponentFighterID,
+			&change.Result,
+			&change.BattleAt,
+			&change.Before,
+			&change.After,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6RatingRepository) listRatings(ctx context.Context, query string, args ...interface{}) ([]domain.SF6Rating, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6Rating
+	for rows.Next() {
+		var rating domain.SF6Rating
+		if err := rows.Scan(
+			&rating.GuildID,
+			&rating.FighterID,
+			&rating.Character,
+			&rating.Rating,
+			&rating.Peak,
+			&rating.Games,
+			&rating.Wins,
+			&rating.Losses,
+			&rating.Draws,
+			&rating.LastBattleAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -9,9 +9,15 @@ import (
 )
 
 type SF6SessionRepository interface {
-	Start(ctx context.Context, guildID, userID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error)
+	Start(ctx context.Context, guildID, userID, subjectFighterID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error)
 	GetActive(ctx context.Context, guildID, userID, opponentFighterID string) (*domain.SF6Session, error)
 	End(ctx context.Context, guildID, userID, opponentFighterID string, endedAt time.Time) (*domain.SF6Session, error)
+	EndByID(ctx context.Context, id string, endedAt time.Time) (*domain.SF6Session, error)
+	ListActive(ctx context.Context) ([]domain.SF6Session, error)
+	GetByID(ctx context.Context, id string) (*domain.SF6Session, error)
+	ListRecent(ctx context.Context, guildID, userID, opponentFighterID string, limit int) ([]domain.SF6Session, error)
+	MarkPolled(ctx context.Context, id string, polledAt time.Time, lastSeenBattleAt *time.Time) error
+	AttachMessage(ctx context.Context, id, channelID, messageID string) error
 }
 
 type sf6SessionRepository struct {
@@ -22,7 +28,33 @@ func NewSF6SessionRepository(db *sql.DB) SF6SessionRepository {
 	return &sf6SessionRepository{db: db}
 }
 
-func (r *sf6SessionRepository) Start(ctx context.Context, guildID, userID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error) {
+const sf6SessionColumns = `id, guild_id, user_id, COALESCE(subject_fighter_id, ''), opponent_fighter_id, status,
+            started_at, ended_at, last_polled_at, last_seen_battle_at, COALESCE(channel_id, ''), COALESCE(message_id, '')`
+
+type sf6SessionScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSF6Session(row sf6SessionScanner) (domain.SF6Session, error) {
+	var session domain.SF6Session
+	err := row.Scan(
+		&session.ID,
+		&session.GuildID,
+		&session.UserID,
+		&session.SubjectFighterID,
+		&session.OpponentFighterID,
+		&session.Status,
+		&session.StartedAt,
+		&session.EndedAt,
+		&session.LastPolledAt,
+		&session.LastSeenBattleAt,
+		&session.ChannelID,
+		&session.MessageID,
+	)
+	return session, err
+}
+
+func (r *sf6SessionRepository) Start(ctx context.Context, guildID, userID, subjectFighterID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error) {
 	if guildID == "" || userID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, userID, opponentFighterID are required")
 	}
@@ -45,17 +77,17 @@ func (r *sf6SessionRepository) Start(ctx context.Context, guildID, userID, oppon
 		return nil, err
 	}
 
-	var session domain.SF6Session
 	row := tx.QueryRowContext(ctx,
 		`INSERT INTO sf6_sessions (
-            guild_id, user_id, opponent_fighter_id, status, started_at, last_polled_at
+            guild_id, user_id, subject_fighter_id, opponent_fighter_id, status, started_at, last_polled_at
          ) VALUES (
-            $1, $2, $3, 'active', $4, $4
+            $1, $2, $3, $4, 'active', $5, $5
          )
-         RETURNING id, guild_id, user_id, opponent_fighter_id, status, started_at`,
-		guildID, userID, opponentFighterID, startedAt,
+         RETURNING `+sf6SessionColumns,
+		guildID, userID, nullIfEmpty(subjectFighterID), opponentFighterID, startedAt,
 	)
-	if err := row.Scan(&session.ID, &session.GuildID, &session.UserID, &session.OpponentFighterID, &session.Status, &session.StartedAt); err != nil {
+	session, err := scanSF6Session(row)
+	if err != nil {
 		return nil, err
 	}
 
@@ -70,15 +102,15 @@ func (r *sf6SessionRepository) GetActive(ctx context.Context, guildID, userID, o
 		return nil, errors.New("guildID, userID, opponentFighterID are required")
 	}
 	row := r.db.QueryRowContext(ctx,
-		`SELECT id, guild_id, user_id, opponent_fighter_id, status, started_at, ended_at
+		`SELECT `+sf6SessionColumns+`
          FROM sf6_sessions
          WHERE guild_id = $1 AND user_id = $2 AND opponent_fighter_id = $3 AND status = 'active'
          ORDER BY started_at DESC
          LIMIT 1`,
 		guildID, userID, opponentFighterID,
 	)
-	var session domain.SF6Session
-	if err := row.Scan(&session.ID, &session.GuildID, &session.UserID, &session.OpponentFighterID, &session.Status, &session.StartedAt, &session.EndedAt); err != nil {
+	session, err := scanSF6Session(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -98,16 +130,136 @@ func (r *sf6SessionRepository) End(ctx context.Context, guildID, userID, opponen
 	if session == nil {
 		return nil, nil
 	}
+	return r.EndByID(ctx, session.ID, endedAt)
+}
+
+func (r *sf6SessionRepository) EndByID(ctx context.Context, id string, endedAt time.Time) (*domain.SF6Session, error) {
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
 	row := r.db.QueryRowContext(ctx,
 		`UPDATE sf6_sessions
          SET status = 'ended', ended_at = $2, updated_at = now()
-         WHERE id = $1
-         RETURNING id, guild_id, user_id, opponent_fighter_id, status, started_at, ended_at`,
-		session.ID, endedAt,
+         WHERE id = $1 AND status = 'active'
+         RETURNING `+sf6SessionColumns,
+		id, endedAt,
 	)
-	var updated domain.SF6Session
-	if err := row.Scan(&updated.ID, &updated.GuildID, &updated.UserID, &updated.OpponentFighterID, &updated.Status, &updated.StartedAt, &updated.EndedAt); err != nil {
+	updated, err := scanSF6Session(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &updated, nil
 }
+
+func (r *sf6SessionRepository) ListActive(ctx context.Context) ([]domain.SF6Session, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+sf6SessionColumns+`
+         FROM sf6_sessions
+         WHERE status = 'active'
+         ORDER BY started_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6Session
+	for rows.Next() {
+		session, err := scanSF6Session(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6SessionRepository) GetByID(ctx context.Context, id string) (*domain.SF6Session, error) {
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+sf6SessionColumns+`
+         FROM sf6_sessions
+         WHERE id = $1`,
+		id,
+	)
+	session, err := scanSF6Session(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListRecent はユーザーのセッションを新しい順に返す。opponentFighterID が空なら相手で絞り込まない。
+func (r *sf6SessionRepository) ListRecent(ctx context.Context, guildID, userID, opponentFighterID string, limit int) ([]domain.SF6Session, error) {
+	if guildID == "" || userID == "" {
+		return nil, errors.New("guildID and userID are required")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+sf6SessionColumns+`
+         FROM sf6_sessions
+         WHERE guild_id = $1 AND user_id = $2
+           AND ($3 = '' OR opponent_fighter_id = $3)
+         ORDER BY started_at DESC
+         LIMIT $4`,
+		guildID, userID, opponentFighterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SF6Session
+	for rows.Next() {
+		session, err := scanSF6Session(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *sf6SessionRepository) MarkPolled(ctx context.Context, id string, polledAt time.Time, lastSeenBattleAt *time.Time) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_sessions
+         SET last_polled_at = $2,
+             last_seen_battle_at = GREATEST(last_seen_battle_at, $3),
+             updated_at = now()
+         WHERE id = $1`,
+		id, polledAt, lastSeenBattleAt,
+	)
+	return err
+}
+
+func (r *sf6SessionRepository) AttachMessage(ctx context.Context, id, channelID, messageID string) error {
+	if id == "" || channelID == "" || messageID == "" {
+		return errors.New("id, channelID, messageID are required")
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sf6_sessions
+         SET channel_id = $2, message_id = $3, updated_at = now()
+         WHERE id = $1`,
+		id, channelID, messageID,
+	)
+	return err
+}
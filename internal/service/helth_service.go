@@ -1,10 +1,12 @@
 package service
 
 import (
+	"backend/internal/buckler"
 	"backend/internal/domain"
 	"backend/internal/repository"
 	"context"
 	"os"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -15,6 +17,18 @@ type HealthService interface {
 	Report(ctx context.Context) domain.HealthReport
 	MarkReady()
 	MarkNotReady()
+	SetBucklerStats(source BucklerStatsSource)
+	SetLeaderStatus(source LeaderStatusSource)
+}
+
+// BucklerStatsSource は Buckler クライアントのリクエスト集計を返す（*buckler.Client が満たす）。
+type BucklerStatsSource interface {
+	RequestStats() buckler.RequestStats
+}
+
+// LeaderStatusSource は定期ジョブのリーダー状況を返す（LeaderElector が満たす）。
+type LeaderStatusSource interface {
+	LeaderStatus(ctx context.Context) domain.LeaderHealth
 }
 
 type healthService struct {
@@ -22,6 +36,10 @@ type healthService struct {
 	// 並行アクセスしても安全に読み書きできるbool
 	readyFlag  atomic.Bool
 	appVersion string
+
+	sourceMu     sync.RWMutex
+	bucklerStats BucklerStatsSource
+	leaderStatus LeaderStatusSource
 }
 
 func NewHealthService(healthRepo repository.HealthRepository) HealthService {
@@ -44,6 +62,20 @@ func (s *healthService) MarkNotReady() {
 	s.readyFlag.Store(false)
 }
 
+// Buckler のリクエスト集計をレポートに含める
+func (s *healthService) SetBucklerStats(source BucklerStatsSource) {
+	s.sourceMu.Lock()
+	defer s.sourceMu.Unlock()
+	s.bucklerStats = source
+}
+
+// 定期ジョブのリーダー状況をレポートに含める
+func (s *healthService) SetLeaderStatus(source LeaderStatusSource) {
+	s.sourceMu.Lock()
+	defer s.sourceMu.Unlock()
+	s.leaderStatus = source
+}
+
 // プロセスが動いてたらOK
 func (s *healthService) Live(ctx context.Context) bool {
 	return true
@@ -66,5 +98,42 @@ func (s *healthService) Report(ctx context.Context) domain.HealthReport {
 		DB:      dbOK,
 		Version: s.appVersion,
 		Time:    time.Now().Format(time.RFC3339),
+		Buckler: s.bucklerReport(),
+		Leader:  s.leaderReport(ctx),
+	}
+}
+
+func (s *healthService) leaderReport(ctx context.Context) *domain.LeaderHealth {
+	s.sourceMu.RLock()
+	source := s.leaderStatus
+	s.sourceMu.RUnlock()
+	if source == nil {
+		return nil
+	}
+	status := source.LeaderStatus(ctx)
+	return &status
+}
+
+func (s *healthService) bucklerReport() *domain.BucklerHealth {
+	s.sourceMu.RLock()
+	source := s.bucklerStats
+	s.sourceMu.RUnlock()
+	if source == nil {
+		return nil
+	}
+	stats := source.RequestStats()
+	report := &domain.BucklerHealth{
+		Requests:     stats.Total,
+		ByHost:       stats.ByHost,
+		Throttled:    stats.Throttled,
+		Rejected:     stats.Rejected,
+		RateLimited:  stats.RateLimited,
+		DailyCount:   stats.DailyCount,
+		DailyCap:     stats.DailyCap,
+		DailyResetAt: stats.DailyResetAt.Format(time.RFC3339),
+	}
+	if stats.BlockedUntil != nil {
+		report.BlockedUntil = stats.BlockedUntil.Format(time.RFC3339)
 	}
+	return report
 }
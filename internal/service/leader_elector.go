@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// 保持者の問い合わせは healthz の応答を遅らせないよう短く打ち切る。
+const leaderHolderTimeout = 500 * time.Millisecond
+
+// ロック解放はシャットダウン中でも行う。
+const leaderReleaseTimeout = 5 * time.Second
+
+// LeaderElectionOptions はリーダー選出の間隔設定。
+type LeaderElectionOptions struct {
+	// RetryInterval は非リーダー時にロック取得を試みる間隔。
+	RetryInterval time.Duration
+	// CheckInterval はリーダー時にロックを保持し続けているか確認する間隔。
+	CheckInterval time.Duration
+}
+
+func (o LeaderElectionOptions) withDefaults() LeaderElectionOptions {
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = 15 * time.Second
+	}
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = 10 * time.Second
+	}
+	return o
+}
+
+// LeaderElector は Postgres の advisory lock で 1 インスタンスだけをリーダーにし、
+// 登録した定期ジョブをリーダーの間だけ実行する。
+type LeaderElector interface {
+	// Go はリーダーの間だけ実行するジョブを登録する。Run より前に呼ぶ。
+	// job はリーダーでなくなると ctx がキャンセルされるので、速やかに戻ること。
+	Go(name string, job func(ctx context.Context))
+	// Run は ctx が終わるまでリーダー選出を続ける。戻る時点でジョブは停止し、ロックは解放済み。
+	Run(ctx context.Context)
+	IsLeader() bool
+	LeaderStatus(ctx context.Context) domain.LeaderHealth
+}
+
+type leaderJob struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type leaderElector struct {
+	lockRepo   repository.LeaderLockRepository
+	lockName   string
+	instanceID string
+	opts       LeaderElectionOptions
+	logger     PollLogger
+
+	mu      sync.RWMutex
+	jobs    []leaderJob
+	leading bool
+	since   time.Time
+}
+
+func NewLeaderElector(lockRepo repository.LeaderLockRepository, lockName, instanceID string, opts LeaderElectionOptions, logger PollLogger) LeaderElector {
+	return &leaderElector{
+		lockRepo:   lockRepo,
+		lockName:   lockName,
+		instanceID: instanceID,
+		opts:       opts.withDefaults(),
+		logger:     logger,
+	}
+}
+
+func (e *leaderElector) Go(name string, job func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.jobs = append(e.jobs, leaderJob{name: name, run: job})
+}
+
+func (e *leaderElector) Run(ctx context.Context) {
+	for {
+		lock, err := e.lockRepo.TryAcquire(ctx, e.lockName, e.instanceID)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Error("leader acquire: ", err)
+		}
+		if lock != nil {
+			e.lead(ctx, lock)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		sleepContext(ctx, e.opts.RetryInterval)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// lead はロックを保持している間ジョブを動かし、ロックを失うか ctx が終わったらジョブを止めてから解放する。
+func (e *leaderElector) lead(ctx context.Context, lock repository.LeaderLock) {
+	e.setLeading(true)
+	e.logger.Infof("leader acquired: lock=%s instance=%s", e.lockName, e.instanceID)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	e.mu.RLock()
+	jobs := append([]leaderJob(nil), e.jobs...)
+	e.mu.RUnlock()
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job leaderJob) {
+			defer wg.Done()
+			job.run(jobCtx)
+			if jobCtx.Err() == nil {
+				e.logger.Infof("leader job exited: %s", job.name)
+			}
+		}(job)
+	}
+
+	ticker := time.NewTicker(e.opts.CheckInterval)
+	defer ticker.Stop()
+	for held := true; held; {
+		select {
+		case <-ctx.Done():
+			held = false
+		case <-ticker.C:
+			if err := lock.Check(ctx); err != nil && ctx.Err() == nil {
+				e.logger.Error("leader lock check: ", err)
+				held = false
+			}
+		}
+	}
+
+	// 次のリーダーとジョブが重ならないよう、ジョブの停止を待ってからロックを外す
+	cancel()
+	wg.Wait()
+	e.setLeading(false)
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), leaderReleaseTimeout)
+	defer cancelRelease()
+	if err := lock.Release(releaseCtx); err != nil {
+		e.logger.Error("leader release: ", err)
+	}
+	e.logger.Infof("leader released: lock=%s instance=%s", e.lockName, e.instanceID)
+}
+
+func (e *leaderElector) setLeading(leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leading = leading
+	if leading {
+		e.since = time.Now()
+	} else {
+		e.since = time.Time{}
+	}
+}
+
+func (e *leaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading
+}
+
+func (e *leaderElector) LeaderStatus(ctx context.Context) domain.LeaderHealth {
+	e.mu.RLock()
+	status := domain.LeaderHealth{
+		Lock:     e.lockName,
+		Instance: e.instanceID,
+		IsLeader: e.leading,
+	}
+	if e.leading {
+		status.Leader = e.instanceID
+		status.Since = e.since.Format(time.RFC3339)
+	}
+	e.mu.RUnlock()
+	if status.IsLeader {
+		return status
+	}
+	ctx, cancel := context.WithTimeout(ctx, leaderHolderTimeout)
+	defer cancel()
+	if holder, err := e.lockRepo.Holder(ctx, e.lockName); err == nil {
+		status.Leader = holder
+	}
+	return status
+}
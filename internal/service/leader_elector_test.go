@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/repository"
+)
+
+// stubLockRepo はプロセス内で 1 つの advisory lock を模倣する。
+type stubLockRepo struct {
+	mu     sync.Mutex
+	holder string
+}
+
+func (r *stubLockRepo) TryAcquire(ctx context.Context, name, instanceID string) (repository.LeaderLock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.holder != "" {
+		return nil, nil
+	}
+	r.holder = instanceID
+	return &stubLock{repo: r, instanceID: instanceID}, nil
+}
+
+func (r *stubLockRepo) Holder(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.holder, nil
+}
+
+// drop は保持者の接続が切れた状況を模倣する。
+func (r *stubLockRepo) drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.holder = ""
+}
+
+type stubLock struct {
+	repo       *stubLockRepo
+	instanceID string
+}
+
+func (l *stubLock) Check(ctx context.Context) error {
+	l.repo.mu.Lock()
+	defer l.repo.mu.Unlock()
+	if l.repo.holder != l.instanceID {
+		return repository.ErrLeaderLockLost
+	}
+	return nil
+}
+
+func (l *stubLock) Release(ctx context.Context) error {
+	l.repo.mu.Lock()
+	defer l.repo.mu.Unlock()
+	if l.repo.holder == l.instanceID {
+		l.repo.holder = ""
+	}
+	return nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLeaderElectorSingleLeaderAndHandover(t *testing.T) {
+	repo := &stubLockRepo{}
+	opts := LeaderElectionOptions{RetryInterval: 10 * time.Millisecond, CheckInterval: 10 * time.Millisecond}
+	var running, maxRunning, starts atomic.Int32
+	job := func(ctx context.Context) {
+		starts.Add(1)
+		n := running.Add(1)
+		for {
+			cur := maxRunning.Load()
+			if n <= cur || maxRunning.CompareAndSwap(cur, n) {
+				break
+			}
+		}
+		<-ctx.Done()
+		running.Add(-1)
+	}
+
+	a := NewLeaderElector(repo, "test", "a", opts, expectErrorLogger{t})
+	b := NewLeaderElector(repo, "test", "b", opts, expectErrorLogger{t})
+	a.Go("job", job)
+	b.Go("job", job)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	go func() { defer close(doneA); a.Run(ctxA) }()
+	waitFor(t, "a to lead", a.IsLeader)
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	doneB := make(chan struct{})
+	go func() { defer close(doneB); b.Run(ctxB) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatal("b became leader while a holds the lock")
+	}
+	if got := b.LeaderStatus(context.Background()); got.Leader != "a" || got.IsLeader {
+		t.Fatalf("b status = %+v, want leader a", got)
+	}
+
+	// a のシャットダウンで b が引き継ぐ
+	cancelA()
+	<-doneA
+	waitFor(t, "b to lead", b.IsLeader)
+	if got := a.LeaderStatus(context.Background()); got.Leader != "b" || got.IsLeader {
+		t.Fatalf("a status = %+v, want leader b", got)
+	}
+
+	// 接続断でロックを失うとジョブを止め、再取得したら再開する
+	repo.drop()
+	waitFor(t, "job restart after lock loss", func() bool { return starts.Load() == 3 && running.Load() == 1 })
+
+	cancelB()
+	<-doneB
+	if running.Load() != 0 {
+		t.Fatalf("jobs still running after shutdown: %d", running.Load())
+	}
+	if maxRunning.Load() != 1 {
+		t.Fatalf("max concurrent jobs = %d, want 1", maxRunning.Load())
+	}
+	if holder, _ := repo.Holder(context.Background(), "test"); holder != "" {
+		t.Fatalf("lock still held by %q after shutdown", holder)
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// ErrSF6BackfillRunning は同じ sid・種別のバックフィルが既に実行中であることを表す。
+var ErrSF6BackfillRunning = errors.New("sf6 backfill already running")
+
+// 進捗の保存は取得の context が終わっていても行う。
+const sf6BackfillSaveTimeout = 10 * time.Second
+
+// 実行中のジョブは DB 上で lease を持ち、heartbeat で延長する。
+// heartbeat が lease を過ぎて途切れたジョブ（落ちたプロセスのもの）は別のプロセスが引き継げる。
+const (
+	sf6BackfillLease             = 2 * time.Minute
+	sf6BackfillHeartbeatInterval = 30 * time.Second
+)
+
+type SF6BackfillService interface {
+	Run(ctx context.Context, guildID, requestUserID, sid string, kinds []buckler.BattlelogKind, restart bool, onProgress func(domain.SF6BackfillJob)) ([]domain.SF6BackfillJob, error)
+	ResumeUnfinished(ctx context.Context, logger PollLogger)
+}
+
+type sf6BackfillService struct {
+	sf6Service   SF6Service
+	backfillRepo repository.SF6BackfillRepository
+	accountRepo  repository.SF6AccountRepository
+}
+
+func NewSF6BackfillService(sf6Service SF6Service, backfillRepo repository.SF6BackfillRepository, accountRepo repository.SF6AccountRepository) SF6BackfillService {
+	return &sf6BackfillService{
+		sf6Service:   sf6Service,
+		backfillRepo: backfillRepo,
+		accountRepo:  accountRepo,
+	}
+}
+
+// Run は sid の battlelog を種別ごとに TotalPage まで遡って保存する。
+// 1 ページごとに進捗を保存するので、中断しても次回は続きのページから再開する。
+// sid が連携アカウントなら連携ユーザーの戦績として、そうでなければ requestUserID のフレンド戦績として保存する。
+func (s *sf6BackfillService) Run(ctx context.Context, guildID, requestUserID, sid string, kinds []buckler.BattlelogKind, restart bool, onProgress func(domain.SF6BackfillJob)) ([]domain.SF6BackfillJob, error) {
+	if guildID == "" || requestUserID == "" || sid == "" {
+		return nil, errors.New("guildID, requestUserID, sid are required")
+	}
+	if len(kinds) == 0 {
+		kinds = []buckler.BattlelogKind{buckler.BattlelogCustom}
+	}
+	userID := requestUserID
+	if s.accountRepo != nil {
+		owner, err := s.accountRepo.GetByFighter(ctx, guildID, sid)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil && owner.UserID != "" {
+			userID = owner.UserID
+		}
+	}
+	jobs := make([]domain.SF6BackfillJob, 0, len(kinds))
+	for _, kind := range kinds {
+		job, err := s.runKind(ctx, guildID, userID, sid, kind, restart, onProgress)
+		if job != nil {
+			jobs = append(jobs, *job)
+		}
+		if err != nil {
+			return jobs, err
+		}
+	}
+	return jobs, nil
+}
+
+// ResumeUnfinished は再起動前に途中だったジョブを順に再開する。失敗したジョブは手動での再実行を待つ。
+// 他のプロセスが lease を持って実行中のジョブは飛ばす。
+func (s *sf6BackfillService) ResumeUnfinished(ctx context.Context, logger PollLogger) {
+	jobs, err := s.backfillRepo.ListUnfinished(ctx)
+	if err != nil {
+		logger.Error("sf6 backfill list unfinished: ", err)
+		return
+	}
+	for _, job := range jobs {
+		logger.Infof("sf6 backfill resume: guild=%s sid=%s kind=%s next_page=%d", job.GuildID, job.SubjectFighterID, job.BattleType, job.NextPage)
+		done, err := s.runKind(ctx, job.GuildID, job.UserID, job.SubjectFighterID, buckler.BattlelogKind(job.BattleType), false, nil)
+		if err != nil {
+			if errors.Is(err, ErrSF6BackfillRunning) {
+				continue
+			}
+			logger.Error("sf6 backfill resume: ", err)
+			if IsSF6FetchFatal(err) || ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		logger.Infof("sf6 backfill done: guild=%s sid=%s kind=%s pages=%d saved=%d", done.GuildID, done.SubjectFighterID, done.BattleType, done.TotalPage, done.SavedCount)
+	}
+}
+
+func (s *sf6BackfillService) runKind(ctx context.Context, guildID, userID, sid string, kind buckler.BattlelogKind, restart bool, onProgress func(domain.SF6BackfillJob)) (*domain.SF6BackfillJob, error) {
+	job, err := s.backfillRepo.Start(ctx, guildID, userID, sid, string(kind), restart, sf6BackfillLease)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrSF6BackfillRunning
+	}
+	stopHeartbeat := s.keepAlive(ctx, job.ID)
+	defer stopHeartbeat()
+	report := func() {
+		if onProgress != nil {
+			onProgress(*job)
+		}
+	}
+	report()
+
+	for page := job.NextPage; job.TotalPage == 0 || page <= job.TotalPage; page++ {
+		saved, totalPage, err := s.sf6Service.FetchAndStoreBattlePage(ctx, guildID, userID, sid, kind, page)
+		if err != nil {
+			// 中断・一時的なエラーは paused として残し、次回（再起動時を含む）に続きから再開する
+			job.Status = domain.SF6BackfillFailed
+			if ctx.Err() != nil || IsSF6FetchFatal(err) {
+				job.Status = domain.SF6BackfillPaused
+			}
+			job.LastError = err.Error()
+			if ferr := s.finish(ctx, job); ferr != nil {
+				err = fmt.Errorf("%w (save status: %v)", err, ferr)
+			}
+			report()
+			return job, err
+		}
+		job.NextPage = page + 1
+		job.TotalPage = totalPage
+		job.SavedCount += saved
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sf6BackfillSaveTimeout)
+		err = s.backfillRepo.Checkpoint(saveCtx, job.ID, job.NextPage, job.TotalPage, saved)
+		cancel()
+		if err != nil {
+			return job, err
+		}
+		report()
+		if totalPage == 0 {
+			break
+		}
+	}
+	job.Status = domain.SF6BackfillDone
+	job.LastError = ""
+	if err := s.finish(ctx, job); err != nil {
+		return job, err
+	}
+	report()
+	return job, nil
+}
+
+func (s *sf6BackfillService) finish(ctx context.Context, job *domain.SF6BackfillJob) error {
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sf6BackfillSaveTimeout)
+	defer cancel()
+	return s.backfillRepo.Finish(saveCtx, job.ID, job.Status, job.LastError)
+}
+
+// keepAlive はジョブが終わるまで定期的に heartbeat を送る。返り値の関数で止める。
+// 送信に失敗しても次の周期で再送し、lease 内に届けば引き継がれることはない。
+func (s *sf6BackfillService) keepAlive(ctx context.Context, id string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(sf6BackfillHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = s.backfillRepo.Heartbeat(ctx, id)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/buckler/bucklertest"
+	"backend/internal/domain"
+	"backend/internal/repository"
+	"backend/internal/repository/repositorytest"
+)
+
+// stubBackfillRepo は複数のプロセスから共有される DB のように、running のジョブを heartbeat の lease で保護する。
+type stubBackfillRepo struct {
+	repository.SF6BackfillRepository
+	mu         sync.Mutex
+	jobs       map[string]*domain.SF6BackfillJob
+	heartbeats map[string]time.Time
+}
+
+func (r *stubBackfillRepo) Start(ctx context.Context, guildID, userID, subjectFighterID, battleType string, restart bool, lease time.Duration) (*domain.SF6BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := guildID + ":" + subjectFighterID + ":" + battleType
+	job, ok := r.jobs[key]
+	if ok && job.Status == domain.SF6BackfillRunning && time.Since(r.heartbeats[key]) < lease {
+		return nil, nil
+	}
+	if !ok || restart || job.Status == domain.SF6BackfillDone {
+		job = &domain.SF6BackfillJob{ID: key, GuildID: guildID, SubjectFighterID: subjectFighterID, BattleType: battleType, NextPage: 1}
+		r.jobs[key] = job
+	}
+	job.UserID = userID
+	job.Status = domain.SF6BackfillRunning
+	r.heartbeats[key] = time.Now()
+	out := *job
+	return &out, nil
+}
+
+func (r *stubBackfillRepo) Heartbeat(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.jobs[id].Status == domain.SF6BackfillRunning {
+		r.heartbeats[id] = time.Now()
+	}
+	return nil
+}
+
+func (r *stubBackfillRepo) ListUnfinished(ctx context.Context) ([]domain.SF6BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.SF6BackfillJob
+	for _, job := range r.jobs {
+		if job.Status == domain.SF6BackfillRunning || job.Status == domain.SF6BackfillPaused {
+			out = append(out, *job)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubBackfillRepo) Checkpoint(ctx context.Context, id string, nextPage, totalPage, saved int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job := r.jobs[id]
+	job.NextPage, job.TotalPage = nextPage, totalPage
+	job.SavedCount += saved
+	r.heartbeats[id] = time.Now()
+	return nil
+}
+
+func (r *stubBackfillRepo) Finish(ctx context.Context, id, status, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[id].Status = status
+	r.jobs[id].LastError = lastError
+	return nil
+}
+
+func newStubBackfillRepo() *stubBackfillRepo {
+	return &stubBackfillRepo{jobs: make(map[string]*domain.SF6BackfillJob), heartbeats: make(map[string]time.Time)}
+}
+
+func TestSF6BackfillResumesFromCheckpoint(t *testing.T) {
+	client, srv := newFakeBucklerClient(t)
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: bucklertest.FixtureSID},
+	}}
+	battleRepo := repositorytest.NewSF6BattleRepo()
+	backfillRepo := newStubBackfillRepo()
+	svc := NewSF6BackfillService(NewSF6Service(client, battleRepo, accountRepo, 0), backfillRepo, accountRepo)
+	ctx := context.Background()
+	kinds := []buckler.BattlelogKind{buckler.BattlelogCustom}
+
+	// 1 ページ目の保存後にメンテナンスになった想定
+	failed := false
+	jobs, err := svc.Run(ctx, "g1", "someone", bucklertest.FixtureSID, kinds, false, func(job domain.SF6BackfillJob) {
+		if job.NextPage == 2 && !failed {
+			failed = true
+			srv.FailBattlelog(1, http.StatusServiceUnavailable, "")
+		}
+	})
+	if !errors.Is(err, buckler.ErrMaintenance) {
+		t.Fatalf("Run() error = %v, want ErrMaintenance", err)
+	}
+	if len(jobs) != 1 || jobs[0].Status != domain.SF6BackfillPaused || jobs[0].NextPage != 2 || jobs[0].UserID != "u1" {
+		t.Fatalf("paused job = %+v", jobs)
+	}
+
+	jobs, err = svc.Run(ctx, "g1", "someone", bucklertest.FixtureSID, kinds, false, nil)
+	if err != nil {
+		t.Fatalf("Run() resume error = %v", err)
+	}
+	if jobs[0].Status != domain.SF6BackfillDone || jobs[0].TotalPage != 2 || jobs[0].SavedCount != 12 {
+		t.Fatalf("resumed job = %+v, want done with 2 pages / 12 saved", jobs[0])
+	}
+	// page 1, page 2（失敗）, page 2 の 3 回だけで、1 ページ目は取り直さない
+	if got := srv.RequestCount("battlelog"); got != 3 {
+		t.Fatalf("battlelog requests = %d, want 3", got)
+	}
+}
+
+// TestSF6BackfillRespectsClaimAcrossProcesses は別プロセスが実行中のジョブを、コマンドからも再開処理からも取らないことを確かめる。
+// heartbeat が途切れたジョブは引き継いで最後まで進める。
+func TestSF6BackfillRespectsClaimAcrossProcesses(t *testing.T) {
+	client, srv := newFakeBucklerClient(t)
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: bucklertest.FixtureSID},
+	}}
+	battleRepo := repositorytest.NewSF6BattleRepo()
+	backfillRepo := newStubBackfillRepo()
+	key := "g1:" + bucklertest.FixtureSID + ":" + string(buckler.BattlelogCustom)
+	// 別のプロセスが 2 ページ目を取得中
+	backfillRepo.jobs[key] = &domain.SF6BackfillJob{
+		ID: key, GuildID: "g1", UserID: "u1", SubjectFighterID: bucklertest.FixtureSID,
+		BattleType: string(buckler.BattlelogCustom), Status: domain.SF6BackfillRunning, NextPage: 2, TotalPage: 2,
+	}
+	backfillRepo.heartbeats[key] = time.Now()
+	svc := NewSF6BackfillService(NewSF6Service(client, battleRepo, accountRepo, 0), backfillRepo, accountRepo)
+	ctx := context.Background()
+
+	if _, err := svc.Run(ctx, "g1", "u1", bucklertest.FixtureSID, []buckler.BattlelogKind{buckler.BattlelogCustom}, false, nil); !errors.Is(err, ErrSF6BackfillRunning) {
+		t.Fatalf("Run() error = %v, want ErrSF6BackfillRunning", err)
+	}
+	svc.ResumeUnfinished(ctx, testPollLogger{t})
+	if got := srv.RequestCount("battlelog"); got != 0 {
+		t.Fatalf("battlelog requests = %d, want 0 while claimed", got)
+	}
+
+	// lease 切れ（プロセスが落ちた）なら再開処理が引き継ぐ
+	backfillRepo.mu.Lock()
+	backfillRepo.heartbeats[key] = time.Now().Add(-sf6BackfillLease - time.Second)
+	backfillRepo.mu.Unlock()
+	svc.ResumeUnfinished(ctx, testPollLogger{t})
+	if job := backfillRepo.jobs[key]; job.Status != domain.SF6BackfillDone || job.NextPage != 3 {
+		t.Fatalf("resumed job = %+v, want done at page 3", job)
+	}
+	if got := srv.RequestCount("battlelog"); got != 1 {
+		t.Fatalf("battlelog requests = %d, want 1", got)
+	}
+}
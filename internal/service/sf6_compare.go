@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"backend/internal/domain"
+)
+
+func (s *sf6Service) Compare(ctx context.Context, guildID, fighterA, fighterB, battleType string) (*domain.SF6Comparison, error) {
+	if s.battleRepo == nil {
+		return nil, errors.New("battle repo not configured")
+	}
+	if fighterA == fighterB {
+		return nil, errors.New("fighterA and fighterB must differ")
+	}
+	recordsA, err := s.battleRepo.RecordsBySubject(ctx, guildID, fighterA, battleType)
+	if err != nil {
+		return nil, err
+	}
+	recordsB, err := s.battleRepo.RecordsBySubject(ctx, guildID, fighterB, battleType)
+	if err != nil {
+		return nil, err
+	}
+	return BuildSF6Comparison(fighterA, fighterB, recordsA, recordsB), nil
+}
+
+// BuildSF6Comparison は 2 人が両方とも対戦したことのある相手だけを取り出して比べる。
+// 比べる 2 人どうしの対戦は共通の相手に含めない。
+func BuildSF6Comparison(fighterA, fighterB string, recordsA, recordsB []domain.SF6OpponentRecord) *domain.SF6Comparison {
+	cmp := &domain.SF6Comparison{FighterA: fighterA, FighterB: fighterB}
+	byOpponentB := make(map[string]domain.SF6OpponentRecord, len(recordsB))
+	for _, record := range recordsB {
+		byOpponentB[record.OpponentFighterID] = record
+	}
+	for _, a := range recordsA {
+		if a.OpponentFighterID == fighterA || a.OpponentFighterID == fighterB {
+			continue
+		}
+		b, ok := byOpponentB[a.OpponentFighterID]
+		if !ok || a.Games == 0 || b.Games == 0 {
+			continue
+		}
+		leader := compareSF6WinRate(a, b)
+		cmp.Rows = append(cmp.Rows, domain.SF6ComparisonRow{OpponentFighterID: a.OpponentFighterID, A: a, B: b, Leader: leader})
+		addSF6Record(&cmp.TotalA, a)
+		addSF6Record(&cmp.TotalB, b)
+		switch leader {
+		case 1:
+			cmp.BetterA++
+		case -1:
+			cmp.BetterB++
+		default:
+			cmp.Even++
+		}
+	}
+	sort.Slice(cmp.Rows, func(x, y int) bool {
+		gx := cmp.Rows[x].A.Games + cmp.Rows[x].B.Games
+		gy := cmp.Rows[y].A.Games + cmp.Rows[y].B.Games
+		if gx != gy {
+			return gx > gy
+		}
+		return cmp.Rows[x].OpponentFighterID < cmp.Rows[y].OpponentFighterID
+	})
+	return cmp
+}
+
+// SF6ComparisonLeader は共通の相手に対してどちらが上かを返す（1: A / -1: B / 0: 互角）。
+// 合計の勝率で比べ、同じなら相手ごとに上回った数で比べる。
+func SF6ComparisonLeader(cmp *domain.SF6Comparison) int {
+	if cmp == nil {
+		return 0
+	}
+	if result := compareSF6WinRate(cmp.TotalA, cmp.TotalB); result != 0 {
+		return result
+	}
+	switch {
+	case cmp.BetterA > cmp.BetterB:
+		return 1
+	case cmp.BetterA < cmp.BetterB:
+		return -1
+	}
+	return 0
+}
+
+func addSF6Record(total *domain.SF6OpponentRecord, record domain.SF6OpponentRecord) {
+	total.Games += record.Games
+	total.Wins += record.Wins
+	total.Losses += record.Losses
+	total.Draws += record.Draws
+}
+
+// compareSF6WinRate は引き分けを除いた勝率を比べる。勝敗のついた試合が無い側は比べない。
+func compareSF6WinRate(a, b domain.SF6OpponentRecord) int {
+	da, db := a.Wins+a.Losses, b.Wins+b.Losses
+	if da == 0 || db == 0 {
+		return 0
+	}
+	// 割り算の誤差を避けるため、a.Wins/da と b.Wins/db を掛け算で比べる
+	left, right := a.Wins*db, b.Wins*da
+	switch {
+	case left > right:
+		return 1
+	case left < right:
+		return -1
+	}
+	return 0
+}
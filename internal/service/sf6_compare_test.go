@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"backend/internal/domain"
+)
+
+func testOpponentRecord(opponent string, wins, losses, draws int) domain.SF6OpponentRecord {
+	return domain.SF6OpponentRecord{
+		OpponentFighterID: opponent,
+		Games:             wins + losses + draws,
+		Wins:              wins,
+		Losses:            losses,
+		Draws:             draws,
+	}
+}
+
+func TestBuildSF6Comparison(t *testing.T) {
+	recordsA := []domain.SF6OpponentRecord{
+		testOpponentRecord("x", 6, 4, 0),
+		testOpponentRecord("y", 1, 1, 1),
+		testOpponentRecord("z", 3, 0, 0), // B は未対戦
+		testOpponentRecord("b", 2, 2, 0), // 比べる相手どうし
+	}
+	recordsB := []domain.SF6OpponentRecord{
+		testOpponentRecord("x", 2, 6, 0),
+		testOpponentRecord("y", 2, 2, 0),
+		testOpponentRecord("w", 5, 0, 0),
+		testOpponentRecord("a", 2, 2, 0),
+	}
+
+	cmp := BuildSF6Comparison("a", "b", recordsA, recordsB)
+	if len(cmp.Rows) != 2 || cmp.Rows[0].OpponentFighterID != "x" || cmp.Rows[1].OpponentFighterID != "y" {
+		t.Fatalf("rows = %+v", cmp.Rows)
+	}
+	if cmp.Rows[0].Leader != 1 || cmp.Rows[1].Leader != 0 {
+		t.Fatalf("leaders = %d/%d, want 1/0", cmp.Rows[0].Leader, cmp.Rows[1].Leader)
+	}
+	if cmp.TotalA.Wins != 7 || cmp.TotalA.Losses != 5 || cmp.TotalA.Draws != 1 || cmp.TotalB.Wins != 4 || cmp.TotalB.Losses != 8 {
+		t.Fatalf("totals = %+v / %+v", cmp.TotalA, cmp.TotalB)
+	}
+	if cmp.BetterA != 1 || cmp.BetterB != 0 || cmp.Even != 1 {
+		t.Fatalf("better = %d/%d/%d, want 1/0/1", cmp.BetterA, cmp.BetterB, cmp.Even)
+	}
+	if SF6ComparisonLeader(cmp) != 1 {
+		t.Fatal("A should lead")
+	}
+}
+
+func TestSF6ComparisonLeaderTieBreak(t *testing.T) {
+	// 合計の勝率が同じなら、相手ごとに上回った数で決める
+	cmp := BuildSF6Comparison("a", "b",
+		[]domain.SF6OpponentRecord{testOpponentRecord("x", 3, 1, 0), testOpponentRecord("y", 2, 1, 0), testOpponentRecord("z", 0, 1, 0)},
+		[]domain.SF6OpponentRecord{testOpponentRecord("x", 2, 1, 0), testOpponentRecord("y", 1, 1, 0), testOpponentRecord("z", 2, 1, 0)},
+	)
+	if compareSF6WinRate(cmp.TotalA, cmp.TotalB) != 0 || cmp.BetterA != 2 || cmp.BetterB != 1 {
+		t.Fatalf("cmp = %+v", cmp)
+	}
+	if SF6ComparisonLeader(cmp) != 1 {
+		t.Fatal("A should lead on per-opponent wins")
+	}
+
+	even := BuildSF6Comparison("a", "b",
+		[]domain.SF6OpponentRecord{testOpponentRecord("x", 3, 1, 0), testOpponentRecord("y", 1, 1, 0)},
+		[]domain.SF6OpponentRecord{testOpponentRecord("x", 2, 2, 0), testOpponentRecord("y", 2, 0, 0)},
+	)
+	if SF6ComparisonLeader(even) != 0 {
+		t.Fatalf("even = %+v", even)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/buckler/bucklertest"
+	"backend/internal/domain"
+	"backend/internal/repository/repositorytest"
+)
+
+type testPollLogger struct{ t *testing.T }
+
+func (l testPollLogger) Infof(format string, args ...interface{}) { l.t.Logf(format, args...) }
+func (l testPollLogger) Error(args ...interface{})                { l.t.Error(args...) }
+
+func newFakeBucklerClient(t *testing.T) (*buckler.Client, *bucklertest.Server) {
+	t.Helper()
+	srv := bucklertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetClientEnv(t)
+	cfg, err := buckler.LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+	c, err := buckler.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, srv
+}
+
+// TestSF6PollerAndHandlersShareClient はポーラーと複数のコマンドが同じクライアントを同時に使う状況を再現する。
+// go test -race で実行すること。
+func TestSF6PollerAndHandlersShareClient(t *testing.T) {
+	client, srv := newFakeBucklerClient(t)
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: bucklertest.FixtureSID},
+		{GuildID: "g2", UserID: "u2", FighterID: bucklertest.FixtureSID},
+	}}
+	battleRepo := repositorytest.NewSF6BattleRepo()
+	svc := NewSF6Service(client, battleRepo, accountRepo, 0)
+	kinds := []buckler.BattlelogKind{buckler.BattlelogCustom, buckler.BattlelogRank}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		opts := SF6PollOptions{MaxPages: 2, Kinds: kinds, Workers: 2}
+		if _, err := runSF6PollOnce(ctx, time.Now(), opts, accountRepo, nil, nil, nil, nil, svc, testPollLogger{t}); err != nil {
+			t.Errorf("runSF6PollOnce() error = %v", err)
+		}
+	}()
+	for i := 0; i < 6; i++ {
+		account := accountRepo.Accounts[i%len(accountRepo.Accounts)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := svc.FetchAndStoreCustomBattles(ctx, account.GuildID, account.UserID, account.FighterID, 1); err != nil {
+				t.Errorf("FetchAndStoreCustomBattles() error = %v", err)
+			}
+		}()
+	}
+	// 途中でセッションが失効しても再ログインは 1 回で済む
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.ExpireSessions()
+	}()
+	wg.Wait()
+
+	if got := srv.LoginCount(); got < 1 || got > 2 {
+		t.Fatalf("LoginCount = %d, want 1 or 2", got)
+	}
+	// ギルドごとに custom の 1 ページ目（10 件）と rank（3 件）は必ず保存される。
+	// custom の 2 ページ目は、先にコマンド側が 1 ページ目を保存していればポーラーが打ち切るので不定。
+	if got := len(battleRepo.Battles()); got < 26 || got > 30 {
+		t.Fatalf("stored battles = %d, want 26..30", got)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// 保存中のトランザクションの行を取りこぼさないよう、直近この時間内に保存された対戦は次回に回す。
+const sf6FeedSettleDelay = 5 * time.Second
+
+// SF6FeedNotifier は新着対戦のまとめを投稿する。Discord 側で実装する。
+type SF6FeedNotifier interface {
+	PostSF6Feed(ctx context.Context, group domain.SF6FeedGroup) error
+}
+
+type SF6FeedService interface {
+	SetChannel(ctx context.Context, guildID, channelID, userID string) error
+	DisableChannel(ctx context.Context, guildID string) (bool, error)
+	GetChannel(ctx context.Context, guildID string) (*domain.SF6FeedChannel, error)
+	SetOptOut(ctx context.Context, guildID, userID string, optOut bool) error
+	IsOptedOut(ctx context.Context, guildID, userID string) (bool, error)
+	// NotifyNewBattles は前回以降に保存された対戦を subject / opponent ごとにまとめて投稿し、投稿数を返す。
+	NotifyNewBattles(ctx context.Context, now time.Time, notifier SF6FeedNotifier, logger PollLogger) (int, error)
+}
+
+type sf6FeedService struct {
+	feedRepo    repository.SF6FeedRepository
+	accountRepo repository.SF6AccountRepository
+	// maxAge より前に行われた対戦（バックフィルで遡って保存したものなど）は通知しない。
+	maxAge time.Duration
+}
+
+func NewSF6FeedService(feedRepo repository.SF6FeedRepository, accountRepo repository.SF6AccountRepository, maxAge time.Duration) SF6FeedService {
+	return &sf6FeedService{feedRepo: feedRepo, accountRepo: accountRepo, maxAge: maxAge}
+}
+
+func (s *sf6FeedService) SetChannel(ctx context.Context, guildID, channelID, userID string) error {
+	return s.feedRepo.SetChannel(ctx, guildID, channelID, userID)
+}
+
+func (s *sf6FeedService) DisableChannel(ctx context.Context, guildID string) (bool, error) {
+	deleted, err := s.feedRepo.DeleteChannel(ctx, guildID)
+	return deleted > 0, err
+}
+
+func (s *sf6FeedService) GetChannel(ctx context.Context, guildID string) (*domain.SF6FeedChannel, error) {
+	return s.feedRepo.GetChannel(ctx, guildID)
+}
+
+func (s *sf6FeedService) SetOptOut(ctx context.Context, guildID, userID string, optOut bool) error {
+	return s.feedRepo.SetOptOut(ctx, guildID, userID, optOut)
+}
+
+func (s *sf6FeedService) IsOptedOut(ctx context.Context, guildID, userID string) (bool, error) {
+	optOuts, err := s.feedRepo.ListOptOuts(ctx, guildID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := optOuts[userID]
+	return ok, nil
+}
+
+func (s *sf6FeedService) NotifyNewBattles(ctx context.Context, now time.Time, notifier SF6FeedNotifier, logger PollLogger) (int, error) {
+	if notifier == nil {
+		return 0, nil
+	}
+	channels, err := s.feedRepo.ListChannels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// created_at は DB の時計なので、区切りも DB で決める
+	until, err := s.feedRepo.SettledCutoff(ctx, sf6FeedSettleDelay)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, channel := range channels {
+		if ctx.Err() != nil {
+			return posted, ctx.Err()
+		}
+		if !until.After(channel.CursorAt) {
+			continue
+		}
+		groups, err := s.collectGroups(ctx, channel, until, now)
+		if err != nil {
+			logger.Error("sf6 feed collect: guild="+channel.GuildID+": ", err)
+			continue
+		}
+		for _, group := range groups {
+			// 投稿先の削除や権限不足で失敗しても、同じ対戦を繰り返し投稿しないようカーソルは進める
+			if err := notifier.PostSF6Feed(ctx, group); err != nil {
+				if ctx.Err() != nil {
+					return posted, ctx.Err()
+				}
+				logger.Error("sf6 feed post: guild="+channel.GuildID+": ", err)
+				continue
+			}
+			posted++
+		}
+		if err := s.feedRepo.AdvanceCursor(ctx, channel.GuildID, until); err != nil {
+			logger.Error("sf6 feed advance cursor: guild="+channel.GuildID+": ", err)
+		}
+	}
+	return posted, nil
+}
+
+// collectGroups は (cursor, until] に保存された対戦を subject / opponent ごとにまとめる。
+// どちらかの持ち主が通知を拒否している組み合わせ、maxAge より古い対戦は除く。
+// 両者とも連携アカウントで同じ試合が双方向に保存されている場合は subject の sid が小さい側だけを残す。
+func (s *sf6FeedService) collectGroups(ctx context.Context, channel domain.SF6FeedChannel, until, now time.Time) ([]domain.SF6FeedGroup, error) {
+	battles, err := s.feedRepo.BattlesCreatedBetween(ctx, channel.GuildID, channel.CursorAt, until)
+	if err != nil || len(battles) == 0 {
+		return nil, err
+	}
+	optOuts, err := s.feedRepo.ListOptOuts(ctx, channel.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]string)
+	if s.accountRepo != nil {
+		accounts, err := s.accountRepo.ListByGuild(ctx, channel.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			owners[account.FighterID] = account.UserID
+		}
+	}
+	optedOut := func(userID string) bool {
+		_, ok := optOuts[userID]
+		return userID != "" && ok
+	}
+
+	type pairKey struct{ subject, opponent string }
+	var order []pairKey
+	groups := make(map[pairKey]*domain.SF6FeedGroup)
+	for _, battle := range battles {
+		if s.maxAge > 0 && battle.BattleAt.Before(now.Add(-s.maxAge)) {
+			continue
+		}
+		if optedOut(battle.UserID) || optedOut(owners[battle.SubjectFighterID]) || optedOut(owners[battle.OpponentFighterID]) {
+			continue
+		}
+		key := pairKey{battle.SubjectFighterID, battle.OpponentFighterID}
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.SF6FeedGroup{
+				GuildID:           channel.GuildID,
+				ChannelID:         channel.ChannelID,
+				SubjectFighterID:  battle.SubjectFighterID,
+				OpponentFighterID: battle.OpponentFighterID,
+				SubjectUserID:     battle.UserID,
+			}
+			groups[key] = group
+			order = append(order, key)
+		}
+		group.Battles = append(group.Battles, domain.SF6BattleHistoryRow{
+			BattleAt:          battle.BattleAt,
+			BattleType:        battle.BattleType,
+			Result:            battle.Result,
+			SelfCharacter:     battle.SelfCharacter,
+			OpponentCharacter: battle.OpponentCharacter,
+		})
+	}
+
+	out := make([]domain.SF6FeedGroup, 0, len(order))
+	for _, key := range order {
+		if _, mirrored := groups[pairKey{key.opponent, key.subject}]; mirrored && key.subject > key.opponent {
+			continue
+		}
+		out = append(out, *groups[key])
+	}
+	return out, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+	"backend/internal/repository/repositorytest"
+)
+
+type stubFeedRepo struct {
+	repository.SF6FeedRepository
+	channels []domain.SF6FeedChannel
+	optOuts  map[string]struct{}
+	battles  []domain.SF6Battle
+	queried  []time.Time
+	advanced map[string]time.Time
+	// dbNow は DB の時計。アプリの時計とずれていてもカーソルはこちらで決まる。
+	dbNow time.Time
+}
+
+func (r *stubFeedRepo) SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error) {
+	return r.dbNow.Add(-delay), nil
+}
+
+func (r *stubFeedRepo) ListChannels(ctx context.Context) ([]domain.SF6FeedChannel, error) {
+	return r.channels, nil
+}
+
+func (r *stubFeedRepo) ListOptOuts(ctx context.Context, guildID string) (map[string]struct{}, error) {
+	return r.optOuts, nil
+}
+
+func (r *stubFeedRepo) BattlesCreatedBetween(ctx context.Context, guildID string, after, until time.Time) ([]domain.SF6Battle, error) {
+	r.queried = append(r.queried, after, until)
+	return r.battles, nil
+}
+
+func (r *stubFeedRepo) AdvanceCursor(ctx context.Context, guildID string, cursorAt time.Time) error {
+	if r.advanced == nil {
+		r.advanced = make(map[string]time.Time)
+	}
+	r.advanced[guildID] = cursorAt
+	return nil
+}
+
+type stubFeedNotifier struct {
+	groups []domain.SF6FeedGroup
+}
+
+func (n *stubFeedNotifier) PostSF6Feed(ctx context.Context, group domain.SF6FeedGroup) error {
+	n.groups = append(n.groups, group)
+	return nil
+}
+
+func TestNotifyNewBattlesGroupsPairsAndSkipsOptOuts(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cursor := now.Add(-10 * time.Minute)
+	battle := func(userID, subject, opponent string, at time.Time) domain.SF6Battle {
+		return domain.SF6Battle{GuildID: "g1", UserID: userID, SubjectFighterID: subject, OpponentFighterID: opponent, BattleAt: at, Result: "win"}
+	}
+	feedRepo := &stubFeedRepo{
+		channels: []domain.SF6FeedChannel{{GuildID: "g1", ChannelID: "c1", CursorAt: cursor}},
+		dbNow:    now.Add(-time.Minute),
+		optOuts:  map[string]struct{}{"u3": {}},
+		battles: []domain.SF6Battle{
+			battle("u1", "100", "200", now.Add(-3*time.Minute)),
+			battle("u1", "100", "200", now.Add(-2*time.Minute)),
+			// 両者とも連携しているので逆向きにも保存されている
+			battle("u2", "200", "100", now.Add(-3*time.Minute)),
+			battle("u2", "200", "100", now.Add(-2*time.Minute)),
+			// 対戦相手の持ち主が拒否している
+			battle("u1", "100", "300", now.Add(-time.Minute)),
+			// 古すぎる対戦（バックフィル）
+			battle("u1", "100", "400", now.Add(-48*time.Hour)),
+		},
+	}
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: "100"},
+		{GuildID: "g1", UserID: "u2", FighterID: "200"},
+		{GuildID: "g1", UserID: "u3", FighterID: "300"},
+	}}
+	notifier := &stubFeedNotifier{}
+	svc := NewSF6FeedService(feedRepo, accountRepo, 24*time.Hour)
+
+	posted, err := svc.NotifyNewBattles(context.Background(), now, notifier, testPollLogger{t})
+	if err != nil {
+		t.Fatalf("NotifyNewBattles: %v", err)
+	}
+	if posted != 1 || len(notifier.groups) != 1 {
+		t.Fatalf("posted=%d groups=%+v, want 1 group", posted, notifier.groups)
+	}
+	group := notifier.groups[0]
+	if group.SubjectFighterID != "100" || group.OpponentFighterID != "200" || group.ChannelID != "c1" || len(group.Battles) != 2 {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	until := feedRepo.dbNow.Add(-sf6FeedSettleDelay)
+	if len(feedRepo.queried) != 2 || !feedRepo.queried[0].Equal(cursor) || !feedRepo.queried[1].Equal(until) {
+		t.Fatalf("queried range = %v, want (%v, %v]", feedRepo.queried, cursor, until)
+	}
+	if got := feedRepo.advanced["g1"]; !got.Equal(until) {
+		t.Fatalf("cursor advanced to %v, want %v", got, until)
+	}
+}
+
+func TestNotifyNewBattlesSkipsSubjectOwnerOptOut(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	feedRepo := &stubFeedRepo{
+		channels: []domain.SF6FeedChannel{{GuildID: "g1", ChannelID: "c1", CursorAt: now.Add(-time.Hour)}},
+		dbNow:    now,
+		optOuts:  map[string]struct{}{"u1": {}},
+		battles: []domain.SF6Battle{
+			{GuildID: "g1", UserID: "u1", SubjectFighterID: "100", OpponentFighterID: "200", BattleAt: now.Add(-time.Minute)},
+		},
+	}
+	notifier := &stubFeedNotifier{}
+	svc := NewSF6FeedService(feedRepo, &repositorytest.SF6AccountRepo{}, 24*time.Hour)
+
+	posted, err := svc.NotifyNewBattles(context.Background(), now, notifier, testPollLogger{t})
+	if err != nil {
+		t.Fatalf("NotifyNewBattles: %v", err)
+	}
+	if posted != 0 || len(notifier.groups) != 0 {
+		t.Fatalf("posted=%d, want nothing for opted-out user", posted)
+	}
+	if _, ok := feedRepo.advanced["g1"]; !ok {
+		t.Fatalf("cursor should advance even when nothing is posted")
+	}
+}
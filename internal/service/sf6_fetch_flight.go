@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend/internal/buckler"
+)
+
+// 取得結果をキャッシュする期間の既定値。
+const defaultSF6FetchFreshness = 30 * time.Second
+
+// sf6FetchKey は同じ取得とみなす単位。保存先がギルドごとなので guildID も含める。
+type sf6FetchKey struct {
+	guildID string
+	sid     string
+	kind    buckler.BattlelogKind
+	page    int
+}
+
+type sf6FetchResult struct {
+	count       int
+	allExisting bool
+	err         error
+}
+
+type sf6FetchCall struct {
+	done   chan struct{}
+	result sf6FetchResult
+}
+
+// sf6FetchGroup は同じキーの取得を 1 本にまとめ、直近に取得済みのキーは Buckler を呼ばずに済ませる。
+type sf6FetchGroup struct {
+	mu        sync.Mutex
+	calls     map[sf6FetchKey]*sf6FetchCall
+	fetchedAt map[sf6FetchKey]time.Time
+	freshness time.Duration
+	now       func() time.Time
+}
+
+func newSF6FetchGroup(freshness time.Duration) *sf6FetchGroup {
+	return &sf6FetchGroup{
+		calls:     make(map[sf6FetchKey]*sf6FetchCall),
+		fetchedAt: make(map[sf6FetchKey]time.Time),
+		freshness: freshness,
+		now:       time.Now,
+	}
+}
+
+// do は fn を実行する。同じキーの実行中の呼び出しがあればその結果を待つ。
+// 待っていた側は保存件数を 0 として受け取る（同じ保存を二重に数えないため）。
+// 先行した呼び出しがその context の終了で失敗した場合、自分の context が生きていればやり直す。
+func (g *sf6FetchGroup) do(ctx context.Context, key sf6FetchKey, fn func() (int, bool, error)) (int, bool, error) {
+	for {
+		g.mu.Lock()
+		if g.isFreshLocked(key) {
+			g.mu.Unlock()
+			return 0, true, nil
+		}
+		if call, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return 0, false, ctx.Err()
+			case <-call.done:
+			}
+			res := call.result
+			if isContextError(res.err) && ctx.Err() == nil {
+				continue
+			}
+			return 0, res.allExisting, res.err
+		}
+		call := &sf6FetchCall{done: make(chan struct{})}
+		g.calls[key] = call
+		g.mu.Unlock()
+
+		count, allExisting, err := fn()
+		call.result = sf6FetchResult{count: count, allExisting: allExisting, err: err}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		if err == nil {
+			g.markFetchedLocked(key)
+		}
+		g.mu.Unlock()
+		close(call.done)
+		return count, allExisting, err
+	}
+}
+
+func (g *sf6FetchGroup) isFreshLocked(key sf6FetchKey) bool {
+	if g.freshness <= 0 {
+		return false
+	}
+	at, ok := g.fetchedAt[key]
+	return ok && g.now().Sub(at) < g.freshness
+}
+
+// markFetchedLocked は取得時刻を記録し、期限切れの記録を掃除する。
+func (g *sf6FetchGroup) markFetchedLocked(key sf6FetchKey) {
+	if g.freshness <= 0 {
+		return
+	}
+	now := g.now()
+	for k, at := range g.fetchedAt {
+		if now.Sub(at) >= g.freshness {
+			delete(g.fetchedAt, k)
+		}
+	}
+	g.fetchedAt[key] = now
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/buckler"
+)
+
+func TestSF6FetchGroupCoalescesConcurrentCalls(t *testing.T) {
+	// 先行呼び出しの完了後に来た呼び出しも鮮度の範囲内なので fn は 1 回だけになる
+	g := newSF6FetchGroup(time.Minute)
+	key := sf6FetchKey{guildID: "g", sid: "1", kind: buckler.BattlelogCustom, page: 1}
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	const workers = 5
+	var wg sync.WaitGroup
+	var totalSaved atomic.Int32
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count, _, err := g.do(context.Background(), key, func() (int, bool, error) {
+				calls.Add(1)
+				<-release
+				return 3, false, nil
+			})
+			if err != nil {
+				t.Errorf("do() error = %v", err)
+			}
+			totalSaved.Add(int32(count))
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("fn calls = %d, want 1", got)
+	}
+	if got := totalSaved.Load(); got != 3 {
+		t.Fatalf("total saved = %d, want 3 (only the leader reports saved rows)", got)
+	}
+}
+
+func TestSF6FetchGroupFreshnessWindow(t *testing.T) {
+	g := newSF6FetchGroup(30 * time.Second)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+	key := sf6FetchKey{guildID: "g", sid: "1", kind: buckler.BattlelogCustom, page: 1}
+	calls := 0
+	fn := func() (int, bool, error) {
+		calls++
+		return 1, false, nil
+	}
+
+	if _, _, err := g.do(context.Background(), key, fn); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	count, allExisting, err := g.do(context.Background(), key, fn)
+	if err != nil || count != 0 || !allExisting {
+		t.Fatalf("fresh do() = (%d, %v, %v), want (0, true, nil)", count, allExisting, err)
+	}
+	other := key
+	other.page = 2
+	if _, _, err := g.do(context.Background(), other, fn); err != nil {
+		t.Fatalf("do(page=2) error = %v", err)
+	}
+	now = now.Add(31 * time.Second)
+	if _, _, err := g.do(context.Background(), key, fn); err != nil {
+		t.Fatalf("do() after window error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("fn calls = %d, want 3", calls)
+	}
+}
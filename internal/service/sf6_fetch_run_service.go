@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// 取得失敗の分類（sf6_fetch_runs.error_class）。
+const (
+	SF6FetchErrorAuth        = "auth"
+	SF6FetchErrorRateLimited = "rate_limited"
+	SF6FetchErrorMaintenance = "maintenance"
+	SF6FetchErrorNotFound    = "not_found"
+	SF6FetchErrorSchema      = "schema_changed"
+	SF6FetchErrorTimeout     = "timeout"
+	SF6FetchErrorCanceled    = "canceled"
+	SF6FetchErrorOther       = "other"
+)
+
+// 失敗の一覧に出す件数。
+const sf6FetchStatusFailureLimit = 5
+
+// 記録の保存は取得の context が終わっていても行う。
+const sf6FetchRunSaveTimeout = 5 * time.Second
+
+// エラーメッセージは長すぎると表示も保存も邪魔になるので切り詰める。
+const sf6FetchRunMaxErrorLen = 500
+
+type SF6FetchRunService interface {
+	Record(ctx context.Context, run domain.SF6FetchRun) error
+	Status(ctx context.Context, guildID string) (*domain.SF6FetchStatus, error)
+}
+
+type sf6FetchRunService struct {
+	runRepo      repository.SF6FetchRunRepository
+	scheduleRepo repository.SF6PollScheduleRepository
+}
+
+func NewSF6FetchRunService(runRepo repository.SF6FetchRunRepository, scheduleRepo repository.SF6PollScheduleRepository) SF6FetchRunService {
+	return &sf6FetchRunService{runRepo: runRepo, scheduleRepo: scheduleRepo}
+}
+
+func (s *sf6FetchRunService) Record(ctx context.Context, run domain.SF6FetchRun) error {
+	return recordSF6FetchRun(ctx, s.runRepo, run)
+}
+
+// Status は fighter ごとの直近の取得と次回予定、直近の失敗をまとめる。
+// 一度も取得していない fighter も、定期取得の予定があれば含める。
+func (s *sf6FetchRunService) Status(ctx context.Context, guildID string) (*domain.SF6FetchStatus, error) {
+	if guildID == "" {
+		return nil, errors.New("guildID is required")
+	}
+	latest, err := s.runRepo.LatestByFighter(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := s.runRepo.RecentFailures(ctx, guildID, sf6FetchStatusFailureLimit)
+	if err != nil {
+		return nil, err
+	}
+	var schedules []domain.SF6PollSchedule
+	if s.scheduleRepo != nil {
+		if schedules, err = s.scheduleRepo.ListByGuild(ctx, guildID); err != nil {
+			return nil, err
+		}
+	}
+
+	byFighter := make(map[string]*domain.SF6FetchFighterStatus, len(latest)+len(schedules))
+	get := func(fighterID string) *domain.SF6FetchFighterStatus {
+		status, ok := byFighter[fighterID]
+		if !ok {
+			status = &domain.SF6FetchFighterStatus{FighterID: fighterID}
+			byFighter[fighterID] = status
+		}
+		return status
+	}
+	for idx := range latest {
+		get(latest[idx].FighterID).LastRun = &latest[idx]
+	}
+	for _, schedule := range schedules {
+		status := get(schedule.FighterID)
+		next := schedule.NextPollAt
+		status.NextPollAt = &next
+		status.Interval = schedule.Interval
+	}
+
+	out := &domain.SF6FetchStatus{Failures: failures}
+	for _, status := range byFighter {
+		out.Fighters = append(out.Fighters, *status)
+	}
+	// 直近に取得したものから並べ、未取得は後ろにまとめる
+	sort.Slice(out.Fighters, func(a, b int) bool {
+		ra, rb := out.Fighters[a].LastRun, out.Fighters[b].LastRun
+		switch {
+		case ra != nil && rb != nil && !ra.StartedAt.Equal(rb.StartedAt):
+			return ra.StartedAt.After(rb.StartedAt)
+		case (ra == nil) != (rb == nil):
+			return ra != nil
+		}
+		return out.Fighters[a].FighterID < out.Fighters[b].FighterID
+	})
+	return out, nil
+}
+
+// SF6FetchErrorClass は取得エラーを記録用の分類に変換する。nil なら空文字。
+func SF6FetchErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, buckler.ErrNotAuthenticated):
+		return SF6FetchErrorAuth
+	case errors.Is(err, buckler.ErrRateLimited):
+		return SF6FetchErrorRateLimited
+	case errors.Is(err, buckler.ErrMaintenance):
+		return SF6FetchErrorMaintenance
+	case errors.Is(err, buckler.ErrNotFound):
+		return SF6FetchErrorNotFound
+	case errors.Is(err, buckler.ErrBuildIDDrift), errors.Is(err, buckler.ErrSchemaChanged):
+		return SF6FetchErrorSchema
+	case errors.Is(err, context.DeadlineExceeded):
+		return SF6FetchErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return SF6FetchErrorCanceled
+	}
+	return SF6FetchErrorOther
+}
+
+// NewSF6FetchRun は取得結果から記録を組み立てる。
+func NewSF6FetchRun(trigger, guildID, fighterID string, attempt, pages, saved int, startedAt time.Time, err error) domain.SF6FetchRun {
+	run := domain.SF6FetchRun{
+		GuildID:    guildID,
+		FighterID:  fighterID,
+		Trigger:    trigger,
+		Attempt:    attempt,
+		Pages:      pages,
+		Saved:      saved,
+		Duration:   time.Since(startedAt),
+		ErrorClass: SF6FetchErrorClass(err),
+		StartedAt:  startedAt,
+	}
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > sf6FetchRunMaxErrorLen {
+			// マルチバイト文字の途中で切ると保存時に不正な UTF-8 になるので、文字の境界まで戻す
+			end := sf6FetchRunMaxErrorLen
+			for end > 0 && !utf8.RuneStart(msg[end]) {
+				end--
+			}
+			msg = msg[:end]
+		}
+		run.ErrorMessage = msg
+	}
+	return run
+}
+
+// recordSF6FetchRun は記録を保存する。runRepo が nil なら何もしない。
+func recordSF6FetchRun(ctx context.Context, runRepo repository.SF6FetchRunRepository, run domain.SF6FetchRun) error {
+	if runRepo == nil {
+		return nil
+	}
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sf6FetchRunSaveTimeout)
+	defer cancel()
+	return runRepo.Insert(saveCtx, run)
+}
+
+// RunSF6FetchRunPruner は interval ごとに retention より古い取得記録を削除する。
+func RunSF6FetchRunPruner(ctx context.Context, interval, retention time.Duration, runRepo repository.SF6FetchRunRepository, logger PollLogger) {
+	if interval <= 0 || retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := runRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			logger.Error("sf6 fetch run prune: ", err)
+		} else if deleted > 0 {
+			logger.Infof("sf6 fetch run prune: deleted=%d", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+type stubFetchRunRepo struct {
+	repository.SF6FetchRunRepository
+	mu   sync.Mutex
+	runs []domain.SF6FetchRun
+}
+
+func (r *stubFetchRunRepo) Insert(ctx context.Context, run domain.SF6FetchRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *stubFetchRunRepo) LatestByFighter(ctx context.Context, guildID string) ([]domain.SF6FetchRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	latest := make(map[string]domain.SF6FetchRun)
+	for _, run := range r.runs {
+		if run.GuildID != guildID {
+			continue
+		}
+		if prev, ok := latest[run.FighterID]; !ok || run.StartedAt.After(prev.StartedAt) {
+			latest[run.FighterID] = run
+		}
+	}
+	out := make([]domain.SF6FetchRun, 0, len(latest))
+	for _, run := range latest {
+		out = append(out, run)
+	}
+	return out, nil
+}
+
+func (r *stubFetchRunRepo) RecentFailures(ctx context.Context, guildID string, limit int) ([]domain.SF6FetchRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.SF6FetchRun
+	for idx := len(r.runs) - 1; idx >= 0 && len(out) < limit; idx-- {
+		if run := r.runs[idx]; run.GuildID == guildID && run.ErrorClass != "" {
+			out = append(out, run)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubScheduleRepo) ListByGuild(ctx context.Context, guildID string) ([]domain.SF6PollSchedule, error) {
+	all, _ := r.List(ctx)
+	var out []domain.SF6PollSchedule
+	for _, schedule := range all {
+		if schedule.GuildID == guildID {
+			out = append(out, schedule)
+		}
+	}
+	return out, nil
+}
+
+func TestSF6FetchErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("fetch: %w", buckler.ErrNotAuthenticated), SF6FetchErrorAuth},
+		{buckler.ErrRateLimited, SF6FetchErrorRateLimited},
+		{buckler.ErrMaintenance, SF6FetchErrorMaintenance},
+		{buckler.ErrNotFound, SF6FetchErrorNotFound},
+		{buckler.ErrBuildIDDrift, SF6FetchErrorSchema},
+		{context.DeadlineExceeded, SF6FetchErrorTimeout},
+		{context.Canceled, SF6FetchErrorCanceled},
+		{errors.New("boom"), SF6FetchErrorOther},
+	}
+	for _, tt := range tests {
+		if got := SF6FetchErrorClass(tt.err); got != tt.want {
+			t.Errorf("SF6FetchErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNewSF6FetchRunTruncatesOnRuneBoundary(t *testing.T) {
+	// 先頭 1 バイトずらして、上限が 3 バイト文字の途中に来るようにする
+	msg := "x" + strings.Repeat("あ", sf6FetchRunMaxErrorLen)
+	run := NewSF6FetchRun(domain.SF6FetchTriggerPoller, "g1", "sid", 1, 0, 0, time.Now(), errors.New(msg))
+	if len(run.ErrorMessage) > sf6FetchRunMaxErrorLen {
+		t.Fatalf("len(ErrorMessage) = %d, want <= %d", len(run.ErrorMessage), sf6FetchRunMaxErrorLen)
+	}
+	if !utf8.ValidString(run.ErrorMessage) {
+		t.Fatalf("ErrorMessage is not valid UTF-8: %q", run.ErrorMessage[len(run.ErrorMessage)-3:])
+	}
+	if want := sf6FetchRunMaxErrorLen - 1; len(run.ErrorMessage) != want {
+		t.Fatalf("len(ErrorMessage) = %d, want %d", len(run.ErrorMessage), want)
+	}
+}
+
+func TestSF6FetchRunStatusMergesSchedules(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	runRepo := &stubFetchRunRepo{runs: []domain.SF6FetchRun{
+		{GuildID: "g1", FighterID: "100", Trigger: domain.SF6FetchTriggerPoller, StartedAt: now.Add(-2 * time.Hour)},
+		{GuildID: "g1", FighterID: "100", Trigger: domain.SF6FetchTriggerManual, StartedAt: now.Add(-time.Hour), ErrorClass: SF6FetchErrorMaintenance},
+		{GuildID: "g1", FighterID: "200", Trigger: domain.SF6FetchTriggerStats, StartedAt: now.Add(-30 * time.Minute)},
+		{GuildID: "g2", FighterID: "300", Trigger: domain.SF6FetchTriggerPoller, StartedAt: now},
+	}}
+	scheduleRepo := &stubScheduleRepo{schedules: map[string]domain.SF6PollSchedule{
+		"g1:100": {GuildID: "g1", FighterID: "100", Interval: time.Hour, NextPollAt: now.Add(time.Hour)},
+		"g1:400": {GuildID: "g1", FighterID: "400", Interval: 4 * time.Hour, NextPollAt: now},
+	}}
+
+	status, err := NewSF6FetchRunService(runRepo, scheduleRepo).Status(ctx, "g1")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	var order []string
+	for _, fighter := range status.Fighters {
+		order = append(order, fighter.FighterID)
+	}
+	// 直近に取得したものから、未取得（予定のみ）は最後
+	if fmt.Sprint(order) != "[200 100 400]" {
+		t.Fatalf("fighters = %v, want [200 100 400]", order)
+	}
+	if got := status.Fighters[1]; got.LastRun.Trigger != domain.SF6FetchTriggerManual || got.NextPollAt == nil || got.Interval != time.Hour {
+		t.Fatalf("fighter 100 = %+v", got)
+	}
+	if status.Fighters[2].LastRun != nil || status.Fighters[2].NextPollAt == nil {
+		t.Fatalf("fighter 400 = %+v, want schedule only", status.Fighters[2])
+	}
+	if len(status.Failures) != 1 || status.Failures[0].FighterID != "100" {
+		t.Fatalf("failures = %+v", status.Failures)
+	}
+}
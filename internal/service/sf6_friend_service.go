@@ -5,6 +5,7 @@ import (
 	"backend/internal/repository"
 	"context"
 	"errors"
+	"strings"
 )
 
 type SF6FriendService interface {
@@ -12,6 +13,7 @@ type SF6FriendService interface {
 	Delete(ctx context.Context, guildID, userID, fighterID string) error
 	List(ctx context.Context, guildID, userID string) ([]domain.SF6Friend, error)
 	ListByGuild(ctx context.Context, guildID string) ([]domain.SF6Friend, error)
+	SuggestOpponents(ctx context.Context, guildID, userID, query string, limit int) ([]domain.SF6OpponentSuggestion, error)
 }
 
 type sf6FriendService struct {
@@ -78,3 +80,109 @@ func (s *sf6FriendService) ListByGuild(ctx context.Context, guildID string) ([]d
 	}
 	return s.friendRepo.ListByGuild(ctx, guildID)
 }
+
+// 入力候補のために見る直近の対戦相手の数。
+const sf6SuggestRecentOpponents = 50
+
+// SuggestOpponents は userID の opponent_code 入力候補を、フレンド・ギルドの連携メンバー・直近の対戦相手から探す。
+func (s *sf6FriendService) SuggestOpponents(ctx context.Context, guildID, userID, query string, limit int) ([]domain.SF6OpponentSuggestion, error) {
+	if guildID == "" || userID == "" {
+		return nil, errors.New("guildID and userID are required")
+	}
+	friends, err := s.friendRepo.List(ctx, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []domain.SF6Account
+	if s.accountRepo != nil {
+		if accounts, err = s.accountRepo.ListByGuild(ctx, guildID); err != nil {
+			return nil, err
+		}
+	}
+	var recent []string
+	if s.battleRepo != nil {
+		if recent, err = s.battleRepo.RecentOpponents(ctx, guildID, userID, sf6SuggestRecentOpponents); err != nil {
+			return nil, err
+		}
+	}
+	return BuildSF6OpponentSuggestions(userID, query, friends, accounts, recent, limit), nil
+}
+
+// BuildSF6OpponentSuggestions は fighter_id・別名・表示名に query を含む候補を、
+// 前方一致 → フレンド → 連携メンバー → 直近の対戦相手の順に最大 limit 件返す。
+// userID 自身の連携アカウントは対戦相手にならないので除く。
+func BuildSF6OpponentSuggestions(userID, query string, friends []domain.SF6Friend, accounts []domain.SF6Account, recent []string, limit int) []domain.SF6OpponentSuggestion {
+	type candidate struct {
+		suggestion domain.SF6OpponentSuggestion
+		keys       []string
+	}
+	self := make(map[string]struct{})
+	for _, acc := range accounts {
+		if acc.UserID == userID {
+			self[acc.FighterID] = struct{}{}
+		}
+	}
+	var candidates []candidate
+	index := make(map[string]int)
+	add := func(fighterID, name, source string, keys ...string) {
+		if fighterID == "" {
+			return
+		}
+		if _, ok := self[fighterID]; ok {
+			return
+		}
+		if idx, ok := index[fighterID]; ok {
+			if candidates[idx].suggestion.Name == "" {
+				candidates[idx].suggestion.Name = name
+			}
+			candidates[idx].keys = append(candidates[idx].keys, keys...)
+			return
+		}
+		index[fighterID] = len(candidates)
+		candidates = append(candidates, candidate{
+			suggestion: domain.SF6OpponentSuggestion{FighterID: fighterID, Name: name, Source: source},
+			keys:       append([]string{fighterID}, keys...),
+		})
+	}
+
+	for _, friend := range friends {
+		name := friend.Alias
+		if name == "" {
+			name = friend.DisplayName
+		}
+		add(friend.FighterID, name, domain.SF6OpponentSourceFriend, friend.Alias, friend.DisplayName)
+	}
+	for _, acc := range accounts {
+		add(acc.FighterID, acc.DisplayName, domain.SF6OpponentSourceMember, acc.DisplayName)
+	}
+	for _, fighterID := range recent {
+		add(fighterID, "", domain.SF6OpponentSourceRecent)
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var prefix, partial []domain.SF6OpponentSuggestion
+	for _, c := range candidates {
+		matched, head := query == "", query == ""
+		for _, key := range c.keys {
+			key = strings.ToLower(key)
+			if key == "" || !strings.Contains(key, query) {
+				continue
+			}
+			matched = true
+			if strings.HasPrefix(key, query) {
+				head = true
+			}
+		}
+		switch {
+		case head:
+			prefix = append(prefix, c.suggestion)
+		case matched:
+			partial = append(partial, c.suggestion)
+		}
+	}
+	out := append(prefix, partial...)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
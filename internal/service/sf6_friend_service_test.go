@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"backend/internal/domain"
+)
+
+func suggestionIDs(suggestions []domain.SF6OpponentSuggestion) []string {
+	ids := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		ids = append(ids, s.FighterID)
+	}
+	return ids
+}
+
+func TestBuildSF6OpponentSuggestions(t *testing.T) {
+	friends := []domain.SF6Friend{
+		{FighterID: "1001", Alias: "Sakura", DisplayName: "sak_main"},
+		{FighterID: "1002", DisplayName: "Ken Master"},
+		{FighterID: "9000", Alias: "Sub Ken"}, // 自分の連携アカウントをフレンド登録している
+	}
+	accounts := []domain.SF6Account{
+		{UserID: "me", FighterID: "9000", DisplayName: "Myself"},
+		{UserID: "u2", FighterID: "2001", DisplayName: "Kenji"},
+		{UserID: "u3", FighterID: "1001", DisplayName: "Sakura Linked"}, // フレンドと重複
+	}
+	recent := []string{"3001", "2001", "9000"}
+
+	all := BuildSF6OpponentSuggestions("me", "", friends, accounts, recent, 0)
+	if got := suggestionIDs(all); len(got) != 4 || got[0] != "1001" || got[1] != "1002" || got[2] != "2001" || got[3] != "3001" {
+		t.Fatalf("all = %v", got)
+	}
+	if all[0].Name != "Sakura" || all[0].Source != domain.SF6OpponentSourceFriend {
+		t.Fatalf("friend = %+v", all[0])
+	}
+	if all[3].Name != "" || all[3].Source != domain.SF6OpponentSourceRecent {
+		t.Fatalf("recent = %+v", all[3])
+	}
+
+	// 別名・表示名は大文字小文字を区別しない
+	got := suggestionIDs(BuildSF6OpponentSuggestions("me", " KEN ", friends, accounts, recent, 0))
+	if len(got) != 2 || got[0] != "1002" || got[1] != "2001" {
+		t.Fatalf("ken = %v", got)
+	}
+
+	got = suggestionIDs(BuildSF6OpponentSuggestions("me", "main", friends, accounts, recent, 0))
+	if len(got) != 1 || got[0] != "1001" {
+		t.Fatalf("main = %v", got)
+	}
+	got = suggestionIDs(BuildSF6OpponentSuggestions("me", "00", friends, accounts, recent, 0))
+	if len(got) != 4 {
+		t.Fatalf("00 = %v", got)
+	}
+	// 前方一致（Sakura）を部分一致（Ken Master）より先に出す
+	got = suggestionIDs(BuildSF6OpponentSuggestions("me", "s", friends, accounts, recent, 0))
+	if len(got) != 2 || got[0] != "1001" || got[1] != "1002" {
+		t.Fatalf("s = %v", got)
+	}
+
+	// 自分の連携アカウントは候補にしない
+	if got := suggestionIDs(BuildSF6OpponentSuggestions("me", "9000", friends, accounts, recent, 0)); len(got) != 0 {
+		t.Fatalf("self = %v", got)
+	}
+	if got := BuildSF6OpponentSuggestions("me", "", friends, accounts, recent, 2); len(got) != 2 {
+		t.Fatalf("limit = %v", suggestionIDs(got))
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"backend/internal/domain"
+)
+
+// ランキングの並べ替え順。
+const (
+	SF6LeaderboardSortWinRate   = "winrate"
+	SF6LeaderboardSortGames     = "games"
+	SF6LeaderboardSortStreak    = "streak"
+	SF6LeaderboardSortOpponents = "opponents"
+)
+
+// SF6LeaderboardDefaultMinGames は勝率順で対象にする既定の最低試合数。
+const SF6LeaderboardDefaultMinGames = 10
+
+func (s *sf6Service) Leaderboard(ctx context.Context, guildID, battleType string, startAt, endAt time.Time, sortBy string, minGames int) (*domain.SF6Leaderboard, error) {
+	if s.battleRepo == nil {
+		return nil, errors.New("battle repo not configured")
+	}
+	games, err := s.battleRepo.LeaderboardGames(ctx, guildID, battleType, startAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+	return BuildSF6Leaderboard(games, sortBy, minGames), nil
+}
+
+// BuildSF6Leaderboard はメンバーごとに古い順に並んだ試合から成績を集計し、sortBy の順に並べる。
+// 勝率順のときは minGames 未満のメンバーを除く。
+func BuildSF6Leaderboard(games []domain.SF6LeaderboardGame, sortBy string, minGames int) *domain.SF6Leaderboard {
+	switch sortBy {
+	case SF6LeaderboardSortGames, SF6LeaderboardSortStreak, SF6LeaderboardSortOpponents:
+	default:
+		sortBy = SF6LeaderboardSortWinRate
+	}
+	if minGames <= 0 {
+		minGames = 1
+	}
+	board := &domain.SF6Leaderboard{SortBy: sortBy, MinGames: minGames}
+
+	var (
+		entries   []domain.SF6LeaderboardEntry
+		results   [][]domain.SF6BattleResult
+		current   *domain.SF6LeaderboardEntry
+		opponents map[string]struct{}
+	)
+	for _, game := range games {
+		if current == nil || current.FighterID != game.FighterID {
+			entries = append(entries, domain.SF6LeaderboardEntry{FighterID: game.FighterID})
+			results = append(results, nil)
+			current = &entries[len(entries)-1]
+			opponents = make(map[string]struct{})
+		}
+		current.Games++
+		switch game.Result {
+		case "win":
+			current.Wins++
+		case "loss":
+			current.Losses++
+		default:
+			current.Draws++
+		}
+		results[len(results)-1] = append(results[len(results)-1], domain.SF6BattleResult{BattleAt: game.BattleAt, Result: game.Result})
+		if _, ok := opponents[game.OpponentFighterID]; !ok {
+			opponents[game.OpponentFighterID] = struct{}{}
+			current.Opponents++
+		}
+	}
+
+	for idx, entry := range entries {
+		entry.LongestWinStreak = SummarizeSF6Momentum(results[idx], SF6MomentumWindow).LongestWinStreak
+		if decided := entry.Wins + entry.Losses; decided > 0 {
+			entry.WinRate = float64(entry.Wins) / float64(decided)
+		}
+		if sortBy == SF6LeaderboardSortWinRate && entry.Games < minGames {
+			board.Hidden++
+			continue
+		}
+		board.Entries = append(board.Entries, entry)
+	}
+
+	sort.SliceStable(board.Entries, func(a, b int) bool {
+		ea, eb := board.Entries[a], board.Entries[b]
+		var ka, kb float64
+		switch sortBy {
+		case SF6LeaderboardSortWinRate:
+			ka, kb = ea.WinRate, eb.WinRate
+		case SF6LeaderboardSortGames:
+			ka, kb = float64(ea.Games), float64(eb.Games)
+		case SF6LeaderboardSortStreak:
+			ka, kb = float64(ea.LongestWinStreak), float64(eb.LongestWinStreak)
+		case SF6LeaderboardSortOpponents:
+			ka, kb = float64(ea.Opponents), float64(eb.Opponents)
+		}
+		if ka != kb {
+			return ka > kb
+		}
+		if ea.Games != eb.Games {
+			return ea.Games > eb.Games
+		}
+		return ea.FighterID < eb.FighterID
+	})
+	return board
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/domain"
+)
+
+func testLeaderboardGames(fighterID string, results ...string) []domain.SF6LeaderboardGame {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	games := make([]domain.SF6LeaderboardGame, 0, len(results))
+	for idx, result := range results {
+		// 結果は "win:b" のように相手を付けられる
+		result, opponent, ok := strings.Cut(result, ":")
+		if !ok {
+			opponent = "opp"
+		}
+		games = append(games, domain.SF6LeaderboardGame{
+			FighterID:         fighterID,
+			OpponentFighterID: opponent,
+			BattleAt:          base.Add(time.Duration(idx) * time.Minute),
+			Result:            result,
+		})
+	}
+	return games
+}
+
+func TestBuildSF6LeaderboardWinRate(t *testing.T) {
+	var games []domain.SF6LeaderboardGame
+	games = append(games, testLeaderboardGames("a", "win", "win", "loss", "draw")...)
+	games = append(games, testLeaderboardGames("b", "win", "win", "win", "loss")...)
+	games = append(games, testLeaderboardGames("c", "win")...)
+
+	board := BuildSF6Leaderboard(games, SF6LeaderboardSortWinRate, 3)
+	if board.Hidden != 1 {
+		t.Fatalf("hidden = %d, want 1", board.Hidden)
+	}
+	if len(board.Entries) != 2 || board.Entries[0].FighterID != "b" || board.Entries[1].FighterID != "a" {
+		t.Fatalf("entries = %+v", board.Entries)
+	}
+	if a := board.Entries[1]; a.Wins != 2 || a.Losses != 1 || a.Draws != 1 || a.WinRate < 0.66 || a.WinRate > 0.67 {
+		t.Fatalf("a = %+v", a)
+	}
+}
+
+func TestBuildSF6LeaderboardStreakAndOpponents(t *testing.T) {
+	var games []domain.SF6LeaderboardGame
+	games = append(games, testLeaderboardGames("a", "win:b", "win:c", "draw:b", "win:b", "win:d", "win:b")...)
+	games = append(games, testLeaderboardGames("b", "loss:a", "win:c", "win:c", "loss:a")...)
+
+	streak := BuildSF6Leaderboard(games, SF6LeaderboardSortStreak, 0)
+	if streak.Entries[0].FighterID != "a" || streak.Entries[0].LongestWinStreak != 3 || streak.Entries[1].LongestWinStreak != 2 {
+		t.Fatalf("streak = %+v", streak.Entries)
+	}
+	if streak.Hidden != 0 {
+		t.Fatalf("hidden = %d, want 0 outside win-rate sort", streak.Hidden)
+	}
+
+	opponents := BuildSF6Leaderboard(games, SF6LeaderboardSortOpponents, 0)
+	if opponents.Entries[0].Opponents != 3 || opponents.Entries[1].Opponents != 2 {
+		t.Fatalf("opponents = %+v", opponents.Entries)
+	}
+}
+
+func TestBuildSF6LeaderboardGamesTieBreak(t *testing.T) {
+	var games []domain.SF6LeaderboardGame
+	games = append(games, testLeaderboardGames("b", "win", "loss")...)
+	games = append(games, testLeaderboardGames("a", "loss", "loss")...)
+
+	board := BuildSF6Leaderboard(games, "unknown", 1)
+	if board.SortBy != SF6LeaderboardSortWinRate {
+		t.Fatalf("sortBy = %q, want fallback to winrate", board.SortBy)
+	}
+	board = BuildSF6Leaderboard(games, SF6LeaderboardSortGames, 1)
+	if board.Entries[0].FighterID != "a" || board.Entries[1].FighterID != "b" {
+		t.Fatalf("entries = %+v", board.Entries)
+	}
+}
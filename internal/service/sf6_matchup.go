@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"backend/internal/domain"
+)
+
+// SF6MatchupDefaultMinGames は試合数が少なく勝率があてにならない組み合わせを隠す既定の閾値。
+const SF6MatchupDefaultMinGames = 3
+
+// 苦手な組み合わせとして挙げる数。
+const sf6MatchupWorstLimit = 3
+
+func (s *sf6Service) MatchupMatrix(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, minGames int) (*domain.SF6MatchupMatrix, error) {
+	if s.battleRepo == nil {
+		return nil, errors.New("battle repo not configured")
+	}
+	rows, err := s.battleRepo.MatchupsByOpponent(ctx, guildID, subjectFighterID, opponentFighterID, battleType)
+	if err != nil {
+		return nil, err
+	}
+	return BuildSF6MatchupMatrix(rows, minGames), nil
+}
+
+// BuildSF6MatchupMatrix は組み合わせごとの成績から、minGames 未満を除いた表と苦手な組み合わせを作る。
+func BuildSF6MatchupMatrix(rows []domain.SF6MatchupRow, minGames int) *domain.SF6MatchupMatrix {
+	if minGames <= 0 {
+		minGames = 1
+	}
+	matrix := &domain.SF6MatchupMatrix{MinGames: minGames}
+	gamesBySelf := make(map[string]int)
+	for _, row := range rows {
+		if row.Games < minGames {
+			matrix.HiddenCells++
+			matrix.HiddenGames += row.Games
+			continue
+		}
+		matrix.Cells = append(matrix.Cells, row)
+		gamesBySelf[row.SelfCharacter] += row.Games
+	}
+	sort.Slice(matrix.Cells, func(a, b int) bool {
+		ca, cb := matrix.Cells[a], matrix.Cells[b]
+		if ca.SelfCharacter != cb.SelfCharacter {
+			if gamesBySelf[ca.SelfCharacter] != gamesBySelf[cb.SelfCharacter] {
+				return gamesBySelf[ca.SelfCharacter] > gamesBySelf[cb.SelfCharacter]
+			}
+			return ca.SelfCharacter < cb.SelfCharacter
+		}
+		if ca.Games != cb.Games {
+			return ca.Games > cb.Games
+		}
+		return ca.OpponentCharacter < cb.OpponentCharacter
+	})
+
+	for _, cell := range matrix.Cells {
+		if decided := cell.Wins + cell.Losses; decided > 0 && cell.Wins*2 < decided {
+			matrix.Worst = append(matrix.Worst, cell)
+		}
+	}
+	// 勝率の低い順、同率なら試合数の多い（確からしい）順
+	sort.SliceStable(matrix.Worst, func(a, b int) bool {
+		wa, wb := matrix.Worst[a], matrix.Worst[b]
+		ra := float64(wa.Wins) / float64(wa.Wins+wa.Losses)
+		rb := float64(wb.Wins) / float64(wb.Wins+wb.Losses)
+		if ra != rb {
+			return ra < rb
+		}
+		return wa.Games > wb.Games
+	})
+	if len(matrix.Worst) > sf6MatchupWorstLimit {
+		matrix.Worst = matrix.Worst[:sf6MatchupWorstLimit]
+	}
+	return matrix
+}
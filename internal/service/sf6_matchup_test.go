@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"backend/internal/domain"
+)
+
+func TestBuildSF6MatchupMatrix(t *testing.T) {
+	rows := []domain.SF6MatchupRow{
+		{SelfCharacter: "Ryu", OpponentCharacter: "Ken", Games: 10, Wins: 7, Losses: 3},
+		{SelfCharacter: "Ryu", OpponentCharacter: "Guile", Games: 6, Wins: 1, Losses: 5},
+		{SelfCharacter: "Ryu", OpponentCharacter: "Zangief", Games: 2, Wins: 0, Losses: 2},
+		{SelfCharacter: "Cammy", OpponentCharacter: "Ken", Games: 4, Wins: 1, Losses: 2, Draws: 1},
+		{SelfCharacter: "Cammy", OpponentCharacter: "JP", Games: 3, Wins: 1, Losses: 1, Draws: 1},
+	}
+
+	m := BuildSF6MatchupMatrix(rows, 3)
+	if m.HiddenCells != 1 || m.HiddenGames != 2 {
+		t.Fatalf("hidden = %d cells / %d games, want 1 / 2", m.HiddenCells, m.HiddenGames)
+	}
+	var order []string
+	for _, cell := range m.Cells {
+		order = append(order, cell.SelfCharacter+"-"+cell.OpponentCharacter)
+	}
+	wantOrder := []string{"Ryu-Ken", "Ryu-Guile", "Cammy-Ken", "Cammy-JP"}
+	if len(order) != len(wantOrder) {
+		t.Fatalf("cells = %v, want %v", order, wantOrder)
+	}
+	for idx := range wantOrder {
+		if order[idx] != wantOrder[idx] {
+			t.Fatalf("cells = %v, want %v", order, wantOrder)
+		}
+	}
+	// 50% ちょうどの Cammy-JP は苦手に含めない
+	if len(m.Worst) != 2 || m.Worst[0].OpponentCharacter != "Guile" || m.Worst[1].SelfCharacter != "Cammy" {
+		t.Fatalf("worst = %+v", m.Worst)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/domain"
+)
+
+// SF6MomentumWindow は勝率推移の各点で見る試合数。
+const SF6MomentumWindow = 10
+
+func (s *sf6Service) MomentumBySession(ctx context.Context, sessionID string) (domain.SF6Momentum, error) {
+	if s.battleRepo == nil {
+		return domain.SF6Momentum{}, errors.New("battle repo not configured")
+	}
+	results, err := s.battleRepo.ResultsBySession(ctx, sessionID)
+	if err != nil {
+		return domain.SF6Momentum{}, err
+	}
+	return SummarizeSF6Momentum(results, SF6MomentumWindow), nil
+}
+
+func (s *sf6Service) MomentumByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) (domain.SF6Momentum, error) {
+	if s.battleRepo == nil {
+		return domain.SF6Momentum{}, errors.New("battle repo not configured")
+	}
+	results, err := s.battleRepo.ResultsByOpponentRange(ctx, guildID, subjectFighterID, opponentFighterID, battleType, startAt, endAt)
+	if err != nil {
+		return domain.SF6Momentum{}, err
+	}
+	return SummarizeSF6Momentum(results, SF6MomentumWindow), nil
+}
+
+func (s *sf6Service) MomentumByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) (domain.SF6Momentum, error) {
+	if s.battleRepo == nil {
+		return domain.SF6Momentum{}, errors.New("battle repo not configured")
+	}
+	results, err := s.battleRepo.ResultsByOpponentCount(ctx, guildID, subjectFighterID, opponentFighterID, battleType, limit)
+	if err != nil {
+		return domain.SF6Momentum{}, err
+	}
+	return SummarizeSF6Momentum(results, SF6MomentumWindow), nil
+}
+
+// SummarizeSF6Momentum は古い順の勝敗から連勝・連敗と、直近 window 戦ごとの勝率の推移を求める。
+// 引き分けは連勝・連敗を途切れさせ、勝率の計算からは除く。
+func SummarizeSF6Momentum(results []domain.SF6BattleResult, window int) domain.SF6Momentum {
+	if window <= 0 {
+		window = SF6MomentumWindow
+	}
+	m := domain.SF6Momentum{Window: window}
+	streak := 0
+	for idx, result := range results {
+		switch result.Result {
+		case "win":
+			if streak < 0 {
+				streak = 0
+			}
+			streak++
+		case "loss":
+			if streak > 0 {
+				streak = 0
+			}
+			streak--
+		default:
+			streak = 0
+		}
+		if streak > m.LongestWinStreak {
+			m.LongestWinStreak = streak
+		}
+		if -streak > m.LongestLossStreak {
+			m.LongestLossStreak = -streak
+		}
+
+		// 試合数が window に満たない序盤は、そこまでの全試合で計算する
+		start := idx - window + 1
+		if start < 0 {
+			start = 0
+		}
+		wins, decided := 0, 0
+		for _, r := range results[start : idx+1] {
+			switch r.Result {
+			case "win":
+				wins++
+				decided++
+			case "loss":
+				decided++
+			}
+		}
+		if decided > 0 {
+			m.RollingWinRates = append(m.RollingWinRates, float64(wins)/float64(decided))
+		}
+	}
+	m.CurrentStreak = streak
+	return m
+}
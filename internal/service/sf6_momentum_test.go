@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"backend/internal/domain"
+)
+
+func TestSummarizeSF6Momentum(t *testing.T) {
+	var results []domain.SF6BattleResult
+	for _, r := range []string{"win", "win", "win", "loss", "draw", "loss", "loss", "win", "win"} {
+		results = append(results, domain.SF6BattleResult{Result: r})
+	}
+
+	m := SummarizeSF6Momentum(results, 4)
+	if m.CurrentStreak != 2 || m.LongestWinStreak != 3 || m.LongestLossStreak != 2 {
+		t.Fatalf("streaks = current %d / win %d / loss %d, want 2 / 3 / 2", m.CurrentStreak, m.LongestWinStreak, m.LongestLossStreak)
+	}
+	want := []float64{1, 1, 1, 0.75, 2.0 / 3, 1.0 / 3, 0, 1.0 / 3, 0.5}
+	if len(m.RollingWinRates) != len(want) {
+		t.Fatalf("rolling = %v, want %v", m.RollingWinRates, want)
+	}
+	for idx := range want {
+		if diff := m.RollingWinRates[idx] - want[idx]; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("rolling[%d] = %v, want %v", idx, m.RollingWinRates[idx], want[idx])
+		}
+	}
+}
+
+func TestSummarizeSF6MomentumEndsOnLossOrDraw(t *testing.T) {
+	m := SummarizeSF6Momentum([]domain.SF6BattleResult{{Result: "win"}, {Result: "loss"}, {Result: "loss"}}, 10)
+	if m.CurrentStreak != -2 {
+		t.Fatalf("current = %d, want -2", m.CurrentStreak)
+	}
+	m = SummarizeSF6Momentum([]domain.SF6BattleResult{{Result: "draw"}}, 10)
+	if m.CurrentStreak != 0 || len(m.RollingWinRates) != 0 {
+		t.Fatalf("draw only: %+v", m)
+	}
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math/rand"
+	"sync"
 	"time"
 
+	"backend/internal/buckler"
+	"backend/internal/domain"
 	"backend/internal/repository"
 )
 
@@ -14,116 +18,522 @@ type PollLogger interface {
 	Error(args ...interface{})
 }
 
+// SF6PollIntervals は fighter ごとの取得間隔の決め方。
+//   - Base: 初回の取得で新しい試合が無かった場合の間隔
+//   - Min: 新しい試合があった直後の間隔
+//   - Max: 試合が無い状態が続いた場合に伸ばす上限
+//   - Active: セッション中の fighter の間隔
+type SF6PollIntervals struct {
+	Base   time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	Active time.Duration
+}
+
+// next は前回の間隔と今回の取得結果から次の間隔を決める。
+// 新しい試合があれば Min に縮め、無ければ倍々に伸ばす（Max まで）。セッション中は Active 以下にする。
+func (p SF6PollIntervals) next(prev time.Duration, hasNew, active bool) time.Duration {
+	var interval time.Duration
+	switch {
+	case hasNew:
+		interval = p.Min
+	case prev <= 0:
+		interval = p.Base
+	default:
+		interval = prev * 2
+	}
+	if p.Max > 0 && interval > p.Max {
+		interval = p.Max
+	}
+	if p.Min > 0 && interval < p.Min {
+		interval = p.Min
+	}
+	if active && p.Active > 0 && interval > p.Active {
+		interval = p.Active
+	}
+	return interval
+}
+
+// SF6PollOptions は定期取得の設定。
+type SF6PollOptions struct {
+	// Tick は予定を確認する間隔。
+	Tick      time.Duration
+	Intervals SF6PollIntervals
+	MaxPages  int
+	// AccountDelayMax は 1 件取得するごとに各ワーカーが入れるランダムな待ち時間の上限。
+	AccountDelayMax time.Duration
+	Kinds           []buckler.BattlelogKind
+	// Workers は同時に取得する fighter の数。
+	Workers int
+	// JobTimeout は 1 fighter の取得（1 回の試行）にかけられる時間。
+	JobTimeout time.Duration
+	// JobRetries は一時的なエラーで失敗した fighter を再試行する回数。
+	JobRetries int
+	// AfterRound は 1 周ごと（打ち切られた場合も含む）に呼ばれる。新着通知などに使う。
+	AfterRound func(ctx context.Context, summary SF6PollSummary)
+}
+
+// SF6PollSummary は 1 周分の取得結果。
+type SF6PollSummary struct {
+	Jobs    int
+	Fetched int
+	Skipped int
+	Failed  int
+	Retried int
+	Saved   int
+	Elapsed time.Duration
+}
+
+// sf6PollTarget は保存先（ギルド内の fighter）。
+type sf6PollTarget struct {
+	guildID   string
+	userID    string
+	fighterID string
+	friend    bool
+}
+
+// sf6PollJob は 1 つの fighter の取得。複数のアカウント・ギルドで同じ fighter を追っていても 1 回だけ取得し、
+// 保存先ごとに保存する。
+type sf6PollJob struct {
+	fighterID string
+	targets   []sf6PollTarget
+	schedules []domain.SF6PollSchedule
+	active    bool
+}
+
+// 再試行の待ち時間の基準。試行ごとに倍にする。
+const sf6PollRetryBackoff = 2 * time.Second
+
+// RunSF6Poller は tick ごとに予定時刻を過ぎた fighter をワーカーで並行して取得する。
+// 予定（sf6_poll_schedules）は DB に保存するので、再起動しても間隔は引き継がれる。
 func RunSF6Poller(
 	ctx context.Context,
-	interval time.Duration,
-	maxPages int,
-	accountDelayMax time.Duration,
+	opts SF6PollOptions,
 	accountRepo repository.SF6AccountRepository,
 	friendRepo repository.SF6FriendRepository,
+	sessionRepo repository.SF6SessionRepository,
+	scheduleRepo repository.SF6PollScheduleRepository,
+	runRepo repository.SF6FetchRunRepository,
 	sf6Service SF6Service,
 	logger PollLogger,
 ) {
-	if interval <= 0 || maxPages <= 0 {
+	if opts.Tick <= 0 || opts.MaxPages <= 0 {
 		return
 	}
-	logger.Infof("sf6 poller start: interval=%s max_pages=%d account_delay_max=%s", interval, maxPages, accountDelayMax)
-	runSF6PollOnce(ctx, maxPages, accountDelayMax, accountRepo, friendRepo, sf6Service, logger)
-	ticker := time.NewTicker(interval)
+	opts = opts.withDefaults()
+	logger.Infof("sf6 poller start: tick=%s base=%s min=%s max=%s active=%s workers=%d job_timeout=%s job_retries=%d max_pages=%d account_delay_max=%s kinds=%v",
+		opts.Tick, opts.Intervals.Base, opts.Intervals.Min, opts.Intervals.Max, opts.Intervals.Active,
+		opts.Workers, opts.JobTimeout, opts.JobRetries, opts.MaxPages, opts.AccountDelayMax, opts.Kinds)
+	poll := func() error {
+		summary, err := runSF6PollOnce(ctx, time.Now(), opts, accountRepo, friendRepo, sessionRepo, scheduleRepo, runRepo, sf6Service, logger)
+		if opts.AfterRound != nil && ctx.Err() == nil {
+			opts.AfterRound(ctx, summary)
+		}
+		return err
+	}
+	err := poll()
+	ticker := time.NewTicker(opts.Tick)
 	defer ticker.Stop()
 	for {
+		// 429 で Retry-After が返っていれば、次の周回までその分だけ待つ
+		if wait, ok := buckler.RetryAfter(err); ok {
+			logger.Infof("sf6 poll backoff: %s", wait)
+			sleepContext(ctx, wait)
+		}
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			runSF6PollOnce(ctx, maxPages, accountDelayMax, accountRepo, friendRepo, sf6Service, logger)
+			err = poll()
 		}
 	}
 }
 
+func (o SF6PollOptions) withDefaults() SF6PollOptions {
+	if len(o.Kinds) == 0 {
+		o.Kinds = []buckler.BattlelogKind{buckler.BattlelogCustom}
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.JobRetries < 0 {
+		o.JobRetries = 0
+	}
+	return o
+}
+
+// runSF6PollOnce は予定時刻を過ぎた fighter をジョブにしてワーカーに配る。
+// 他の fighter でも同じく失敗するエラー（認証・レート制限など）が出たら残りのジョブを打ち切ってそのエラーを返す。
 func runSF6PollOnce(
 	ctx context.Context,
-	maxPages int,
-	accountDelayMax time.Duration,
+	now time.Time,
+	opts SF6PollOptions,
 	accountRepo repository.SF6AccountRepository,
 	friendRepo repository.SF6FriendRepository,
+	sessionRepo repository.SF6SessionRepository,
+	scheduleRepo repository.SF6PollScheduleRepository,
+	runRepo repository.SF6FetchRunRepository,
 	sf6Service SF6Service,
 	logger PollLogger,
-) {
-	accounts, err := accountRepo.ListActive(ctx)
+) (SF6PollSummary, error) {
+	opts = opts.withDefaults()
+	var summary SF6PollSummary
+	targets, err := listSF6PollTargets(ctx, accountRepo, friendRepo, logger)
 	if err != nil {
 		logger.Error("sf6 poll list accounts: ", err)
-		return
+		return summary, err
 	}
-	if len(accounts) == 0 {
-		logger.Infof("sf6 poll: no active accounts")
+	if len(targets) == 0 {
+		return summary, nil
+	}
+	schedules := make(map[string]domain.SF6PollSchedule)
+	if scheduleRepo != nil {
+		list, err := scheduleRepo.List(ctx)
+		if err != nil {
+			logger.Error("sf6 poll list schedules: ", err)
+			return summary, err
+		}
+		for _, schedule := range list {
+			schedules[schedule.GuildID+":"+schedule.FighterID] = schedule
+		}
+	}
+	active := make(map[string]struct{})
+	if sessionRepo != nil {
+		sessions, err := sessionRepo.ListActive(ctx)
+		if err != nil {
+			logger.Error("sf6 poll list sessions: ", err)
+		}
+		for _, session := range sessions {
+			if session.SubjectFighterID != "" {
+				active[session.GuildID+":"+session.SubjectFighterID] = struct{}{}
+			}
+			active[session.GuildID+":"+session.OpponentFighterID] = struct{}{}
+		}
+	}
+
+	jobs := buildSF6PollJobs(targets, schedules, active, now, opts.Intervals)
+	summary.Jobs = len(jobs)
+	summary.Skipped = countSF6PollSkipped(targets, jobs)
+	if len(jobs) == 0 {
+		return summary, nil
+	}
+
+	started := time.Now()
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	var (
+		mu       sync.Mutex
+		fatalErr error
+	)
+	queue := make(chan *sf6PollJob)
+	go func() {
+		defer close(queue)
+		for _, job := range jobs {
+			select {
+			case <-runCtx.Done():
+				return
+			case queue <- job:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for job := range queue {
+				saved, retries, err := runSF6PollJob(runCtx, opts, sf6Service, runRepo, job, logger)
+				mu.Lock()
+				summary.Retried += retries
+				summary.Saved += saved
+				if err == nil {
+					summary.Fetched++
+				}
+				if err != nil && (IsSF6FetchFatal(err) || runCtx.Err() != nil) {
+					// 全体を打ち切るエラーは予定を進めず、次の tick でやり直す
+					if fatalErr == nil && IsSF6FetchFatal(err) {
+						fatalErr = err
+						cancelRun()
+					}
+					mu.Unlock()
+					continue
+				}
+				mu.Unlock()
+				saveSF6PollSchedules(ctx, scheduleRepo, job, saved, opts.Intervals, logger)
+				jitterSleep(runCtx, rng, opts.AccountDelayMax)
+			}
+		}(now.UnixNano() + int64(w))
+	}
+	wg.Wait()
+
+	summary.Elapsed = time.Since(started)
+	// 打ち切りで取得しなかったジョブも失敗として数える
+	summary.Failed = summary.Jobs - summary.Fetched
+	logger.Infof("sf6 poll summary: jobs=%d fetched=%d skipped=%d failed=%d retried=%d saved=%d elapsed=%s",
+		summary.Jobs, summary.Fetched, summary.Skipped, summary.Failed, summary.Retried, summary.Saved, summary.Elapsed.Round(time.Millisecond))
+	if fatalErr != nil {
+		logger.Infof("sf6 poll aborted: %v", fatalErr)
+		return summary, fatalErr
+	}
+	if err := ctx.Err(); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// runSF6PollJob は 1 fighter を取得する。一時的なエラーなら JobRetries 回まで間隔を空けて再試行する。
+// 試行ごとに保存先のギルド単位で取得履歴（sf6_fetch_runs）を残す。
+func runSF6PollJob(ctx context.Context, opts SF6PollOptions, sf6Service SF6Service, runRepo repository.SF6FetchRunRepository, job *sf6PollJob, logger PollLogger) (int, int, error) {
+	owners := make([]SF6FetchOwner, 0, len(job.targets))
+	for _, target := range job.targets {
+		owners = append(owners, SF6FetchOwner{GuildID: target.guildID, UserID: target.userID})
+	}
+	retries := 0
+	for attempt := 0; ; attempt++ {
+		jobCtx, cancel := ctx, context.CancelFunc(func() {})
+		if opts.JobTimeout > 0 {
+			jobCtx, cancel = context.WithTimeout(ctx, opts.JobTimeout)
+		}
+		started := time.Now()
+		saved, pages, err := fetchSF6PagesForOwners(jobCtx, sf6Service, job.fighterID, owners, opts.Kinds, opts.MaxPages)
+		cancel()
+		recordSF6PollJobRun(ctx, runRepo, job, attempt+1, pages, saved, started, err, logger)
+		if err == nil {
+			logger.Infof("sf6 poll done: fighter=%s owners=%d saved=%d", job.fighterID, len(owners), saved)
+			return saved, retries, nil
+		}
+		logger.Error("sf6 poll fetch: fighter="+job.fighterID+": ", err)
+		if IsSF6FetchFatal(err) || ctx.Err() != nil || errors.Is(err, buckler.ErrNotFound) || attempt >= opts.JobRetries {
+			return saved, retries, err
+		}
+		retries++
+		sleepContext(ctx, sf6PollRetryBackoff<<attempt)
+	}
+}
+
+// recordSF6PollJobRun は 1 回の試行をジョブの保存先ギルドごとに記録する。
+func recordSF6PollJobRun(ctx context.Context, runRepo repository.SF6FetchRunRepository, job *sf6PollJob, attempt, pages, saved int, started time.Time, fetchErr error, logger PollLogger) {
+	if runRepo == nil {
 		return
 	}
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for _, account := range accounts {
-		totalSaved := 0
+	seen := make(map[string]struct{}, len(job.targets))
+	for _, target := range job.targets {
+		if _, ok := seen[target.guildID]; ok {
+			continue
+		}
+		seen[target.guildID] = struct{}{}
+		run := NewSF6FetchRun(domain.SF6FetchTriggerPoller, target.guildID, job.fighterID, attempt, pages, saved, started, fetchErr)
+		if err := recordSF6FetchRun(ctx, runRepo, run); err != nil {
+			logger.Error("sf6 poll record run: ", err)
+		}
+	}
+}
+
+// fetchSF6PagesForOwners は fetchSF6Pages と同じ打ち切り方で取得するが、失敗はすべて返す（再試行の判断に使う）。
+// 保存件数と取得したページ数を返す。
+func fetchSF6PagesForOwners(ctx context.Context, sf6Service SF6Service, sid string, owners []SF6FetchOwner, kinds []buckler.BattlelogKind, maxPages int) (int, int, error) {
+	saved, pages := 0, 0
+	for _, kind := range kinds {
 		for page := 1; page <= maxPages; page++ {
-			count, allExisting, err := sf6Service.FetchAndStoreCustomBattles(
-				ctx,
-				account.GuildID,
-				account.UserID,
-				account.FighterID,
-				page,
-			)
+			count, allExisting, err := sf6Service.FetchAndStoreBattlesForOwners(ctx, sid, kind, page, owners)
+			saved += count
 			if err != nil {
-				logger.Error("sf6 poll fetch: ", err)
-				break
+				return saved, pages, err
 			}
-			totalSaved += count
+			pages++
 			if allExisting {
 				break
 			}
 		}
-		logger.Infof("sf6 poll done: guild=%s user=%s saved=%d", account.GuildID, account.UserID, totalSaved)
-		if friendRepo != nil {
-			friends, err := friendRepo.List(ctx, account.GuildID, account.UserID)
+	}
+	return saved, pages, nil
+}
+
+// buildSF6PollJobs は保存先を fighter ごとにまとめ、どれか 1 つでも予定時刻を過ぎていればジョブにする。
+func buildSF6PollJobs(targets []sf6PollTarget, schedules map[string]domain.SF6PollSchedule, active map[string]struct{}, now time.Time, intervals SF6PollIntervals) []*sf6PollJob {
+	byFighter := make(map[string]*sf6PollJob)
+	due := make(map[string]bool)
+	order := make([]string, 0)
+	for _, target := range targets {
+		key := target.guildID + ":" + target.fighterID
+		schedule, scheduled := schedules[key]
+		if !scheduled {
+			schedule = domain.SF6PollSchedule{GuildID: target.guildID, FighterID: target.fighterID, UserID: target.userID}
+		}
+		_, isActive := active[key]
+		job, ok := byFighter[target.fighterID]
+		if !ok {
+			job = &sf6PollJob{fighterID: target.fighterID}
+			byFighter[target.fighterID] = job
+			order = append(order, target.fighterID)
+		}
+		job.targets = append(job.targets, target)
+		job.schedules = append(job.schedules, schedule)
+		job.active = job.active || isActive
+		if !scheduled || sf6PollDue(schedule, now, isActive, intervals) {
+			due[target.fighterID] = true
+		}
+	}
+	jobs := make([]*sf6PollJob, 0, len(due))
+	for _, fighterID := range order {
+		if due[fighterID] {
+			jobs = append(jobs, byFighter[fighterID])
+		}
+	}
+	return jobs
+}
+
+func countSF6PollSkipped(targets []sf6PollTarget, jobs []*sf6PollJob) int {
+	fighters := make(map[string]struct{})
+	for _, target := range targets {
+		fighters[target.fighterID] = struct{}{}
+	}
+	return len(fighters) - len(jobs)
+}
+
+// saveSF6PollSchedules は取得結果から保存先ごとの次回予定を保存する。
+// 失敗した場合も新しい試合が無かったものとして間隔を伸ばし、同じ fighter に短い間隔で当たり続けないようにする。
+func saveSF6PollSchedules(ctx context.Context, scheduleRepo repository.SF6PollScheduleRepository, job *sf6PollJob, saved int, intervals SF6PollIntervals, logger PollLogger) {
+	if scheduleRepo == nil {
+		return
+	}
+	polledAt := time.Now()
+	for idx, target := range job.targets {
+		prev := job.schedules[idx]
+		next := domain.SF6PollSchedule{
+			GuildID:         target.guildID,
+			FighterID:       target.fighterID,
+			UserID:          target.userID,
+			Interval:        intervals.next(prev.Interval, saved > 0, job.active),
+			LastPolledAt:    &polledAt,
+			LastNewBattleAt: prev.LastNewBattleAt,
+		}
+		next.NextPollAt = polledAt.Add(next.Interval)
+		if saved > 0 {
+			next.LastNewBattleAt = &polledAt
+		}
+		if err := scheduleRepo.Save(ctx, next); err != nil {
+			logger.Error("sf6 poll save schedule: ", err)
+		}
+	}
+}
+
+// sf6PollDue は予定時刻を過ぎたかを返す。セッション中なら Active の間隔で前倒しする。
+func sf6PollDue(schedule domain.SF6PollSchedule, now time.Time, active bool, intervals SF6PollIntervals) bool {
+	if !now.Before(schedule.NextPollAt) {
+		return true
+	}
+	if active && intervals.Active > 0 && schedule.LastPolledAt != nil {
+		return !now.Before(schedule.LastPolledAt.Add(intervals.Active))
+	}
+	return false
+}
+
+// listSF6PollTargets は連携アカウントと、その友達のうち連携されていない fighter を返す。
+// 同じギルドの同じ fighter は 1 回だけ取得する（友達は最初に見つかったユーザーの戦績として保存する）。
+func listSF6PollTargets(ctx context.Context, accountRepo repository.SF6AccountRepository, friendRepo repository.SF6FriendRepository, logger PollLogger) ([]sf6PollTarget, error) {
+	accounts, err := accountRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		logger.Infof("sf6 poll: no active accounts")
+		return nil, nil
+	}
+	seen := make(map[string]struct{})
+	targets := make([]sf6PollTarget, 0, len(accounts))
+	for _, account := range accounts {
+		key := account.GuildID + ":" + account.FighterID
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		targets = append(targets, sf6PollTarget{guildID: account.GuildID, userID: account.UserID, fighterID: account.FighterID})
+	}
+	if friendRepo == nil {
+		return targets, nil
+	}
+	for _, account := range accounts {
+		friends, err := friendRepo.List(ctx, account.GuildID, account.UserID)
+		if err != nil {
+			logger.Error("sf6 poll friend list: ", err)
+			continue
+		}
+		for _, friend := range friends {
+			key := account.GuildID + ":" + friend.FighterID
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			if owner, err := accountRepo.GetByFighter(ctx, account.GuildID, friend.FighterID); err != nil {
+				logger.Error("sf6 poll friend owner lookup: ", err)
+				continue
+			} else if owner != nil {
+				continue
+			}
+			seen[key] = struct{}{}
+			targets = append(targets, sf6PollTarget{guildID: account.GuildID, userID: account.UserID, fighterID: friend.FighterID, friend: true})
+		}
+	}
+	return targets, nil
+}
+
+// fetchSF6Pages は種別ごとに最大 maxPages まで取得し、既存だけのページに当たったらその種別を打ち切る。
+// 他の sid でも同じく失敗するエラー（認証・メンテナンス・レート制限など）の場合は即座に返す。
+// 保存件数と取得できたページ数を返す。
+func fetchSF6Pages(
+	ctx context.Context,
+	sf6Service SF6Service,
+	guildID, userID, sid string,
+	kinds []buckler.BattlelogKind,
+	maxPages int,
+	onError func(error),
+) (int, int, error) {
+	saved, pages := 0, 0
+	for _, kind := range kinds {
+		for page := 1; page <= maxPages; page++ {
+			count, allExisting, err := sf6Service.FetchAndStoreBattles(ctx, guildID, userID, sid, kind, page)
 			if err != nil {
-				logger.Error("sf6 poll friend list: ", err)
-			} else {
-				for _, friend := range friends {
-					if owner, err := accountRepo.GetByFighter(ctx, account.GuildID, friend.FighterID); err != nil {
-						logger.Error("sf6 poll friend owner lookup: ", err)
-						continue
-					} else if owner != nil {
-						continue
-					}
-					friendSaved := 0
-					for page := 1; page <= maxPages; page++ {
-						count, allExisting, err := sf6Service.FetchAndStoreCustomBattles(
-							ctx,
-							account.GuildID,
-							account.UserID,
-							friend.FighterID,
-							page,
-						)
-						if err != nil {
-							logger.Error("sf6 poll friend fetch: ", err)
-							break
-						}
-						friendSaved += count
-						if allExisting {
-							break
-						}
-					}
-					logger.Infof("sf6 poll friend done: guild=%s user=%s friend=%s saved=%d", account.GuildID, account.UserID, friend.FighterID, friendSaved)
-					jitterSleep(ctx, rng, accountDelayMax)
+				if onError != nil {
+					onError(err)
+				}
+				if IsSF6FetchFatal(err) || ctx.Err() != nil {
+					return saved, pages, err
 				}
+				break
+			}
+			saved += count
+			pages++
+			if allExisting {
+				break
 			}
 		}
-		jitterSleep(ctx, rng, accountDelayMax)
 	}
+	return saved, pages, nil
+}
+
+// IsSF6FetchFatal は今回の巡回（他の sid の取得も含む）を打ち切るべきエラーかを判定する。
+// ErrNotFound（sid の誤り・非公開）はその sid だけの問題なので続行する。
+func IsSF6FetchFatal(err error) bool {
+	return errors.Is(err, buckler.ErrNotAuthenticated) ||
+		errors.Is(err, buckler.ErrRateLimited) ||
+		errors.Is(err, buckler.ErrMaintenance) ||
+		errors.Is(err, buckler.ErrBuildIDDrift) ||
+		errors.Is(err, buckler.ErrSchemaChanged)
 }
 
 func jitterSleep(ctx context.Context, rng *rand.Rand, max time.Duration) {
 	if max <= 0 {
 		return
 	}
-	delay := time.Duration(rng.Int63n(int64(max)))
+	sleepContext(ctx, time.Duration(rng.Int63n(int64(max))))
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
 	timer := time.NewTimer(delay)
 	defer timer.Stop()
 	select {
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+	"backend/internal/repository/repositorytest"
+)
+
+func TestSF6PollIntervalsNext(t *testing.T) {
+	p := SF6PollIntervals{Base: 4 * time.Hour, Min: 30 * time.Minute, Max: 24 * time.Hour, Active: 10 * time.Minute}
+	tests := []struct {
+		name   string
+		prev   time.Duration
+		hasNew bool
+		active bool
+		want   time.Duration
+	}{
+		{"first poll idle", 0, false, false, 4 * time.Hour},
+		{"new battles shrink to min", 8 * time.Hour, true, false, 30 * time.Minute},
+		{"idle doubles", 30 * time.Minute, false, false, time.Hour},
+		{"idle capped at max", 16 * time.Hour, false, false, 24 * time.Hour},
+		{"active session", 8 * time.Hour, false, true, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.next(tt.prev, tt.hasNew, tt.active); got != tt.want {
+			t.Errorf("%s: next(%s, %v, %v) = %s, want %s", tt.name, tt.prev, tt.hasNew, tt.active, got, tt.want)
+		}
+	}
+}
+
+type stubScheduleRepo struct {
+	repository.SF6PollScheduleRepository
+	mu        sync.Mutex
+	schedules map[string]domain.SF6PollSchedule
+}
+
+func (r *stubScheduleRepo) List(ctx context.Context) ([]domain.SF6PollSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domain.SF6PollSchedule, 0, len(r.schedules))
+	for _, schedule := range r.schedules {
+		out = append(out, schedule)
+	}
+	return out, nil
+}
+
+func (r *stubScheduleRepo) Save(ctx context.Context, schedule domain.SF6PollSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules[schedule.GuildID+":"+schedule.FighterID] = schedule
+	return nil
+}
+
+type stubSessionRepo struct {
+	repository.SF6SessionRepository
+	sessions []domain.SF6Session
+}
+
+func (r *stubSessionRepo) ListActive(ctx context.Context) ([]domain.SF6Session, error) {
+	return r.sessions, nil
+}
+
+// countingSF6Service は fighter ごとの取得回数を数え、saved[fighter] 件の新規保存を返す。
+// failures[fighter] 回までは errFor の戻り値で失敗させる。
+type countingSF6Service struct {
+	SF6Service
+	mu       sync.Mutex
+	calls    map[string]int
+	owners   map[string]int
+	saved    map[string]int
+	failures map[string]int
+	failErr  error
+}
+
+func (s *countingSF6Service) FetchAndStoreBattlesForOwners(ctx context.Context, sid string, kind buckler.BattlelogKind, page int, owners []SF6FetchOwner) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[sid]++
+	s.owners[sid] = len(owners)
+	if s.failures[sid] > 0 {
+		s.failures[sid]--
+		return 0, false, s.failErr
+	}
+	return s.saved[sid], true, nil
+}
+
+func newCountingSF6Service() *countingSF6Service {
+	return &countingSF6Service{
+		calls:    make(map[string]int),
+		owners:   make(map[string]int),
+		saved:    make(map[string]int),
+		failures: make(map[string]int),
+	}
+}
+
+func TestRunSF6PollOnceFollowsSchedule(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	intervals := SF6PollIntervals{Base: 4 * time.Hour, Min: 30 * time.Minute, Max: 24 * time.Hour, Active: 10 * time.Minute}
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: "100"},
+		{GuildID: "g1", UserID: "u2", FighterID: "200"},
+		{GuildID: "g1", UserID: "u3", FighterID: "300"},
+	}}
+	lastPolled := now.Add(-20 * time.Minute)
+	scheduleRepo := &stubScheduleRepo{schedules: map[string]domain.SF6PollSchedule{
+		// 予定がまだ先: 取得しない
+		"g1:200": {GuildID: "g1", FighterID: "200", UserID: "u2", Interval: 8 * time.Hour, NextPollAt: now.Add(time.Hour), LastPolledAt: &lastPolled},
+		// 予定はまだ先だがセッション中: 前倒しして取得する
+		"g1:300": {GuildID: "g1", FighterID: "300", UserID: "u3", Interval: 8 * time.Hour, NextPollAt: now.Add(time.Hour), LastPolledAt: &lastPolled},
+	}}
+	sessionRepo := &stubSessionRepo{sessions: []domain.SF6Session{{GuildID: "g1", SubjectFighterID: "300", OpponentFighterID: "999"}}}
+	svc := newCountingSF6Service()
+	svc.saved["100"] = 2
+	opts := SF6PollOptions{Intervals: intervals, MaxPages: 1, Kinds: []buckler.BattlelogKind{buckler.BattlelogCustom}, Workers: 2}
+
+	summary, err := runSF6PollOnce(ctx, now, opts, accountRepo, nil, sessionRepo, scheduleRepo, nil, svc, testPollLogger{t})
+	if err != nil {
+		t.Fatalf("runSF6PollOnce() error = %v", err)
+	}
+	if svc.calls["100"] != 1 || svc.calls["200"] != 0 || svc.calls["300"] != 1 {
+		t.Fatalf("calls = %v, want 100:1 200:0 300:1", svc.calls)
+	}
+	if summary.Jobs != 2 || summary.Fetched != 2 || summary.Skipped != 1 || summary.Saved != 2 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if got := scheduleRepo.schedules["g1:100"]; got.Interval != 30*time.Minute || got.LastNewBattleAt == nil {
+		t.Fatalf("schedule 100 = %+v, want min interval with last_new_battle_at", got)
+	}
+	if got := scheduleRepo.schedules["g1:300"]; got.Interval != 10*time.Minute {
+		t.Fatalf("schedule 300 interval = %s, want active interval", got.Interval)
+	}
+	if got := scheduleRepo.schedules["g1:200"]; got.Interval != 8*time.Hour {
+		t.Fatalf("schedule 200 changed: %+v", got)
+	}
+}
+
+type stubFriendRepo struct {
+	repository.SF6FriendRepository
+	friends map[string][]domain.SF6Friend
+}
+
+func (r *stubFriendRepo) List(ctx context.Context, guildID, userID string) ([]domain.SF6Friend, error) {
+	return r.friends[guildID+":"+userID], nil
+}
+
+func TestRunSF6PollOnceDedupesFighterAcrossAccountsAndGuilds(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: "100"},
+		{GuildID: "g1", UserID: "u2", FighterID: "200"},
+		{GuildID: "g2", UserID: "u3", FighterID: "300"},
+	}}
+	// 友達 500 を 3 人が登録している（g1 に 2 人、g2 に 1 人）
+	friendRepo := &stubFriendRepo{friends: map[string][]domain.SF6Friend{
+		"g1:u1": {{GuildID: "g1", UserID: "u1", FighterID: "500"}},
+		"g1:u2": {{GuildID: "g1", UserID: "u2", FighterID: "500"}},
+		"g2:u3": {{GuildID: "g2", UserID: "u3", FighterID: "500"}},
+	}}
+	scheduleRepo := &stubScheduleRepo{schedules: make(map[string]domain.SF6PollSchedule)}
+	svc := newCountingSF6Service()
+	opts := SF6PollOptions{MaxPages: 1, Kinds: []buckler.BattlelogKind{buckler.BattlelogCustom}, Workers: 3}
+
+	summary, err := runSF6PollOnce(ctx, time.Now(), opts, accountRepo, friendRepo, nil, scheduleRepo, nil, svc, testPollLogger{t})
+	if err != nil {
+		t.Fatalf("runSF6PollOnce() error = %v", err)
+	}
+	if svc.calls["500"] != 1 {
+		t.Fatalf("fighter 500 fetched %d times, want 1", svc.calls["500"])
+	}
+	if svc.owners["500"] != 2 {
+		t.Fatalf("fighter 500 owners = %d, want 2 (one per guild)", svc.owners["500"])
+	}
+	if summary.Jobs != 4 || summary.Fetched != 4 {
+		t.Fatalf("summary = %+v, want 4 jobs fetched", summary)
+	}
+	if _, ok := scheduleRepo.schedules["g2:500"]; !ok {
+		t.Fatal("schedule for g2:500 not saved")
+	}
+}
+
+// expectErrorLogger はエラーログを想定内として記録だけする。
+type expectErrorLogger struct{ t *testing.T }
+
+func (l expectErrorLogger) Infof(format string, args ...interface{}) { l.t.Logf(format, args...) }
+func (l expectErrorLogger) Error(args ...interface{})                { l.t.Log(args...) }
+
+func TestRunSF6PollOnceRetriesAndAbortsOnFatal(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := &repositorytest.SF6AccountRepo{Accounts: []domain.SF6Account{
+		{GuildID: "g1", UserID: "u1", FighterID: "100"},
+	}}
+	opts := SF6PollOptions{MaxPages: 1, Kinds: []buckler.BattlelogKind{buckler.BattlelogCustom}, Workers: 1, JobRetries: 1}
+
+	// 一時的なエラーは再試行して成功する。試行ごとに取得履歴を残す
+	svc := newCountingSF6Service()
+	svc.failures["100"] = 1
+	svc.failErr = errors.New("connection reset")
+	runRepo := &stubFetchRunRepo{}
+	summary, err := runSF6PollOnce(ctx, time.Now(), opts, accountRepo, nil, nil, nil, runRepo, svc, expectErrorLogger{t})
+	if err != nil || summary.Fetched != 1 || summary.Retried != 1 {
+		t.Fatalf("transient: summary = %+v err = %v, want fetched after 1 retry", summary, err)
+	}
+	if len(runRepo.runs) != 2 ||
+		runRepo.runs[0].Attempt != 1 || runRepo.runs[0].ErrorClass != SF6FetchErrorOther ||
+		runRepo.runs[1].Attempt != 2 || runRepo.runs[1].ErrorClass != "" || runRepo.runs[1].Pages != 1 ||
+		runRepo.runs[1].Trigger != domain.SF6FetchTriggerPoller {
+		t.Fatalf("recorded runs = %+v", runRepo.runs)
+	}
+
+	// 認証エラーは再試行せずに周回ごと打ち切る
+	svc = newCountingSF6Service()
+	svc.failures["100"] = 5
+	svc.failErr = buckler.ErrNotAuthenticated
+	summary, err = runSF6PollOnce(ctx, time.Now(), opts, accountRepo, nil, nil, nil, nil, svc, expectErrorLogger{t})
+	if !errors.Is(err, buckler.ErrNotAuthenticated) || summary.Failed != 1 || svc.calls["100"] != 1 {
+		t.Fatalf("fatal: summary = %+v err = %v calls = %d", summary, err, svc.calls["100"])
+	}
+}
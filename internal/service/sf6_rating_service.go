@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// Elo の初期値と K 係数。
+const (
+	SF6RatingInitial = 1500.0
+	SF6RatingK       = 32.0
+)
+
+// 1 回の保存でまとめて反映する試合数。
+const sf6RatingBatchSize = 500
+
+// 保存中のトランザクションの行を取りこぼさないよう、確認時刻は少し前にずらす。
+const sf6RatingSettleDelay = 5 * time.Second
+
+// ErrSF6RatingConflict は別のインスタンスが同じギルドのレーティングを同時に更新したことを表す。
+var ErrSF6RatingConflict = errors.New("sf6 rating updated concurrently")
+
+type SF6RatingService interface {
+	// Refresh は前回以降に保存された試合をレーティングに反映し、反映した試合数を返す。
+	// 反映済みの試合より前の試合が後から保存されていれば、最初から計算し直す。
+	Refresh(ctx context.Context, guildID string) (int, error)
+	// RefreshAll は試合のある全ギルドを Refresh する。
+	RefreshAll(ctx context.Context, logger PollLogger)
+	GetRating(ctx context.Context, guildID, fighterID, character string) (*domain.SF6Rating, error)
+	Leaderboard(ctx context.Context, guildID, character string, limit, offset int) ([]domain.SF6Rating, error)
+	History(ctx context.Context, guildID, fighterID, character string, limit int) ([]domain.SF6RatingChange, error)
+}
+
+type sf6RatingService struct {
+	ratingRepo repository.SF6RatingRepository
+}
+
+func NewSF6RatingService(ratingRepo repository.SF6RatingRepository) SF6RatingService {
+	return &sf6RatingService{ratingRepo: ratingRepo}
+}
+
+func (s *sf6RatingService) Refresh(ctx context.Context, guildID string) (int, error) {
+	if guildID == "" {
+		return 0, errors.New("guildID is required")
+	}
+	cursor, err := s.ratingRepo.GetCursor(ctx, guildID)
+	if err != nil {
+		return 0, err
+	}
+	// checkedAt までに保存された試合だけを反映し、それより後の行は次回に回す。created_at と同じ DB の時計で決める
+	checkedAt, err := s.ratingRepo.SettledCutoff(ctx, sf6RatingSettleDelay)
+	if err != nil {
+		return 0, err
+	}
+	reset := false
+	if cursor == nil {
+		cursor = &domain.SF6RatingCursor{GuildID: guildID}
+	} else {
+		late, err := s.ratingRepo.HasLateBattles(ctx, guildID, cursor.CheckedAt, checkedAt, cursor.BattleAt, cursor.MatchKey)
+		if err != nil {
+			return 0, err
+		}
+		reset = late
+	}
+
+	engine := newSF6RatingEngine(guildID)
+	switch {
+	case reset:
+		cursor.BattleAt, cursor.MatchKey = time.Time{}, ""
+	case cursor.Version > 0:
+		ratings, err := s.ratingRepo.ListRatings(ctx, guildID)
+		if err != nil {
+			return 0, err
+		}
+		engine.load(ratings)
+	}
+
+	applied := 0
+	for {
+		battles, err := s.ratingRepo.BattlesAfter(ctx, guildID, checkedAt, cursor.BattleAt, cursor.MatchKey, sf6RatingBatchSize)
+		if err != nil {
+			return applied, err
+		}
+		if len(battles) == 0 && !reset {
+			// 新しい試合がなければ cursor も進めない（確認時刻だけの書き込みを避ける）
+			return applied, nil
+		}
+		var changes []domain.SF6RatingChange
+		for _, battle := range battles {
+			changes = append(changes, engine.apply(battle)...)
+			cursor.BattleAt, cursor.MatchKey = battle.BattleAt, battle.MatchKey
+		}
+		next := *cursor
+		next.CheckedAt = checkedAt
+		saved, err := s.ratingRepo.Save(ctx, next, engine.takeTouched(), changes, reset)
+		if err != nil {
+			return applied, err
+		}
+		if !saved {
+			return applied, ErrSF6RatingConflict
+		}
+		cursor.Version++
+		reset = false
+		applied += len(battles)
+		if len(battles) < sf6RatingBatchSize {
+			return applied, nil
+		}
+	}
+}
+
+func (s *sf6RatingService) RefreshAll(ctx context.Context, logger PollLogger) {
+	guildIDs, err := s.ratingRepo.GuildIDs(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("sf6 rating guilds: ", err)
+		}
+		return
+	}
+	for _, guildID := range guildIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		applied, err := s.Refresh(ctx, guildID)
+		switch {
+		case errors.Is(err, ErrSF6RatingConflict):
+			// 他のインスタンス（コマンド実行時の更新）が反映済み
+		case err != nil:
+			if ctx.Err() == nil {
+				logger.Error("sf6 rating refresh: guild="+guildID+": ", err)
+			}
+		case applied > 0:
+			logger.Infof("sf6 rating refresh: guild=%s applied=%d", guildID, applied)
+		}
+	}
+}
+
+func (s *sf6RatingService) GetRating(ctx context.Context, guildID, fighterID, character string) (*domain.SF6Rating, error) {
+	return s.ratingRepo.GetRating(ctx, guildID, fighterID, character)
+}
+
+func (s *sf6RatingService) Leaderboard(ctx context.Context, guildID, character string, limit, offset int) ([]domain.SF6Rating, error) {
+	return s.ratingRepo.Leaderboard(ctx, guildID, character, limit, offset)
+}
+
+func (s *sf6RatingService) History(ctx context.Context, guildID, fighterID, character string, limit int) ([]domain.SF6RatingChange, error) {
+	return s.ratingRepo.History(ctx, guildID, fighterID, character, limit)
+}
+
+type sf6RatingKey struct {
+	fighterID string
+	character string
+}
+
+// sf6RatingEngine は試合を古い順に受け取り、全キャラ通算とキャラ別の Elo を更新する。
+type sf6RatingEngine struct {
+	guildID string
+	ratings map[sf6RatingKey]*domain.SF6Rating
+	touched map[sf6RatingKey]struct{}
+}
+
+func newSF6RatingEngine(guildID string) *sf6RatingEngine {
+	return &sf6RatingEngine{
+		guildID: guildID,
+		ratings: make(map[sf6RatingKey]*domain.SF6Rating),
+		touched: make(map[sf6RatingKey]struct{}),
+	}
+}
+
+func (e *sf6RatingEngine) load(ratings []domain.SF6Rating) {
+	for idx := range ratings {
+		rating := ratings[idx]
+		e.ratings[sf6RatingKey{rating.FighterID, rating.Character}] = &rating
+	}
+}
+
+func (e *sf6RatingEngine) get(fighterID, character string) *domain.SF6Rating {
+	key := sf6RatingKey{fighterID, character}
+	rating, ok := e.ratings[key]
+	if !ok {
+		rating = &domain.SF6Rating{
+			GuildID:   e.guildID,
+			FighterID: fighterID,
+			Character: character,
+			Rating:    SF6RatingInitial,
+			Peak:      SF6RatingInitial,
+		}
+		e.ratings[key] = rating
+	}
+	e.touched[key] = struct{}{}
+	return rating
+}
+
+// apply は 1 試合を反映し、両者の（通算とキャラ別の）変化を返す。
+func (e *sf6RatingEngine) apply(battle domain.SF6RatingBattle) []domain.SF6RatingChange {
+	if battle.SubjectFighterID == "" || battle.OpponentFighterID == "" || battle.SubjectFighterID == battle.OpponentFighterID {
+		return nil
+	}
+	var score float64
+	switch battle.Result {
+	case "win":
+		score = 1
+	case "loss":
+		score = 0
+	case "draw":
+		score = 0.5
+	default:
+		return nil
+	}
+	levels := [][2]string{{"", ""}}
+	if battle.SelfCharacter != "" && battle.OpponentCharacter != "" {
+		levels = append(levels, [2]string{battle.SelfCharacter, battle.OpponentCharacter})
+	}
+	changes := make([]domain.SF6RatingChange, 0, 2*len(levels))
+	for _, level := range levels {
+		self := e.get(battle.SubjectFighterID, level[0])
+		oppo := e.get(battle.OpponentFighterID, level[1])
+		expected := 1 / (1 + math.Pow(10, (oppo.Rating-self.Rating)/400))
+		delta := SF6RatingK * (score - expected)
+
+		changes = append(changes,
+			e.update(self, oppo.FighterID, battle.Result, battle.BattleAt, delta),
+			e.update(oppo, self.FighterID, invertSF6Result(battle.Result), battle.BattleAt, -delta),
+		)
+	}
+	return changes
+}
+
+func (e *sf6RatingEngine) update(rating *domain.SF6Rating, opponentID, result string, battleAt time.Time, delta float64) domain.SF6RatingChange {
+	change := domain.SF6RatingChange{
+		GuildID:           e.guildID,
+		FighterID:         rating.FighterID,
+		Character:         rating.Character,
+		OpponentFighterID: opponentID,
+		Result:            result,
+		BattleAt:          battleAt,
+		Before:            rating.Rating,
+	}
+	rating.Rating += delta
+	rating.Peak = math.Max(rating.Peak, rating.Rating)
+	rating.Games++
+	switch result {
+	case "win":
+		rating.Wins++
+	case "loss":
+		rating.Losses++
+	default:
+		rating.Draws++
+	}
+	rating.LastBattleAt = battleAt
+	change.After = rating.Rating
+	return change
+}
+
+// takeTouched は前回以降に変わったレーティングを返す。
+func (e *sf6RatingEngine) takeTouched() []domain.SF6Rating {
+	out := make([]domain.SF6Rating, 0, len(e.touched))
+	for key := range e.touched {
+		out = append(out, *e.ratings[key])
+	}
+	e.touched = make(map[sf6RatingKey]struct{})
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].FighterID != out[b].FighterID {
+			return out[a].FighterID < out[b].FighterID
+		}
+		return out[a].Character < out[b].Character
+	})
+	return out
+}
+
+func invertSF6Result(result string) string {
+	switch result {
+	case "win":
+		return "loss"
+	case "loss":
+		return "win"
+	}
+	return result
+}
+
+// NormalizeSF6Character はコマンドで指定されたキャラ名（"Chun-Li" など）を保存形式の character_tool_name（"chunli"）にそろえる。
+func NormalizeSF6Character(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
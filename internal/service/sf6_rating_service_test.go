@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// stubRatingRepo は sf6_battles の行（保存時刻つき）を持ち、HasLateBattles / BattlesAfter を SQL と同じ条件で絞り込む。
+type stubRatingRepo struct {
+	repository.SF6RatingRepository
+	cursor   *domain.SF6RatingCursor
+	rows     []stubRatingRow
+	stored   map[sf6RatingKey]domain.SF6Rating
+	changes  []domain.SF6RatingChange
+	saves    int
+	resets   int
+	listed   int
+	conflict bool
+	// now は DB の時計。SettledCutoff はここから猶予を引いた時刻を返す。
+	now time.Time
+}
+
+type stubRatingRow struct {
+	battle    domain.SF6RatingBattle
+	createdAt time.Time
+}
+
+func (r *stubRatingRepo) insert(createdAt time.Time, battles ...domain.SF6RatingBattle) {
+	for _, battle := range battles {
+		r.rows = append(r.rows, stubRatingRow{battle: battle, createdAt: createdAt})
+	}
+}
+
+func (r *stubRatingRepo) GetCursor(ctx context.Context, guildID string) (*domain.SF6RatingCursor, error) {
+	if r.cursor == nil {
+		return nil, nil
+	}
+	c := *r.cursor
+	return &c, nil
+}
+
+func (r *stubRatingRepo) SettledCutoff(ctx context.Context, delay time.Duration) (time.Time, error) {
+	return r.now.Add(-delay), nil
+}
+
+// compareRatingPos は (battle_at, match_key) の行比較。
+func compareRatingPos(at time.Time, key string, battleAt time.Time, matchKey string) int {
+	switch {
+	case at.Before(battleAt):
+		return -1
+	case at.After(battleAt):
+		return 1
+	}
+	return strings.Compare(key, matchKey)
+}
+
+func (r *stubRatingRepo) HasLateBattles(ctx context.Context, guildID string, createdAfter, createdUntil, battleAt time.Time, matchKey string) (bool, error) {
+	for _, row := range r.rows {
+		b := row.battle
+		if !row.createdAt.After(createdAfter) || row.createdAt.After(createdUntil) || compareRatingPos(b.BattleAt, b.MatchKey, battleAt, matchKey) > 0 {
+			continue
+		}
+		applied := false
+		for _, other := range r.rows {
+			if !other.createdAt.After(createdAfter) && other.battle.BattleAt.Equal(b.BattleAt) && other.battle.MatchKey == b.MatchKey {
+				applied = true
+				break
+			}
+		}
+		if !applied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *stubRatingRepo) BattlesAfter(ctx context.Context, guildID string, createdUntil, battleAt time.Time, matchKey string, limit int) ([]domain.SF6RatingBattle, error) {
+	var candidates []domain.SF6RatingBattle
+	for _, row := range r.rows {
+		if row.createdAt.After(createdUntil) || compareRatingPos(row.battle.BattleAt, row.battle.MatchKey, battleAt, matchKey) <= 0 {
+			continue
+		}
+		candidates = append(candidates, row.battle)
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if c := compareRatingPos(candidates[a].BattleAt, candidates[a].MatchKey, candidates[b].BattleAt, candidates[b].MatchKey); c != 0 {
+			return c < 0
+		}
+		return candidates[a].SubjectFighterID < candidates[b].SubjectFighterID
+	})
+	// DISTINCT ON (battle_at, match_key)
+	var out []domain.SF6RatingBattle
+	for _, b := range candidates {
+		if n := len(out); n > 0 && out[n-1].BattleAt.Equal(b.BattleAt) && out[n-1].MatchKey == b.MatchKey {
+			continue
+		}
+		out = append(out, b)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r *stubRatingRepo) ListRatings(ctx context.Context, guildID string) ([]domain.SF6Rating, error) {
+	r.listed++
+	var out []domain.SF6Rating
+	for _, rating := range r.stored {
+		out = append(out, rating)
+	}
+	return out, nil
+}
+
+func (r *stubRatingRepo) Save(ctx context.Context, cursor domain.SF6RatingCursor, ratings []domain.SF6Rating, changes []domain.SF6RatingChange, reset bool) (bool, error) {
+	if r.conflict || (r.cursor != nil && r.cursor.Version != cursor.Version) {
+		return false, nil
+	}
+	r.saves++
+	if reset {
+		r.resets++
+		r.stored = nil
+		r.changes = nil
+	}
+	if r.stored == nil {
+		r.stored = make(map[sf6RatingKey]domain.SF6Rating)
+	}
+	for _, rating := range ratings {
+		r.stored[sf6RatingKey{rating.FighterID, rating.Character}] = rating
+	}
+	r.changes = append(r.changes, changes...)
+	cursor.Version++
+	r.cursor = &cursor
+	return true, nil
+}
+
+// ratingBattle は replay の試合を subject 側から見た行を作る。双方向に保存された行は同じ replay を渡す。
+func ratingBattle(replay, subject, opponent, selfChar, oppoChar, result string, at time.Time) domain.SF6RatingBattle {
+	key := subject + ":" + opponent + ":" + replay
+	if opponent < subject {
+		key = opponent + ":" + subject + ":" + replay
+	}
+	return domain.SF6RatingBattle{
+		SubjectFighterID: subject, OpponentFighterID: opponent,
+		SelfCharacter: selfChar, OpponentCharacter: oppoChar,
+		Result: result, BattleAt: at, MatchKey: key,
+	}
+}
+
+func TestSF6RatingRefreshAppliesIncrementally(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubRatingRepo{}
+	repo.insert(base, ratingBattle("R1", "100", "200", "ryu", "ken", "win", base))
+	svc := NewSF6RatingService(repo)
+
+	repo.now = base.Add(time.Hour)
+	applied, err := svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 1 {
+		t.Fatalf("Refresh = %d, %v", applied, err)
+	}
+	overall := repo.stored[sf6RatingKey{"100", ""}]
+	if math.Abs(overall.Rating-(SF6RatingInitial+SF6RatingK/2)) > 1e-9 || overall.Wins != 1 {
+		t.Fatalf("winner overall = %+v", overall)
+	}
+	if loser := repo.stored[sf6RatingKey{"200", "ken"}]; math.Abs(loser.Rating-(SF6RatingInitial-SF6RatingK/2)) > 1e-9 || loser.Losses != 1 {
+		t.Fatalf("loser by character = %+v", loser)
+	}
+	if len(repo.changes) != 4 {
+		t.Fatalf("changes = %d, want 4 (overall and character for both)", len(repo.changes))
+	}
+
+	// 2 回目は続きだけを反映する
+	repo.insert(base.Add(90*time.Minute), ratingBattle("R2", "200", "100", "ken", "ryu", "win", base.Add(time.Minute)))
+	repo.now = base.Add(2 * time.Hour)
+	applied, err = svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 1 {
+		t.Fatalf("second Refresh = %d, %v", applied, err)
+	}
+	if repo.listed != 1 || repo.resets != 0 {
+		t.Fatalf("listed=%d resets=%d, want incremental update", repo.listed, repo.resets)
+	}
+	a, b := repo.stored[sf6RatingKey{"100", ""}], repo.stored[sf6RatingKey{"200", ""}]
+	if math.Abs(a.Rating+b.Rating-2*SF6RatingInitial) > 1e-9 || a.Games != 2 || b.Wins != 1 {
+		t.Fatalf("ratings after rematch: %+v / %+v", a, b)
+	}
+	if !repo.cursor.BattleAt.Equal(base.Add(time.Minute)) || !repo.cursor.CheckedAt.Equal(base.Add(2*time.Hour-sf6RatingSettleDelay)) {
+		t.Fatalf("cursor = %+v", repo.cursor)
+	}
+}
+
+func TestSF6RatingRefreshRebuildsOnLateBattle(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubRatingRepo{}
+	repo.insert(base, ratingBattle("R1", "100", "200", "", "", "win", base))
+	svc := NewSF6RatingService(repo)
+	repo.now = base.Add(time.Hour)
+	if _, err := svc.Refresh(context.Background(), "g1"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	// バックフィルで過去の試合が後から保存された
+	repo.insert(base.Add(90*time.Minute), ratingBattle("R0", "100", "200", "", "", "loss", base.Add(-time.Hour)))
+	repo.now = base.Add(2 * time.Hour)
+	applied, err := svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 2 {
+		t.Fatalf("rebuild Refresh = %d, %v", applied, err)
+	}
+	if repo.resets != 1 || repo.listed != 0 {
+		t.Fatalf("resets=%d listed=%d, want a full rebuild", repo.resets, repo.listed)
+	}
+	if got := repo.stored[sf6RatingKey{"100", ""}]; got.Games != 2 || got.Wins != 1 || got.Losses != 1 {
+		t.Fatalf("rebuilt rating = %+v", got)
+	}
+	if len(repo.changes) != 4 {
+		t.Fatalf("history = %d rows, want 4 after rebuild", len(repo.changes))
+	}
+}
+
+// TestSF6RatingRefreshIgnoresMirroredAndSettlingBattles は、反映済みの試合を相手側から保存した行や、
+// 前回の確認時刻の直後（保存待ちの猶予内）に保存された行で作り直しにならないことを確かめる。
+func TestSF6RatingRefreshIgnoresMirroredAndSettlingBattles(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	first := base.Add(time.Hour)
+	repo := &stubRatingRepo{}
+	repo.insert(base, ratingBattle("R1", "100", "200", "", "", "win", base))
+	// 1 回目の更新の猶予内に保存された、反映済みの試合の相手側の行と、新しい試合
+	repo.insert(first.Add(-time.Second),
+		ratingBattle("R1", "200", "100", "", "", "loss", base),
+		ratingBattle("R2", "100", "300", "", "", "win", base.Add(30*time.Minute)),
+	)
+	svc := NewSF6RatingService(repo)
+
+	repo.now = first
+	applied, err := svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 1 {
+		t.Fatalf("Refresh = %d, %v, want only the settled battle", applied, err)
+	}
+	// 1 回目の確認時刻より後に、反映済みの試合の相手側の行がもう 1 件（別ギルドからの再取得などで）入った
+	repo.insert(first.Add(10*time.Minute), ratingBattle("R1", "200", "100", "", "", "loss", base))
+
+	repo.now = base.Add(2 * time.Hour)
+	applied, err = svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 1 {
+		t.Fatalf("second Refresh = %d, %v, want the battle held back by the settle delay", applied, err)
+	}
+	if repo.resets != 0 || repo.listed != 1 {
+		t.Fatalf("resets=%d listed=%d, want incremental update", repo.resets, repo.listed)
+	}
+	if got := repo.stored[sf6RatingKey{"100", ""}]; got.Games != 2 || got.Wins != 2 {
+		t.Fatalf("rating = %+v, want 2 games counted once each", got)
+	}
+
+	// 新しい試合がなければ保存しない
+	saves := repo.saves
+	repo.now = base.Add(3 * time.Hour)
+	applied, err = svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 0 || repo.saves != saves || repo.resets != 0 {
+		t.Fatalf("idle Refresh = %d, %v (saves %d -> %d, resets %d)", applied, err, saves, repo.saves, repo.resets)
+	}
+}
+
+// TestSF6RatingRefreshKeepsSameSecondMatches は、同じ 2 人の同時刻の別の試合をまとめず、双方向の行だけをまとめることを確かめる。
+func TestSF6RatingRefreshKeepsSameSecondMatches(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubRatingRepo{}
+	repo.insert(base,
+		ratingBattle("R1", "100", "200", "", "", "win", base),
+		ratingBattle("R1", "200", "100", "", "", "loss", base),
+		ratingBattle("R2", "100", "200", "", "", "loss", base),
+		ratingBattle("R2", "200", "100", "", "", "win", base),
+	)
+	svc := NewSF6RatingService(repo)
+
+	repo.now = base.Add(time.Hour)
+	applied, err := svc.Refresh(context.Background(), "g1")
+	if err != nil || applied != 2 {
+		t.Fatalf("Refresh = %d, %v, want 2 distinct matches", applied, err)
+	}
+	if got := repo.stored[sf6RatingKey{"100", ""}]; got.Games != 2 || got.Wins != 1 || got.Losses != 1 {
+		t.Fatalf("rating = %+v, want both matches counted once", got)
+	}
+}
+
+func TestSF6RatingRefreshConflict(t *testing.T) {
+	repo := &stubRatingRepo{conflict: true}
+	repo.insert(time.Now().Add(-time.Hour), ratingBattle("R1", "100", "200", "", "", "win", time.Now().Add(-time.Hour)))
+	svc := NewSF6RatingService(repo)
+	repo.now = time.Now()
+	if _, err := svc.Refresh(context.Background(), "g1"); !errors.Is(err, ErrSF6RatingConflict) {
+		t.Fatalf("err = %v, want ErrSF6RatingConflict", err)
+	}
+}
+
+func TestNormalizeSF6Character(t *testing.T) {
+	if got := NormalizeSF6Character(" Chun-Li "); got != "chunli" {
+		t.Fatalf("got %q", got)
+	}
+}
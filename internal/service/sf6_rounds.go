@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/domain"
+)
+
+func (s *sf6Service) RoundStatsBySession(ctx context.Context, sessionID string) (domain.SF6RoundStats, error) {
+	if s.battleRepo == nil {
+		return domain.SF6RoundStats{}, errors.New("battle repo not configured")
+	}
+	rounds, err := s.battleRepo.RoundsBySession(ctx, sessionID)
+	if err != nil {
+		return domain.SF6RoundStats{}, err
+	}
+	return SummarizeSF6Rounds(rounds), nil
+}
+
+func (s *sf6Service) RoundStatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) (domain.SF6RoundStats, error) {
+	if s.battleRepo == nil {
+		return domain.SF6RoundStats{}, errors.New("battle repo not configured")
+	}
+	rounds, err := s.battleRepo.RoundsByOpponentRange(ctx, guildID, subjectFighterID, opponentFighterID, battleType, startAt, endAt)
+	if err != nil {
+		return domain.SF6RoundStats{}, err
+	}
+	return SummarizeSF6Rounds(rounds), nil
+}
+
+func (s *sf6Service) RoundStatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) (domain.SF6RoundStats, error) {
+	if s.battleRepo == nil {
+		return domain.SF6RoundStats{}, errors.New("battle repo not configured")
+	}
+	rounds, err := s.battleRepo.RoundsByOpponentCount(ctx, guildID, subjectFighterID, opponentFighterID, battleType, limit)
+	if err != nil {
+		return domain.SF6RoundStats{}, err
+	}
+	return SummarizeSF6Rounds(rounds), nil
+}
+
+// SummarizeSF6Rounds は対戦ごと・ラウンド順に並んだラウンド結果を集計する。
+// 対戦の勝敗は sf6_battles.result と同じく、値を持つラウンド数の多い側の勝ちとする。
+func SummarizeSF6Rounds(rounds []domain.SF6BattleRound) domain.SF6RoundStats {
+	stats := domain.SF6RoundStats{
+		SelfFinishes:     make(map[string]int),
+		OpponentFinishes: make(map[string]int),
+	}
+	for start := 0; start < len(rounds); {
+		end := start
+		for end < len(rounds) && rounds[end].BattleID == rounds[start].BattleID {
+			end++
+		}
+		battle := rounds[start:end]
+		start = end
+
+		stats.Battles++
+		selfPoints, oppoPoints := 0, 0
+		for _, round := range battle {
+			if round.SelfCode > 0 {
+				selfPoints++
+			}
+			if round.OpponentCode > 0 {
+				oppoPoints++
+			}
+			switch round.Winner {
+			case "self":
+				stats.SelfRounds++
+				stats.SelfFinishes[round.Finish]++
+			case "opponent":
+				stats.OpponentRounds++
+				stats.OpponentFinishes[round.Finish]++
+			}
+		}
+		first := battle[0]
+		if first.RoundNo != 1 {
+			continue
+		}
+		switch first.Winner {
+		case "self":
+			stats.RoundOneWins++
+			stats.RoundOneDecided++
+		case "opponent":
+			stats.RoundOneDecided++
+			stats.LostRoundOne++
+			if selfPoints > oppoPoints {
+				stats.Comebacks++
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+)
+
+func testBattleRounds(battleID string, self, oppo []int) []domain.SF6BattleRound {
+	rounds := sf6BattleRounds(self, oppo)
+	for idx := range rounds {
+		rounds[idx].BattleID = battleID
+	}
+	return rounds
+}
+
+func TestSummarizeSF6Rounds(t *testing.T) {
+	var rounds []domain.SF6BattleRound
+	rounds = append(rounds, testBattleRounds("a", []int{0, 6, 7}, []int{1, 0, 0})...) // 1 ラウンド目を落として SA / CA で逆転
+	rounds = append(rounds, testBattleRounds("b", []int{5, 1}, []int{0, 0})...)       // Perfect からのストレート勝ち
+	rounds = append(rounds, testBattleRounds("c", []int{0, 1, 0}, []int{7, 0, 3})...) // 1 ラウンド目を落として負け
+	rounds = append(rounds, testBattleRounds("d", []int{4, 1}, []int{4, 0})...)       // 1 ラウンド目がダブル KO
+
+	stats := SummarizeSF6Rounds(rounds)
+	if stats.Battles != 4 {
+		t.Fatalf("battles = %d, want 4", stats.Battles)
+	}
+	if stats.RoundOneWins != 1 || stats.RoundOneDecided != 3 {
+		t.Fatalf("round one = %d/%d, want 1/3", stats.RoundOneWins, stats.RoundOneDecided)
+	}
+	if stats.Comebacks != 1 || stats.LostRoundOne != 2 {
+		t.Fatalf("comebacks = %d/%d, want 1/2", stats.Comebacks, stats.LostRoundOne)
+	}
+	if stats.SelfRounds != 6 || stats.OpponentRounds != 3 {
+		t.Fatalf("rounds = %d-%d, want 6-3", stats.SelfRounds, stats.OpponentRounds)
+	}
+	if stats.SelfFinishes[buckler.RoundFinishSuperArt] != 1 || stats.SelfFinishes[buckler.RoundFinishCriticalArt] != 1 || stats.SelfFinishes[buckler.RoundFinishPerfect] != 1 {
+		t.Fatalf("self finishes = %v", stats.SelfFinishes)
+	}
+	if stats.OpponentFinishes[buckler.RoundFinishCriticalArt] != 1 || stats.OpponentFinishes[buckler.RoundFinishTime] != 1 {
+		t.Fatalf("opponent finishes = %v", stats.OpponentFinishes)
+	}
+}
+
+func TestSF6BattleRoundsDoubleKO(t *testing.T) {
+	rounds := sf6BattleRounds([]int{4}, []int{4})
+	if len(rounds) != 1 || rounds[0].Winner != "draw" || rounds[0].Finish != buckler.RoundFinishDoubleKO {
+		t.Fatalf("rounds = %+v", rounds)
+	}
+}
@@ -13,56 +13,180 @@ import (
 )
 
 type BucklerClient interface {
-	FetchCustomBattlelog(ctx context.Context, sid string, page int) (buckler.BattlelogResponse, error)
+	FetchBattlelog(ctx context.Context, sid string, kind buckler.BattlelogKind, page int) (buckler.BattlelogResponse, error)
 	FetchCard(ctx context.Context, sid string) (buckler.CardResponse, error)
 }
 
 type SF6Service interface {
+	FetchAndStoreBattles(ctx context.Context, guildID, userID, sid string, kind buckler.BattlelogKind, page int) (int, bool, error)
 	FetchAndStoreCustomBattles(ctx context.Context, guildID, userID, sid string, page int) (int, bool, error)
+	FetchAndStoreBattlePage(ctx context.Context, guildID, userID, sid string, kind buckler.BattlelogKind, page int) (int, int, error)
+	FetchAndStoreBattlesForOwners(ctx context.Context, sid string, kind buckler.BattlelogKind, page int, owners []SF6FetchOwner) (int, bool, error)
 	FetchCard(ctx context.Context, sid string) (buckler.CardResponse, error)
-	StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error)
-	StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit int) ([]domain.SF6BattleStatRow, error)
-	HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit, offset int) ([]domain.SF6BattleHistoryRow, error)
-	CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) (int, error)
-	BattleTimesByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) ([]time.Time, error)
+	AssignSessions(ctx context.Context, guildID, subjectFighterID string) (int64, error)
+	StatsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleStatRow, error)
+	HistoryBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleHistoryRow, error)
+	StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error)
+	StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleStatRow, error)
+	MomentumBySession(ctx context.Context, sessionID string) (domain.SF6Momentum, error)
+	MomentumByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) (domain.SF6Momentum, error)
+	MomentumByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) (domain.SF6Momentum, error)
+	RoundStatsBySession(ctx context.Context, sessionID string) (domain.SF6RoundStats, error)
+	RoundStatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) (domain.SF6RoundStats, error)
+	RoundStatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) (domain.SF6RoundStats, error)
+	MatchupMatrix(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, minGames int) (*domain.SF6MatchupMatrix, error)
+	HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit, offset int) ([]domain.SF6BattleHistoryRow, error)
+	CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) (int, error)
+	ResultsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6BattleResult, error)
+	Leaderboard(ctx context.Context, guildID, battleType string, startAt, endAt time.Time, sortBy string, minGames int) (*domain.SF6Leaderboard, error)
+	Compare(ctx context.Context, guildID, fighterA, fighterB, battleType string) (*domain.SF6Comparison, error)
+}
+
+// SF6FetchOwner は取得した戦績の保存先（ギルドとユーザー）。
+type SF6FetchOwner struct {
+	GuildID string
+	UserID  string
 }
 
 type sf6Service struct {
 	bucklerClient BucklerClient
 	battleRepo    repository.SF6BattleRepository
 	accountRepo   repository.SF6AccountRepository
+	fetches       *sf6FetchGroup
 }
 
-func NewSF6Service(bucklerClient BucklerClient, battleRepo repository.SF6BattleRepository, accountRepo repository.SF6AccountRepository) SF6Service {
-	return &sf6Service{bucklerClient: bucklerClient, battleRepo: battleRepo, accountRepo: accountRepo}
+// NewSF6Service は freshness 以内に取得済みの (sid, 種別, ページ) を Buckler に問い合わせずに返す。
+// freshness が 0 以下ならキャッシュせず、同時に走る同じ取得をまとめるだけにする。
+func NewSF6Service(bucklerClient BucklerClient, battleRepo repository.SF6BattleRepository, accountRepo repository.SF6AccountRepository, freshness time.Duration) SF6Service {
+	return &sf6Service{
+		bucklerClient: bucklerClient,
+		battleRepo:    battleRepo,
+		accountRepo:   accountRepo,
+		fetches:       newSF6FetchGroup(freshness),
+	}
 }
 
 func (s *sf6Service) FetchAndStoreCustomBattles(ctx context.Context, guildID, userID, sid string, page int) (int, bool, error) {
+	return s.FetchAndStoreBattles(ctx, guildID, userID, sid, buckler.BattlelogCustom, page)
+}
+
+func (s *sf6Service) FetchAndStoreBattles(ctx context.Context, guildID, userID, sid string, kind buckler.BattlelogKind, page int) (int, bool, error) {
 	if guildID == "" || userID == "" || sid == "" {
 		return 0, false, errors.New("guildID, userID, sid are required")
 	}
-	ownerKind := "account"
-	if s.accountRepo != nil {
-		owner, err := s.accountRepo.GetByFighter(ctx, guildID, sid)
+	if kind == "" {
+		kind = buckler.BattlelogCustom
+	}
+	ownerKind, skip, err := s.resolveOwnerKind(ctx, guildID, userID, sid)
+	if err != nil {
+		return 0, false, err
+	}
+	if skip {
+		return 0, true, nil
+	}
+	key := sf6FetchKey{guildID: guildID, sid: sid, kind: kind, page: page}
+	return s.fetches.do(ctx, key, func() (int, bool, error) {
+		count, allExisting, _, err := s.fetchAndStore(ctx, guildID, userID, sid, ownerKind, kind, page)
+		return count, allExisting, err
+	})
+}
+
+// FetchAndStoreBattlePage は 1 ページを取得して保存し、保存件数と総ページ数を返す。
+// 既存データでの打ち切りや鮮度キャッシュは使わない（バックフィル用）。
+func (s *sf6Service) FetchAndStoreBattlePage(ctx context.Context, guildID, userID, sid string, kind buckler.BattlelogKind, page int) (int, int, error) {
+	if guildID == "" || userID == "" || sid == "" {
+		return 0, 0, errors.New("guildID, userID, sid are required")
+	}
+	if kind == "" {
+		kind = buckler.BattlelogCustom
+	}
+	ownerKind, skip, err := s.resolveOwnerKind(ctx, guildID, userID, sid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if skip {
+		return 0, 0, fmt.Errorf("sid %s is linked to another user", sid)
+	}
+	count, _, totalPage, err := s.fetchAndStore(ctx, guildID, userID, sid, ownerKind, kind, page)
+	return count, totalPage, err
+}
+
+// resolveOwnerKind は sid が連携アカウントかフレンドかを返す。他ユーザーの連携アカウントなら skip を返す。
+func (s *sf6Service) resolveOwnerKind(ctx context.Context, guildID, userID, sid string) (string, bool, error) {
+	if s.accountRepo == nil {
+		return "account", false, nil
+	}
+	owner, err := s.accountRepo.GetByFighter(ctx, guildID, sid)
+	if err != nil {
+		return "", false, err
+	}
+	if owner == nil {
+		return "friend", false, nil
+	}
+	if owner.UserID != userID {
+		return "", true, nil
+	}
+	return "account", false, nil
+}
+
+// FetchAndStoreBattlesForOwners は 1 ページを 1 回だけ取得し、保存先ごとに保存する。
+// 保存件数は全保存先の合計で、allExisting は全保存先で既存だった場合に true になる。
+// 他ユーザーの連携アカウントに当たる保存先はスキップする。
+func (s *sf6Service) FetchAndStoreBattlesForOwners(ctx context.Context, sid string, kind buckler.BattlelogKind, page int, owners []SF6FetchOwner) (int, bool, error) {
+	if sid == "" || len(owners) == 0 {
+		return 0, false, errors.New("sid, owners are required")
+	}
+	if kind == "" {
+		kind = buckler.BattlelogCustom
+	}
+	type target struct {
+		owner     SF6FetchOwner
+		ownerKind string
+	}
+	targets := make([]target, 0, len(owners))
+	for _, owner := range owners {
+		ownerKind, skip, err := s.resolveOwnerKind(ctx, owner.GuildID, owner.UserID, sid)
 		if err != nil {
 			return 0, false, err
 		}
-		if owner != nil {
-			if owner.UserID != userID {
-				return 0, true, nil
-			}
-			ownerKind = "account"
-		} else {
-			ownerKind = "friend"
+		if !skip {
+			targets = append(targets, target{owner: owner, ownerKind: ownerKind})
 		}
 	}
-	res, err := s.bucklerClient.FetchCustomBattlelog(ctx, sid, page)
+	if len(targets) == 0 {
+		return 0, true, nil
+	}
+	res, err := s.bucklerClient.FetchBattlelog(ctx, sid, kind, page)
 	if err != nil {
 		return 0, false, err
 	}
+	total := 0
+	allExisting := true
+	for _, t := range targets {
+		count, existing, err := s.storePage(ctx, t.owner.GuildID, t.owner.UserID, sid, t.ownerKind, kind, res)
+		total += count
+		if err != nil {
+			return total, false, err
+		}
+		allExisting = allExisting && existing
+	}
+	return total, allExisting, nil
+}
+
+func (s *sf6Service) fetchAndStore(ctx context.Context, guildID, userID, sid, ownerKind string, kind buckler.BattlelogKind, page int) (int, bool, int, error) {
+	res, err := s.bucklerClient.FetchBattlelog(ctx, sid, kind, page)
+	if err != nil {
+		return 0, false, 0, err
+	}
+	count, allExisting, err := s.storePage(ctx, guildID, userID, sid, ownerKind, kind, res)
+	return count, allExisting, res.PageProps.TotalPage, err
+}
+
+// storePage は取得済みのページをギルドの戦績として保存する。すべて既存なら allExisting を返す。
+func (s *sf6Service) storePage(ctx context.Context, guildID, userID, sid, ownerKind string, kind buckler.BattlelogKind, res buckler.BattlelogResponse) (int, bool, error) {
 	battles := make([]domain.SF6Battle, 0, len(res.PageProps.ReplayList))
 	for _, entry := range res.PageProps.ReplayList {
-		battle, ok := buildBattleFromReplay(guildID, userID, sid, ownerKind, entry)
+		battle, ok := buildBattleFromReplay(guildID, userID, sid, ownerKind, kind, entry)
 		if !ok {
 			continue
 		}
@@ -86,6 +210,9 @@ func (s *sf6Service) FetchAndStoreCustomBattles(ctx context.Context, guildID, us
 	if err != nil {
 		return 0, false, err
 	}
+	if _, err := s.battleRepo.AssignSessions(ctx, guildID, sid); err != nil {
+		return count, false, err
+	}
 	return count, false, nil
 }
 
@@ -96,42 +223,63 @@ func (s *sf6Service) FetchCard(ctx context.Context, sid string) (buckler.CardRes
 	return s.bucklerClient.FetchCard(ctx, sid)
 }
 
-func (s *sf6Service) StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error) {
+func (s *sf6Service) AssignSessions(ctx context.Context, guildID, subjectFighterID string) (int64, error) {
+	if s.battleRepo == nil {
+		return 0, errors.New("battle repo not configured")
+	}
+	return s.battleRepo.AssignSessions(ctx, guildID, subjectFighterID)
+}
+
+func (s *sf6Service) StatsBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleStatRow, error) {
+	if s.battleRepo == nil {
+		return nil, errors.New("battle repo not configured")
+	}
+	return s.battleRepo.StatsBySession(ctx, sessionID)
+}
+
+func (s *sf6Service) HistoryBySession(ctx context.Context, sessionID string) ([]domain.SF6BattleHistoryRow, error) {
+	if s.battleRepo == nil {
+		return nil, errors.New("battle repo not configured")
+	}
+	return s.battleRepo.HistoryBySession(ctx, sessionID)
+}
+
+func (s *sf6Service) StatsByOpponentRange(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, startAt, endAt time.Time) ([]domain.SF6BattleStatRow, error) {
 	if s.battleRepo == nil {
 		return nil, errors.New("battle repo not configured")
 	}
-	return s.battleRepo.StatsByOpponentRange(ctx, guildID, subjectFighterID, opponentFighterID, startAt, endAt)
+	return s.battleRepo.StatsByOpponentRange(ctx, guildID, subjectFighterID, opponentFighterID, battleType, startAt, endAt)
 }
 
-func (s *sf6Service) StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit int) ([]domain.SF6BattleStatRow, error) {
+func (s *sf6Service) StatsByOpponentCount(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit int) ([]domain.SF6BattleStatRow, error) {
 	if s.battleRepo == nil {
 		return nil, errors.New("battle repo not configured")
 	}
-	return s.battleRepo.StatsByOpponentCount(ctx, guildID, subjectFighterID, opponentFighterID, limit)
+	return s.battleRepo.StatsByOpponentCount(ctx, guildID, subjectFighterID, opponentFighterID, battleType, limit)
 }
 
-func (s *sf6Service) HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string, limit, offset int) ([]domain.SF6BattleHistoryRow, error) {
+func (s *sf6Service) HistoryByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string, limit, offset int) ([]domain.SF6BattleHistoryRow, error) {
 	if s.battleRepo == nil {
 		return nil, errors.New("battle repo not configured")
 	}
-	return s.battleRepo.HistoryByOpponent(ctx, guildID, subjectFighterID, opponentFighterID, limit, offset)
+	return s.battleRepo.HistoryByOpponent(ctx, guildID, subjectFighterID, opponentFighterID, battleType, limit, offset)
 }
 
-func (s *sf6Service) CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) (int, error) {
+func (s *sf6Service) CountByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) (int, error) {
 	if s.battleRepo == nil {
 		return 0, errors.New("battle repo not configured")
 	}
-	return s.battleRepo.CountByOpponent(ctx, guildID, subjectFighterID, opponentFighterID)
+	return s.battleRepo.CountByOpponent(ctx, guildID, subjectFighterID, opponentFighterID, battleType)
 }
 
-func (s *sf6Service) BattleTimesByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID string) ([]time.Time, error) {
+func (s *sf6Service) ResultsByOpponent(ctx context.Context, guildID, subjectFighterID, opponentFighterID, battleType string) ([]domain.SF6BattleResult, error) {
 	if s.battleRepo == nil {
 		return nil, errors.New("battle repo not configured")
 	}
-	return s.battleRepo.BattleTimesByOpponent(ctx, guildID, subjectFighterID, opponentFighterID)
+	return s.battleRepo.ResultsByOpponent(ctx, guildID, subjectFighterID, opponentFighterID, battleType)
 }
 
-func buildBattleFromReplay(guildID, userID, sid, ownerKind string, entry buckler.ReplayEntry) (domain.SF6Battle, bool) {
+func buildBattleFromReplay(guildID, userID, sid, ownerKind string, kind buckler.BattlelogKind, entry buckler.ReplayEntry) (domain.SF6Battle, bool) {
 	selfSID, err := strconv.ParseInt(sid, 10, 64)
 	if err != nil {
 		return domain.SF6Battle{}, false
@@ -175,6 +323,12 @@ func buildBattleFromReplay(guildID, userID, sid, ownerKind string, entry buckler
 		)
 	}
 
+	// 一覧の種別より replay 自体の種別を優先する（判別できなければ取得元の種別）
+	battleType := entry.Kind()
+	if battleType == "" {
+		battleType = kind
+	}
+
 	raw, _ := json.Marshal(entry)
 
 	return domain.SF6Battle{
@@ -192,5 +346,33 @@ func buildBattleFromReplay(guildID, userID, sid, ownerKind string, entry buckler
 		SourceKey:         sourceKey,
 		SessionID:         nil,
 		RawPayload:        raw,
+		BattleType:        string(battleType),
+		Rounds:            sf6BattleRounds(self.RoundResults, oppo.RoundResults),
 	}, true
 }
+
+// sf6BattleRounds は両者の round_results をラウンドごとの勝者と決着の種類にする。
+// 片方だけが値を持つラウンドはその側の勝ち、両方（ダブル KO）またはどちらも持たないラウンドは draw。
+func sf6BattleRounds(self, oppo []int) []domain.SF6BattleRound {
+	rounds := make([]domain.SF6BattleRound, 0, len(self))
+	for idx, selfCode := range self {
+		oppoCode := 0
+		if idx < len(oppo) {
+			oppoCode = oppo[idx]
+		}
+		round := domain.SF6BattleRound{RoundNo: idx + 1, SelfCode: selfCode, OpponentCode: oppoCode}
+		switch {
+		case selfCode > 0 && oppoCode == 0:
+			round.Winner = "self"
+			round.Finish = buckler.RoundFinish(selfCode)
+		case oppoCode > 0 && selfCode == 0:
+			round.Winner = "opponent"
+			round.Finish = buckler.RoundFinish(oppoCode)
+		default:
+			round.Winner = "draw"
+			round.Finish = buckler.RoundFinish(max(selfCode, oppoCode))
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds
+}
@@ -9,9 +9,15 @@ import (
 )
 
 type SF6SessionService interface {
-	Start(ctx context.Context, guildID, userID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error)
+	Start(ctx context.Context, guildID, userID, subjectFighterID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error)
 	End(ctx context.Context, guildID, userID, opponentFighterID string, endedAt time.Time) (*domain.SF6Session, error)
+	EndByID(ctx context.Context, id string, endedAt time.Time) (*domain.SF6Session, error)
 	GetActive(ctx context.Context, guildID, userID, opponentFighterID string) (*domain.SF6Session, error)
+	ListActive(ctx context.Context) ([]domain.SF6Session, error)
+	GetByID(ctx context.Context, id string) (*domain.SF6Session, error)
+	ListRecent(ctx context.Context, guildID, userID, opponentFighterID string, limit int) ([]domain.SF6Session, error)
+	MarkPolled(ctx context.Context, id string, polledAt time.Time, lastSeenBattleAt *time.Time) error
+	AttachMessage(ctx context.Context, id, channelID, messageID string) error
 }
 
 type sf6SessionService struct {
@@ -22,11 +28,11 @@ func NewSF6SessionService(repo repository.SF6SessionRepository) SF6SessionServic
 	return &sf6SessionService{repo: repo}
 }
 
-func (s *sf6SessionService) Start(ctx context.Context, guildID, userID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error) {
+func (s *sf6SessionService) Start(ctx context.Context, guildID, userID, subjectFighterID, opponentFighterID string, startedAt time.Time) (*domain.SF6Session, error) {
 	if guildID == "" || userID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, userID, opponentFighterID are required")
 	}
-	return s.repo.Start(ctx, guildID, userID, opponentFighterID, startedAt)
+	return s.repo.Start(ctx, guildID, userID, subjectFighterID, opponentFighterID, startedAt)
 }
 
 func (s *sf6SessionService) End(ctx context.Context, guildID, userID, opponentFighterID string, endedAt time.Time) (*domain.SF6Session, error) {
@@ -36,9 +42,48 @@ func (s *sf6SessionService) End(ctx context.Context, guildID, userID, opponentFi
 	return s.repo.End(ctx, guildID, userID, opponentFighterID, endedAt)
 }
 
+func (s *sf6SessionService) EndByID(ctx context.Context, id string, endedAt time.Time) (*domain.SF6Session, error) {
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+	return s.repo.EndByID(ctx, id, endedAt)
+}
+
 func (s *sf6SessionService) GetActive(ctx context.Context, guildID, userID, opponentFighterID string) (*domain.SF6Session, error) {
 	if guildID == "" || userID == "" || opponentFighterID == "" {
 		return nil, errors.New("guildID, userID, opponentFighterID are required")
 	}
 	return s.repo.GetActive(ctx, guildID, userID, opponentFighterID)
 }
+
+func (s *sf6SessionService) ListActive(ctx context.Context) ([]domain.SF6Session, error) {
+	return s.repo.ListActive(ctx)
+}
+
+func (s *sf6SessionService) GetByID(ctx context.Context, id string) (*domain.SF6Session, error) {
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *sf6SessionService) ListRecent(ctx context.Context, guildID, userID, opponentFighterID string, limit int) ([]domain.SF6Session, error) {
+	if guildID == "" || userID == "" {
+		return nil, errors.New("guildID and userID are required")
+	}
+	return s.repo.ListRecent(ctx, guildID, userID, opponentFighterID, limit)
+}
+
+func (s *sf6SessionService) MarkPolled(ctx context.Context, id string, polledAt time.Time, lastSeenBattleAt *time.Time) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	return s.repo.MarkPolled(ctx, id, polledAt, lastSeenBattleAt)
+}
+
+func (s *sf6SessionService) AttachMessage(ctx context.Context, id, channelID, messageID string) error {
+	if id == "" || channelID == "" || messageID == "" {
+		return errors.New("id, channelID, messageID are required")
+	}
+	return s.repo.AttachMessage(ctx, id, channelID, messageID)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/buckler"
+	"backend/internal/domain"
+	"backend/internal/repository"
+)
+
+// SF6SessionNotifier はセッションのスコアボード（公開メッセージ）を更新する。Discord 側で実装する。
+// 戻り値はスコアボードを表示しているチャンネル/メッセージ ID（新規投稿した場合は新しい ID）。
+type SF6SessionNotifier interface {
+	UpdateSessionScoreboard(ctx context.Context, session domain.SF6Session, battles []domain.SF6BattleHistoryRow) (string, string, error)
+}
+
+// RunSF6SessionWatcher はアクティブなセッションを interval ごとにポーリングし、
+// スコアボードを更新する。最後の試合（無ければ開始時刻）から idleTimeout 経過したセッションは自動終了する。
+func RunSF6SessionWatcher(
+	ctx context.Context,
+	interval time.Duration,
+	idleTimeout time.Duration,
+	kinds []buckler.BattlelogKind,
+	sessionService SF6SessionService,
+	sf6Service SF6Service,
+	accountRepo repository.SF6AccountRepository,
+	runRepo repository.SF6FetchRunRepository,
+	notifier SF6SessionNotifier,
+	logger PollLogger,
+) {
+	if interval <= 0 || sessionService == nil || sf6Service == nil {
+		return
+	}
+	if len(kinds) == 0 {
+		kinds = []buckler.BattlelogKind{buckler.BattlelogCustom}
+	}
+	logger.Infof("sf6 session watcher start: interval=%s idle_timeout=%s", interval, idleTimeout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runSF6SessionWatchOnce(ctx, idleTimeout, kinds, sessionService, sf6Service, accountRepo, runRepo, notifier, logger)
+		}
+	}
+}
+
+func runSF6SessionWatchOnce(
+	ctx context.Context,
+	idleTimeout time.Duration,
+	kinds []buckler.BattlelogKind,
+	sessionService SF6SessionService,
+	sf6Service SF6Service,
+	accountRepo repository.SF6AccountRepository,
+	runRepo repository.SF6FetchRunRepository,
+	notifier SF6SessionNotifier,
+	logger PollLogger,
+) {
+	sessions, err := sessionService.ListActive(ctx)
+	if err != nil {
+		logger.Error("sf6 session watch list: ", err)
+		return
+	}
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			return
+		}
+		watchSF6Session(ctx, session, idleTimeout, kinds, sessionService, sf6Service, accountRepo, runRepo, notifier, logger)
+	}
+}
+
+func watchSF6Session(
+	ctx context.Context,
+	session domain.SF6Session,
+	idleTimeout time.Duration,
+	kinds []buckler.BattlelogKind,
+	sessionService SF6SessionService,
+	sf6Service SF6Service,
+	accountRepo repository.SF6AccountRepository,
+	runRepo repository.SF6FetchRunRepository,
+	notifier SF6SessionNotifier,
+	logger PollLogger,
+) {
+	now := time.Now().UTC()
+	var battles []domain.SF6BattleHistoryRow
+	if session.SubjectFighterID != "" {
+		fetchUserID := session.UserID
+		if accountRepo != nil {
+			if owner, err := accountRepo.GetByFighter(ctx, session.GuildID, session.SubjectFighterID); err == nil && owner != nil && owner.UserID != "" {
+				fetchUserID = owner.UserID
+			}
+		}
+		// セッション中は増分だけ拾えればよいので 1 ページ目のみ取得する
+		started := time.Now()
+		var fetchErr error
+		saved, pages, _ := fetchSF6Pages(ctx, sf6Service, session.GuildID, fetchUserID, session.SubjectFighterID, kinds, 1, func(err error) {
+			fetchErr = err
+			logger.Error("sf6 session watch fetch: ", err)
+		})
+		run := NewSF6FetchRun(domain.SF6FetchTriggerSession, session.GuildID, session.SubjectFighterID, 1, pages, saved, started, fetchErr)
+		if err := recordSF6FetchRun(ctx, runRepo, run); err != nil {
+			logger.Error("sf6 session watch record run: ", err)
+		}
+		rows, err := sf6Service.HistoryBySession(ctx, session.ID)
+		if err != nil {
+			logger.Error("sf6 session watch history: ", err)
+		} else {
+			battles = rows
+		}
+		var lastSeen *time.Time
+		if len(battles) > 0 {
+			t := battles[len(battles)-1].BattleAt
+			lastSeen = &t
+			if session.LastSeenBattleAt == nil || t.After(*session.LastSeenBattleAt) {
+				session.LastSeenBattleAt = &t
+			}
+		}
+		if err := sessionService.MarkPolled(ctx, session.ID, now, lastSeen); err != nil {
+			logger.Error("sf6 session watch mark polled: ", err)
+		}
+		session.LastPolledAt = now
+	}
+
+	lastActivity := session.StartedAt
+	if session.LastSeenBattleAt != nil && session.LastSeenBattleAt.After(lastActivity) {
+		lastActivity = *session.LastSeenBattleAt
+	}
+	if idleTimeout > 0 && now.Sub(lastActivity) >= idleTimeout {
+		ended, err := sessionService.EndByID(ctx, session.ID, now)
+		if err != nil {
+			logger.Error("sf6 session watch end: ", err)
+		} else if ended != nil {
+			session = *ended
+			logger.Infof("sf6 session idle ended: guild=%s user=%s opponent=%s session=%s", session.GuildID, session.UserID, session.OpponentFighterID, session.ID)
+		}
+	}
+
+	if notifier == nil || session.ChannelID == "" {
+		return
+	}
+	channelID, messageID, err := notifier.UpdateSessionScoreboard(ctx, session, battles)
+	if err != nil {
+		logger.Error("sf6 session watch scoreboard: ", err)
+		return
+	}
+	if messageID != "" && (channelID != session.ChannelID || messageID != session.MessageID) {
+		if err := sessionService.AttachMessage(ctx, session.ID, channelID, messageID); err != nil {
+			logger.Error("sf6 session watch attach message: ", err)
+		}
+	}
+}
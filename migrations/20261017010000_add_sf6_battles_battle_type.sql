@@ -0,0 +1,6 @@
+-- Add battle_type (rank/casual/hub/custom) to sf6_battles
+ALTER TABLE "public"."sf6_battles"
+  ADD COLUMN "battle_type" text NOT NULL DEFAULT 'custom',
+  ADD CONSTRAINT "sf6_battles_battle_type_check" CHECK ("battle_type" IN ('rank','casual','hub','custom'));
+-- Create index "sf6_battles_guild_subject_opponent_type_idx" to table: "sf6_battles"
+CREATE INDEX "sf6_battles_guild_subject_opponent_type_idx" ON "public"."sf6_battles" ("guild_id", "subject_fighter_id", "opponent_fighter_id", "battle_type", "battle_at");
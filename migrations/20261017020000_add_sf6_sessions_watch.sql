@@ -0,0 +1,7 @@
+-- Add watcher columns (subject / scoreboard message) to sf6_sessions
+ALTER TABLE "public"."sf6_sessions"
+  ADD COLUMN "subject_fighter_id" text NULL,
+  ADD COLUMN "channel_id" text NULL,
+  ADD COLUMN "message_id" text NULL;
+-- Create index "sf6_sessions_status_idx" to table: "sf6_sessions"
+CREATE INDEX "sf6_sessions_status_idx" ON "public"."sf6_sessions" ("status");
@@ -0,0 +1,2 @@
+-- Create index "sf6_battles_session_id_idx" to table: "sf6_battles"
+CREATE INDEX "sf6_battles_session_id_idx" ON "public"."sf6_battles" ("session_id");
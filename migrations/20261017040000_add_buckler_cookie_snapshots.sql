@@ -0,0 +1,9 @@
+-- Create "buckler_cookie_snapshots" table
+CREATE TABLE "public"."buckler_cookie_snapshots" (
+  "account_key" text NOT NULL,
+  "key_id" text NOT NULL,
+  "payload" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("account_key")
+);
@@ -0,0 +1,25 @@
+-- Create "sf6_backfill_jobs" table
+CREATE TABLE "public"."sf6_backfill_jobs" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "guild_id" text NOT NULL,
+  "user_id" text NOT NULL,
+  "subject_fighter_id" text NOT NULL,
+  "battle_type" text NOT NULL,
+  "status" text NOT NULL DEFAULT 'running',
+  "next_page" integer NOT NULL DEFAULT 1,
+  "total_page" integer NOT NULL DEFAULT 0,
+  "saved_count" integer NOT NULL DEFAULT 0,
+  "last_error" text NULL,
+  "started_at" timestamptz NOT NULL DEFAULT now(),
+  "finished_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "sf6_backfill_jobs_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_backfill_jobs_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_backfill_jobs_status_check" CHECK (status = ANY (ARRAY['running'::text, 'paused'::text, 'done'::text, 'failed'::text]))
+);
+-- Create index "sf6_backfill_jobs_subject_kind_key" to table: "sf6_backfill_jobs"
+CREATE UNIQUE INDEX "sf6_backfill_jobs_subject_kind_key" ON "public"."sf6_backfill_jobs" ("guild_id", "subject_fighter_id", "battle_type");
+-- Create index "sf6_backfill_jobs_status_idx" to table: "sf6_backfill_jobs"
+CREATE INDEX "sf6_backfill_jobs_status_idx" ON "public"."sf6_backfill_jobs" ("status");
@@ -0,0 +1,17 @@
+-- Create "sf6_poll_schedules" table
+CREATE TABLE "public"."sf6_poll_schedules" (
+  "guild_id" text NOT NULL,
+  "fighter_id" text NOT NULL,
+  "user_id" text NOT NULL,
+  "interval_seconds" integer NOT NULL,
+  "next_poll_at" timestamptz NOT NULL,
+  "last_polled_at" timestamptz NULL,
+  "last_new_battle_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("guild_id", "fighter_id"),
+  CONSTRAINT "sf6_poll_schedules_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_poll_schedules_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "sf6_poll_schedules_next_poll_at_idx" to table: "sf6_poll_schedules"
+CREATE INDEX "sf6_poll_schedules_next_poll_at_idx" ON "public"."sf6_poll_schedules" ("next_poll_at");
@@ -0,0 +1,24 @@
+-- Create "sf6_fetch_runs" table
+CREATE TABLE "public"."sf6_fetch_runs" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "guild_id" text NOT NULL,
+  "fighter_id" text NOT NULL,
+  "trigger" text NOT NULL,
+  "attempt" integer NOT NULL DEFAULT 1,
+  "pages" integer NOT NULL DEFAULT 0,
+  "saved_count" integer NOT NULL DEFAULT 0,
+  "duration_ms" integer NOT NULL DEFAULT 0,
+  "error_class" text NULL,
+  "error_message" text NULL,
+  "started_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "sf6_fetch_runs_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_fetch_runs_trigger_check" CHECK ("trigger" = ANY (ARRAY['poller'::text, 'manual'::text, 'stats'::text, 'session'::text]))
+);
+-- Create index "sf6_fetch_runs_guild_fighter_started_at_idx" to table: "sf6_fetch_runs"
+CREATE INDEX "sf6_fetch_runs_guild_fighter_started_at_idx" ON "public"."sf6_fetch_runs" ("guild_id", "fighter_id", "started_at" DESC);
+-- Create index "sf6_fetch_runs_guild_failed_idx" to table: "sf6_fetch_runs"
+CREATE INDEX "sf6_fetch_runs_guild_failed_idx" ON "public"."sf6_fetch_runs" ("guild_id", "started_at" DESC) WHERE ("error_class" IS NOT NULL);
+-- Create index "sf6_fetch_runs_started_at_idx" to table: "sf6_fetch_runs"
+CREATE INDEX "sf6_fetch_runs_started_at_idx" ON "public"."sf6_fetch_runs" ("started_at");
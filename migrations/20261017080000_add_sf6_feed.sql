@@ -0,0 +1,22 @@
+-- Create "sf6_feed_channels" table
+CREATE TABLE "public"."sf6_feed_channels" (
+  "guild_id" text NOT NULL,
+  "channel_id" text NOT NULL,
+  "configured_by" text NOT NULL,
+  "cursor_at" timestamptz NOT NULL DEFAULT now(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("guild_id"),
+  CONSTRAINT "sf6_feed_channels_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create "sf6_feed_optouts" table
+CREATE TABLE "public"."sf6_feed_optouts" (
+  "guild_id" text NOT NULL,
+  "user_id" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("guild_id", "user_id"),
+  CONSTRAINT "sf6_feed_optouts_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_feed_optouts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "sf6_battles_guild_created_at_idx" to table: "sf6_battles"
+CREATE INDEX "sf6_battles_guild_created_at_idx" ON "public"."sf6_battles" ("guild_id", "created_at");
@@ -0,0 +1,57 @@
+-- Create "sf6_battle_rounds" table
+CREATE TABLE "public"."sf6_battle_rounds" (
+  "battle_id" uuid NOT NULL,
+  "round_no" integer NOT NULL,
+  "winner" text NOT NULL,
+  "finish" text NOT NULL,
+  "self_code" integer NOT NULL,
+  "opponent_code" integer NOT NULL,
+  PRIMARY KEY ("battle_id", "round_no"),
+  CONSTRAINT "sf6_battle_rounds_battle_id_fkey" FOREIGN KEY ("battle_id") REFERENCES "public"."sf6_battles" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_battle_rounds_winner_check" CHECK (winner = ANY (ARRAY['self'::text, 'opponent'::text, 'draw'::text]))
+);
+-- Backfill "sf6_battle_rounds" from the stored battlelog payloads
+WITH "sides" AS (
+  SELECT "id",
+         CASE WHEN "raw_payload" -> 'player1_info' -> 'player' ->> 'short_id' = "subject_fighter_id"
+              THEN "raw_payload" -> 'player1_info' -> 'round_results'
+              ELSE "raw_payload" -> 'player2_info' -> 'round_results' END AS "self_rounds",
+         CASE WHEN "raw_payload" -> 'player1_info' -> 'player' ->> 'short_id' = "subject_fighter_id"
+              THEN "raw_payload" -> 'player2_info' -> 'round_results'
+              ELSE "raw_payload" -> 'player1_info' -> 'round_results' END AS "opponent_rounds"
+  FROM "public"."sf6_battles"
+  WHERE jsonb_typeof("raw_payload" -> 'player1_info' -> 'round_results') = 'array'
+    AND jsonb_typeof("raw_payload" -> 'player2_info' -> 'round_results') = 'array'
+), "rounds" AS (
+  SELECT "sides"."id" AS "battle_id",
+         "r"."ord"::integer AS "round_no",
+         "r"."value"::integer AS "self_code",
+         COALESCE(("sides"."opponent_rounds" ->> ("r"."ord"::integer - 1))::integer, 0) AS "opponent_code"
+  FROM "sides"
+  CROSS JOIN LATERAL jsonb_array_elements_text("sides"."self_rounds") WITH ORDINALITY AS "r" ("value", "ord")
+), "decided" AS (
+  SELECT "battle_id", "round_no", "self_code", "opponent_code",
+         CASE WHEN "self_code" > 0 AND "opponent_code" = 0 THEN 'self'
+              WHEN "opponent_code" > 0 AND "self_code" = 0 THEN 'opponent'
+              ELSE 'draw' END AS "winner",
+         CASE WHEN "self_code" > 0 AND "opponent_code" = 0 THEN "self_code"
+              WHEN "opponent_code" > 0 AND "self_code" = 0 THEN "opponent_code"
+              ELSE GREATEST("self_code", "opponent_code") END AS "finish_code"
+  FROM "rounds"
+)
+INSERT INTO "public"."sf6_battle_rounds" ("battle_id", "round_no", "winner", "finish", "self_code", "opponent_code")
+SELECT "battle_id", "round_no", "winner",
+       CASE "finish_code"
+         WHEN 0 THEN 'none'
+         WHEN 1 THEN 'ko'
+         WHEN 2 THEN 'chip'
+         WHEN 3 THEN 'time'
+         WHEN 4 THEN 'double_ko'
+         WHEN 5 THEN 'perfect'
+         WHEN 6 THEN 'super_art'
+         WHEN 7 THEN 'critical_art'
+         WHEN 8 THEN 'overdrive'
+         ELSE 'other' END,
+       "self_code", "opponent_code"
+FROM "decided"
+ON CONFLICT DO NOTHING;
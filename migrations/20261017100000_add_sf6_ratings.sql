@@ -0,0 +1,46 @@
+-- Create "sf6_ratings" table
+CREATE TABLE "public"."sf6_ratings" (
+  "guild_id" text NOT NULL,
+  "fighter_id" text NOT NULL,
+  "character" text NOT NULL DEFAULT '',
+  "rating" double precision NOT NULL,
+  "peak" double precision NOT NULL,
+  "games" integer NOT NULL DEFAULT 0,
+  "wins" integer NOT NULL DEFAULT 0,
+  "losses" integer NOT NULL DEFAULT 0,
+  "draws" integer NOT NULL DEFAULT 0,
+  "last_battle_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("guild_id", "fighter_id", "character"),
+  CONSTRAINT "sf6_ratings_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "sf6_ratings_guild_character_rating_idx" to table: "sf6_ratings"
+CREATE INDEX "sf6_ratings_guild_character_rating_idx" ON "public"."sf6_ratings" ("guild_id", "character", "rating" DESC);
+-- Create "sf6_rating_history" table
+CREATE TABLE "public"."sf6_rating_history" (
+  "id" bigserial NOT NULL,
+  "guild_id" text NOT NULL,
+  "fighter_id" text NOT NULL,
+  "character" text NOT NULL DEFAULT '',
+  "opponent_fighter_id" text NOT NULL,
+  "result" text NOT NULL,
+  "battle_at" timestamptz NOT NULL,
+  "rating_before" double precision NOT NULL,
+  "rating_after" double precision NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "sf6_rating_history_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "sf6_rating_history_result_check" CHECK (result = ANY (ARRAY['win'::text, 'loss'::text, 'draw'::text]))
+);
+-- Create index "sf6_rating_history_fighter_idx" to table: "sf6_rating_history"
+CREATE INDEX "sf6_rating_history_fighter_idx" ON "public"."sf6_rating_history" ("guild_id", "fighter_id", "character", "battle_at" DESC);
+-- Create "sf6_rating_cursors" table
+CREATE TABLE "public"."sf6_rating_cursors" (
+  "guild_id" text NOT NULL,
+  "battle_at" timestamptz NOT NULL,
+  "match_key" text NOT NULL,
+  "checked_at" timestamptz NOT NULL,
+  "version" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("guild_id"),
+  CONSTRAINT "sf6_rating_cursors_guild_id_fkey" FOREIGN KEY ("guild_id") REFERENCES "public"."guilds" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
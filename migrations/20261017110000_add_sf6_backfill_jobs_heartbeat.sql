@@ -0,0 +1,3 @@
+-- Add heartbeat_at to sf6_backfill_jobs (lease for the running job)
+ALTER TABLE "public"."sf6_backfill_jobs"
+  ADD COLUMN "heartbeat_at" timestamptz NULL;
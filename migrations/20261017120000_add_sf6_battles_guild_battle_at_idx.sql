@@ -0,0 +1,2 @@
+-- Create index "sf6_battles_guild_battle_at_idx" to table: "sf6_battles"
+CREATE INDEX "sf6_battles_guild_battle_at_idx" ON "public"."sf6_battles" ("guild_id", "battle_at");
@@ -74,12 +74,15 @@ CREATE TABLE IF NOT EXISTS sf6_sessions (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     guild_id TEXT NOT NULL,
     user_id TEXT NOT NULL,
+    subject_fighter_id TEXT,
     opponent_fighter_id TEXT NOT NULL,
     status TEXT NOT NULL DEFAULT 'active',
     started_at TIMESTAMPTZ NOT NULL,
     ended_at TIMESTAMPTZ,
     last_polled_at TIMESTAMPTZ NOT NULL,
     last_seen_battle_at TIMESTAMPTZ,
+    channel_id TEXT,
+    message_id TEXT,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     CONSTRAINT sf6_sessions_status_check CHECK (status IN ('active','ended')),
@@ -90,6 +93,8 @@ CREATE INDEX IF NOT EXISTS sf6_sessions_guild_user_status_idx
     ON sf6_sessions (guild_id, user_id, status);
 CREATE INDEX IF NOT EXISTS sf6_sessions_guild_user_opponent_idx
     ON sf6_sessions (guild_id, user_id, opponent_fighter_id);
+CREATE INDEX IF NOT EXISTS sf6_sessions_status_idx
+    ON sf6_sessions (status);
 
 -- SF6 Buckler: battles
 CREATE TABLE IF NOT EXISTS sf6_battles (
@@ -97,6 +102,7 @@ CREATE TABLE IF NOT EXISTS sf6_battles (
     guild_id TEXT NOT NULL,
     user_id TEXT NOT NULL,
     owner_kind TEXT NOT NULL DEFAULT 'account',
+    battle_type TEXT NOT NULL DEFAULT 'custom',
     subject_fighter_id TEXT NOT NULL,
     opponent_fighter_id TEXT NOT NULL,
     battle_at TIMESTAMPTZ NOT NULL,
@@ -112,6 +118,7 @@ CREATE TABLE IF NOT EXISTS sf6_battles (
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     CONSTRAINT sf6_battles_result_check CHECK (result IN ('win','loss','draw')),
     CONSTRAINT sf6_battles_owner_kind_check CHECK (owner_kind IN ('account','friend','unlinked')),
+    CONSTRAINT sf6_battles_battle_type_check CHECK (battle_type IN ('rank','casual','hub','custom')),
     CONSTRAINT sf6_battles_unique UNIQUE (guild_id, subject_fighter_id, source_key),
     FOREIGN KEY (session_id) REFERENCES sf6_sessions (id) ON DELETE SET NULL,
     FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE,
@@ -121,3 +128,166 @@ CREATE INDEX IF NOT EXISTS sf6_battles_guild_user_opponent_battle_at_idx
     ON sf6_battles (guild_id, user_id, opponent_fighter_id, battle_at);
 CREATE INDEX IF NOT EXISTS sf6_battles_guild_user_battle_at_idx
     ON sf6_battles (guild_id, user_id, battle_at);
+CREATE INDEX IF NOT EXISTS sf6_battles_guild_subject_opponent_type_idx
+    ON sf6_battles (guild_id, subject_fighter_id, opponent_fighter_id, battle_type, battle_at);
+CREATE INDEX IF NOT EXISTS sf6_battles_session_id_idx
+    ON sf6_battles (session_id);
+
+-- SF6 Buckler: login cookie snapshots (encrypted)
+CREATE TABLE IF NOT EXISTS buckler_cookie_snapshots (
+    account_key TEXT PRIMARY KEY,
+    key_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- SF6 Buckler: backfill jobs (resumable full battlelog walk)
+CREATE TABLE IF NOT EXISTS sf6_backfill_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    guild_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    subject_fighter_id TEXT NOT NULL,
+    battle_type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    next_page INT NOT NULL DEFAULT 1,
+    total_page INT NOT NULL DEFAULT 0,
+    saved_count INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    heartbeat_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT sf6_backfill_jobs_status_check CHECK (status IN ('running','paused','done','failed')),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS sf6_backfill_jobs_subject_kind_key
+    ON sf6_backfill_jobs (guild_id, subject_fighter_id, battle_type);
+CREATE INDEX IF NOT EXISTS sf6_backfill_jobs_status_idx
+    ON sf6_backfill_jobs (status);
+
+-- SF6 Buckler: per-fighter poll schedule (adaptive interval)
+CREATE TABLE IF NOT EXISTS sf6_poll_schedules (
+    guild_id TEXT NOT NULL,
+    fighter_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    interval_seconds INT NOT NULL,
+    next_poll_at TIMESTAMPTZ NOT NULL,
+    last_polled_at TIMESTAMPTZ,
+    last_new_battle_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (guild_id, fighter_id),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS sf6_poll_schedules_next_poll_at_idx
+    ON sf6_poll_schedules (next_poll_at);
+
+-- SF6 Buckler: fetch run history (one row per attempt and guild)
+CREATE TABLE IF NOT EXISTS sf6_fetch_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    guild_id TEXT NOT NULL,
+    fighter_id TEXT NOT NULL,
+    trigger TEXT NOT NULL,
+    attempt INT NOT NULL DEFAULT 1,
+    pages INT NOT NULL DEFAULT 0,
+    saved_count INT NOT NULL DEFAULT 0,
+    duration_ms INT NOT NULL DEFAULT 0,
+    error_class TEXT,
+    error_message TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT sf6_fetch_runs_trigger_check CHECK (trigger IN ('poller','manual','stats','session')),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS sf6_fetch_runs_guild_fighter_started_at_idx
+    ON sf6_fetch_runs (guild_id, fighter_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS sf6_fetch_runs_guild_failed_idx
+    ON sf6_fetch_runs (guild_id, started_at DESC) WHERE error_class IS NOT NULL;
+CREATE INDEX IF NOT EXISTS sf6_fetch_runs_started_at_idx
+    ON sf6_fetch_runs (started_at);
+
+-- SF6 Buckler: feed channel for new battles and per-user opt-out
+CREATE TABLE IF NOT EXISTS sf6_feed_channels (
+    guild_id TEXT PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    configured_by TEXT NOT NULL,
+    cursor_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS sf6_feed_optouts (
+    guild_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (guild_id, user_id),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS sf6_battles_guild_created_at_idx
+    ON sf6_battles (guild_id, created_at);
+CREATE INDEX IF NOT EXISTS sf6_battles_guild_battle_at_idx
+    ON sf6_battles (guild_id, battle_at);
+
+-- SF6 Buckler: per-round results parsed from round_results
+CREATE TABLE IF NOT EXISTS sf6_battle_rounds (
+    battle_id UUID NOT NULL,
+    round_no INT NOT NULL,
+    winner TEXT NOT NULL,
+    finish TEXT NOT NULL,
+    self_code INT NOT NULL,
+    opponent_code INT NOT NULL,
+    PRIMARY KEY (battle_id, round_no),
+    CONSTRAINT sf6_battle_rounds_winner_check CHECK (winner IN ('self','opponent','draw')),
+    FOREIGN KEY (battle_id) REFERENCES sf6_battles (id) ON DELETE CASCADE
+);
+
+-- SF6 Buckler: guild-wide Elo ratings (overall when character = '', otherwise per character)
+CREATE TABLE IF NOT EXISTS sf6_ratings (
+    guild_id TEXT NOT NULL,
+    fighter_id TEXT NOT NULL,
+    character TEXT NOT NULL DEFAULT '',
+    rating DOUBLE PRECISION NOT NULL,
+    peak DOUBLE PRECISION NOT NULL,
+    games INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    losses INT NOT NULL DEFAULT 0,
+    draws INT NOT NULL DEFAULT 0,
+    last_battle_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (guild_id, fighter_id, character),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS sf6_ratings_guild_character_rating_idx
+    ON sf6_ratings (guild_id, character, rating DESC);
+
+CREATE TABLE IF NOT EXISTS sf6_rating_history (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id TEXT NOT NULL,
+    fighter_id TEXT NOT NULL,
+    character TEXT NOT NULL DEFAULT '',
+    opponent_fighter_id TEXT NOT NULL,
+    result TEXT NOT NULL,
+    battle_at TIMESTAMPTZ NOT NULL,
+    rating_before DOUBLE PRECISION NOT NULL,
+    rating_after DOUBLE PRECISION NOT NULL,
+    CONSTRAINT sf6_rating_history_result_check CHECK (result IN ('win','loss','draw')),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS sf6_rating_history_fighter_idx
+    ON sf6_rating_history (guild_id, fighter_id, character, battle_at DESC);
+
+-- SF6 Buckler: rating replay cursor per guild
+CREATE TABLE IF NOT EXISTS sf6_rating_cursors (
+    guild_id TEXT PRIMARY KEY,
+    battle_at TIMESTAMPTZ NOT NULL,
+    match_key TEXT NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (guild_id) REFERENCES guilds (id) ON DELETE CASCADE
+);